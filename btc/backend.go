@@ -0,0 +1,67 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package btc
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// Backend names used in configuration
+const (
+	BackendBtcd = "btcd"
+	BackendCore = "core"
+)
+
+// BackendHandlers are called by the backend on chain changes.
+// Block notifications are delivered in chain order.
+type BackendHandlers struct {
+	OnBlockConnected    func(hash *chainhash.Hash, height int32)
+	OnBlockDisconnected func(height int32, header *wire.BlockHeader)
+	OnTxAccepted        func(tx *btcjson.TxRawResult)
+}
+
+// ChainBackend is a connection to the node which sends chain notifications
+// and answers the queries the service needs
+type ChainBackend interface {
+	// Connect makes the connection, handlers are not called before Subscribe
+	Connect(handlers BackendHandlers) error
+	// Subscribe starts delivering notifications to handlers
+	Subscribe() error
+	Shutdown()
+	WaitForShutdown()
+
+	GetBestBlock() (*chainhash.Hash, int32, error)
+	GetBlockCount() (int64, error)
+	GetBlockHash(height int64) (*chainhash.Hash, error)
+	GetBlockHeader(hash *chainhash.Hash) (*wire.BlockHeader, error)
+	GetBlock(hash *chainhash.Hash) (*wire.MsgBlock, error)
+	GetBlockVerbose(hash *chainhash.Hash) (*btcjson.GetBlockVerboseResult, error)
+	GetRawTransactionVerbose(hash *chainhash.Hash) (*btcjson.TxRawResult, error)
+	// GetTxOut returns nil if the output is spent or doesn't exist
+	GetTxOut(hash *chainhash.Hash, index uint32, mempool bool) (*btcjson.GetTxOutResult, error)
+	GetRawMempool() ([]*chainhash.Hash, error)
+	GetRawMempoolVerbose() (map[string]btcjson.GetRawMempoolVerboseResult, error)
+	DecodeRawTransaction(serializedTx []byte) (*btcjson.TxRawResult, error)
+	// SendRawTransaction broadcasts hex encoded transaction
+	SendRawTransaction(txHex string, allowHighFees bool) (*chainhash.Hash, error)
+	RawRequest(method string, params []json.RawMessage) (json.RawMessage, error)
+}
+
+// NewBackend creates the backend of configured type
+func NewBackend(conf RPCConf, host string, certificate []byte) (ChainBackend, error) {
+	switch conf.Backend {
+	case BackendBtcd, "":
+		return NewBtcdBackend(conf, host, certificate), nil
+	case BackendCore:
+		return NewCoreBackend(conf, host, certificate), nil
+	}
+	return nil, fmt.Errorf("unknown backend %q", conf.Backend)
+}
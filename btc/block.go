@@ -2,10 +2,198 @@ package btc
 
 import (
 	pb "github.com/Multy-io/Multy-BTC-node-service/node-streamer"
+	"github.com/Multy-io/Multy-BTC-node-service/outbox"
+	"github.com/Multy-io/Multy-BTC-node-service/storage"
+	"github.com/Multy-io/Multy-back/store"
 	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
 )
 
+const blockQueueSize = 100
+
+// blockNotification is a connected or disconnected block waiting to be processed
+type blockNotification struct {
+	hash         *chainhash.Hash
+	header       *wire.BlockHeader
+	height       int32
+	disconnected bool
+}
+
+// processBlocks handles block notifications one by one so the last block
+// in the storage always matches what was sent to the client
+func (c *Client) processBlocks() {
+	c.resume()
+	c.recoverSpendableOutputs()
+	for block := range c.blocks {
+		if block.disconnected {
+			c.disconnectBlock(block.height, block.header)
+			continue
+		}
+		c.connectBlock(block.hash, block.height)
+	}
+}
+
+// resume rolls back blocks orphaned while the service was down
+// and processes blocks connected since the last stored one
+func (c *Client) resume() {
+	last, err := c.Storage.LastBlock()
+	if err != nil {
+		log.Errorf("resume:Storage.LastBlock: %s", err.Error())
+		return
+	}
+
+	bestHash, bestHeight, err := c.RPCClient.GetBestBlock()
+	if err != nil {
+		log.Errorf("resume:RPCClient.GetBestBlock: %s", err.Error())
+		return
+	}
+
+	if last.Hash == "" {
+		log.Infof("resume: no processed blocks, starting from %s (%d)", bestHash.String(), bestHeight)
+		c.setLastBlock(bestHash.String(), int64(bestHeight))
+		return
+	}
+	log.Infof("resume: last processed block %s (%d), best block %s (%d)", last.Hash, last.Height, bestHash.String(), bestHeight)
+
+	for last.Height > 0 {
+		hash, err := c.RPCClient.GetBlockHash(last.Height)
+		if err == nil && hash.String() == last.Hash {
+			break
+		}
+		if err != nil && last.Height <= int64(bestHeight) {
+			log.Errorf("resume:RPCClient.GetBlockHash: %s", err.Error())
+			return
+		}
+
+		orphanHash, err := chainhash.NewHashFromStr(last.Hash)
+		if err != nil {
+			log.Errorf("resume:chainhash.NewHashFromStr: %s", err.Error())
+			return
+		}
+		header, err := c.RPCClient.GetBlockHeader(orphanHash)
+		if err != nil {
+			log.Errorf("resume:RPCClient.GetBlockHeader: %s", err.Error())
+			return
+		}
+		c.blockDisconnected(int32(last.Height), header)
+
+		rolledBack, err := c.Storage.LastBlock()
+		if err != nil {
+			log.Errorf("resume:Storage.LastBlock: %s", err.Error())
+			return
+		}
+		if rolledBack == last {
+			log.Errorf("resume: can't roll back block %s (%d)", last.Hash, last.Height)
+			return
+		}
+		last = rolledBack
+	}
+
+	c.syncBlocks(last.Height+1, int64(bestHeight))
+}
+
+/*
+recoverSpendableOutputs checks spendable outputs kept in the storage after resume.
+Outputs spent or dropped from mempool while the service was down are deleted
+and the deletion is sent to the client.
+*/
+func (c *Client) recoverSpendableOutputs() {
+	spOuts, err := c.Storage.SpendableOutputs()
+	if err != nil {
+		log.Errorf("recoverSpendableOutputs:Storage.SpendableOutputs: %s", err.Error())
+		return
+	}
+
+	// an output is kept for each of its owners
+	owners := map[string][]store.SpendableOutputs{}
+	for _, spOut := range spOuts {
+		key := outpointKey(spOut.TxID, uint32(spOut.TxOutID))
+		owners[key] = append(owners[key], spOut)
+	}
+
+	deleted := 0
+	for _, outputs := range owners {
+		spOut := outputs[0]
+		hash, err := chainhash.NewHashFromStr(spOut.TxID)
+		if err != nil {
+			log.Errorf("recoverSpendableOutputs:chainhash.NewHashFromStr: %s", err.Error())
+			continue
+		}
+		txOut, err := c.RPCClient.GetTxOut(hash, uint32(spOut.TxOutID), true)
+		if err != nil {
+			log.Errorf("recoverSpendableOutputs:RPCClient.GetTxOut: %s", err.Error())
+			continue
+		}
+		if txOut != nil {
+			continue
+		}
+
+		c.removeSpendableOutput(spOut.TxID, spOut.TxOutID)
+		deleted++
+		for _, output := range outputs {
+			c.emit(outbox.KindDeleteSpOut, &pb.ReqDeleteSpOut{
+				UserID:  output.UserID,
+				TxID:    output.TxID,
+				Address: output.Address,
+				TxOutID: int32(output.TxOutID),
+			})
+		}
+	}
+	log.Infof("recoverSpendableOutputs: %d stored outputs, %d deleted", len(owners), deleted)
+}
+
+// syncBlocks processes main chain blocks from one height to another inclusive
+func (c *Client) syncBlocks(from, to int64) {
+	for height := from; height <= to; height++ {
+		hash, err := c.RPCClient.GetBlockHash(height)
+		if err != nil {
+			log.Errorf("syncBlocks:RPCClient.GetBlockHash: %s", err.Error())
+			return
+		}
+		c.BlockTransactions(hash)
+	}
+}
+
+func (c *Client) connectBlock(hash *chainhash.Hash, height int32) {
+	last, err := c.Storage.LastBlock()
+	if err != nil {
+		log.Errorf("connectBlock:Storage.LastBlock: %s", err.Error())
+	}
+	if last.Hash != "" {
+		if int64(height) <= last.Height {
+			log.Debugf("connectBlock: block %s (%d) is already processed", hash.String(), height)
+			return
+		}
+		// blocks connected while the notification was in the queue
+		c.syncBlocks(last.Height+1, int64(height)-1)
+	}
+	c.BlockTransactions(hash)
+}
+
+func (c *Client) disconnectBlock(height int32, header *wire.BlockHeader) {
+	last, err := c.Storage.LastBlock()
+	if err != nil {
+		log.Errorf("disconnectBlock:Storage.LastBlock: %s", err.Error())
+	}
+	if hash := header.BlockHash(); last.Hash != "" && last.Hash != hash.String() {
+		log.Warnf("disconnectBlock: block %s (%d) is not the last processed block %s (%d)", hash.String(), height, last.Hash, last.Height)
+		return
+	}
+	c.blockDisconnected(height, header)
+}
+
+// setLastBlock remembers the last processed block to resume from it after restart
+func (c *Client) setLastBlock(hash string, height int64) {
+	err := c.Storage.SetLastBlock(storage.BlockState{
+		Hash:   hash,
+		Height: height,
+	})
+	if err != nil {
+		log.Errorf("setLastBlock:Storage.SetLastBlock: %s", err.Error())
+	}
+}
+
 // ProcessTransaction from block
 func (c *Client) BlockTransactions(hash *chainhash.Hash) {
 	log.Debugf("New block connected %s", hash.String())
@@ -20,28 +208,51 @@ func (c *Client) BlockTransactions(hash *chainhash.Hash) {
 
 	//parse all block transactions
 	rawBlock, err := c.RPCClient.GetBlock(hash)
+	if err != nil {
+		log.Errorf("parseNewBlock:GetBlock: %s", err.Error())
+		return
+	}
 	allBlockTransactions, err := rawBlock.TxHashes()
 	if err != nil {
 		log.Errorf("parseNewBlock:rawBlock.TxHashes: %s", err.Error())
 	}
 
-	// Broadcast to client to delete mempool
+	txids := []string{}
 	for _, hash := range allBlockTransactions {
-		c.DeleteMempool <- pb.MempoolToDelete{
-			Hash: hash.String(),
-		}
+		txids = append(txids, hash.String())
 	}
+	c.fees.addBlock(blockHeight, txids)
 
-	for _, txHash := range allBlockTransactions {
+	// Broadcast to client to delete mempool
+	for _, hash := range allBlockTransactions {
+		c.emit(outbox.KindDeleteMempool, &pb.MempoolToDelete{
+			Hash:   hash.String(),
+			Reason: pb.MempoolDeleteReason_MINED,
+		})
+	}
 
-		blockTxVerbose, err := c.RPCClient.GetRawTransactionVerbose(&txHash)
-		if err != nil {
-			log.Errorf("parseNewBlock:RPCClient.GetRawTransactionVerbose: %s", err.Error())
-			continue
-		}
+	// transactions are decoded from the block itself and their outputs are
+	// cached first, so inputs spending outputs of the same block are resolved in memory
+	txs := c.blockTransactions(rawBlock, true)
+	for _, tx := range txs {
+		c.mempoolConflicts(tx, blockHeight)
+		c.mempoolSpends.mined(tx.Txid)
+		c.ProcessTransaction(blockHeight, tx, false)
+	}
 
-		c.ProcessTransaction(blockHeight, blockTxVerbose, false)
+	if c.index.Enabled {
+		c.indexBlock(blockHeight, txs)
 	}
+
+	c.updateConfirmations(blockHeight)
+
+	c.setLastBlock(hash.String(), blockHeight)
+
+	// the block is announced after its transactions
+	c.emit(outbox.KindBlock, &pb.BlockHeight{Height: blockHeight})
+
+	stats := c.PrevoutStats()
+	log.Debugf("prevout cache: %d outputs, %d hits, %d misses, %d evictions", stats.Size, stats.Hits, stats.Misses, stats.Evictions)
 }
 
 func (c *Client) ResyncBlock(blockVerbose *btcjson.GetBlockVerboseResult) {
@@ -51,17 +262,12 @@ func (c *Client) ResyncBlock(blockVerbose *btcjson.GetBlockVerboseResult) {
 	//parse all block transactions
 	hash, _ := chainhash.NewHashFromStr(blockVerbose.Hash)
 	rawBlock, err := c.RPCClient.GetBlock(hash)
-	allBlockTransactions, err := rawBlock.TxHashes()
 	if err != nil {
-		log.Errorf("parseNewBlock:rawBlock.TxHashes: %s", err.Error())
+		log.Errorf("ResyncBlock:RPCClient.GetBlock: %s", err.Error())
+		return
 	}
 
-	for _, txHash := range allBlockTransactions {
-		blockTxVerbose, err := c.RPCClient.GetRawTransactionVerbose(&txHash)
-		if err != nil {
-			log.Errorf("parseNewBlock:RPCClient.GetRawTransactionVerbose: %s", err.Error())
-			continue
-		}
-		c.ProcessTransaction(blockHeight, blockTxVerbose, false)
+	for _, tx := range c.blockTransactions(rawBlock, true) {
+		c.ProcessTransaction(blockHeight, tx, false)
 	}
 }
@@ -6,107 +6,178 @@ See LICENSE for details
 package btc
 
 import (
+	"errors"
+	"fmt"
 	"sync"
-	"time"
 
-	pb "github.com/Multy-io/Multy-BTC-node-service/node-streamer"
+	"github.com/Multy-io/Multy-BTC-node-service/outbox"
+	"github.com/Multy-io/Multy-BTC-node-service/storage"
 	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
-	"github.com/btcsuite/btcd/rpcclient"
 	"github.com/btcsuite/btcd/wire"
+	"github.com/golang/protobuf/proto"
 	"github.com/jekabolt/slf"
 	_ "github.com/jekabolt/slflog"
 )
 
 type Client struct {
-	RPCClient      *rpcclient.Client
-	ResyncCh       chan pb.Resync
-	TransactionsCh chan pb.BTCTransaction
-	AddSpOut       chan pb.AddSpOut
-	DelSpOut       chan pb.ReqDeleteSpOut
-	DeleteMempool  chan pb.MempoolToDelete
-	AddToMempool   chan pb.MempoolRecord
-	Block          chan pb.BlockHeight
-	UsersData      *sync.Map
-	rpcConf        *rpcclient.ConnConfig
+	// RPCClient is the node backend, it's connected and on the network of params
+	RPCClient         ChainBackend
+	Events            *outbox.Outbox
+	UsersData         *sync.Map
+	Storage           storage.Storage
+	backend           ChainBackend
+	params            *chaincfg.Params
+	blocks            chan blockNotification
+	confirmationDepth int
+	index             IndexConf
+	fees              *feeStats
+	feeConf           FeeConf
+	delivery          *delivery
+	watchdog          *watchdog
+	prevouts          *prevoutCache
+	opReturn          OpReturnConf
+	mempoolSpends     *mempoolSpends
+	mempoolSync       MempoolSyncConf
+	// confFailures counts blocks a tracked transaction couldn't be read on, used by processBlocks only
+	confFailures map[string]int
+}
+
+// Conf is a configuration of the btc client
+type Conf struct {
+	Certificate []byte
+	NodeAddress string
+	RPC         RPCConf
+	// Params of the network, the node is checked to be on it
+	Params            *chaincfg.Params
+	ConfirmationDepth int
+	Index             IndexConf
+	Fee               FeeConf
+	Watchdog          WatchdogConf
+	// PrevoutCacheSize is the number of cached outputs spent by analysed inputs
+	PrevoutCacheSize int
+	OpReturn         OpReturnConf
+	MempoolSync      MempoolSyncConf
 }
 
 var log = slf.WithContext("btc").WithCaller(slf.CallerShort)
 
-func NewClient(certFromConf []byte, btcNodeAddress string, usersData *sync.Map) (*Client, error) {
+func NewClient(conf Conf, usersData *sync.Map, storage storage.Storage, events *outbox.Outbox) (*Client, error) {
+	certFromConf := conf.Certificate
+	btcNodeAddress := conf.NodeAddress
+	if btcNodeAddress == "" {
+		return nil, errors.New("node address is empty")
+	}
+	if err := conf.RPC.Validate(certFromConf); err != nil {
+		return nil, fmt.Errorf("node rpc configuration: %s", err.Error())
+	}
+	if err := conf.OpReturn.Validate(); err != nil {
+		return nil, err
+	}
+	if err := conf.Fee.Validate(); err != nil {
+		return nil, err
+	}
+	confirmationDepth := conf.ConfirmationDepth
+	if confirmationDepth <= 0 {
+		confirmationDepth = DefaultConfirmationDepth
+	}
+	params := conf.Params
+	if params == nil {
+		params = &chaincfg.MainNetParams
+	}
+
+	backend, err := NewBackend(conf.RPC, btcNodeAddress, certFromConf)
+	if err != nil {
+		return nil, err
+	}
 
 	cli := &Client{
-		ResyncCh:       make(chan pb.Resync),
-		TransactionsCh: make(chan pb.BTCTransaction),
-		AddSpOut:       make(chan pb.AddSpOut),
-		DelSpOut:       make(chan pb.ReqDeleteSpOut),
-		DeleteMempool:  make(chan pb.MempoolToDelete),
-		AddToMempool:   make(chan pb.MempoolRecord),
-		Block:          make(chan pb.BlockHeight),
-		rpcConf: &rpcclient.ConnConfig{
-			Host:         btcNodeAddress,
-			User:         "multy",
-			Pass:         "multy",
-			Endpoint:     "ws",
-			Certificates: certFromConf,
-			HTTPPostMode: false, // Bitcoin core only supports HTTP POST mode
-			DisableTLS:   false, // Bitcoin core does not provide TLS by default
-		},
+		Events:    events,
 		UsersData: usersData,
+		Storage:   storage,
+		backend:   backend,
+		params:    params,
+		blocks:    make(chan blockNotification, blockQueueSize),
+
+		confirmationDepth: confirmationDepth,
+		index:             conf.Index,
+		fees:              newFeeStats(),
+		feeConf:           conf.Fee,
+		delivery:          newDelivery(!conf.Watchdog.Disabled),
+		watchdog:          newWatchdog(conf.Watchdog),
+		prevouts:          newPrevoutCache(conf.PrevoutCacheSize),
+		opReturn:          conf.OpReturn,
+		mempoolSpends:     newMempoolSpends(),
+		mempoolSync:       conf.MempoolSync,
+		confFailures:      map[string]int{},
 	}
 
 	log.Infof("cert= %d bytes\n", len(certFromConf))
+	log.Infof("network= %s", NetworkName(params))
+	log.Infof("backend= %s tls= %t http post= %t proxy= %t cookie= %t", conf.RPC.Backend, !conf.RPC.DisableTLS, conf.RPC.httpPostMode(), conf.RPC.Proxy != "", conf.RPC.CookieFile != "")
+
+	if err := backend.Connect(cli.handlers(true)); err != nil {
+		return nil, fmt.Errorf("backend.Connect: %s", err.Error())
+	}
+	// Don't process anything from a node of another network
+	if err := cli.checkNetwork(backend); err != nil {
+		backend.Shutdown()
+		return nil, err
+	}
+	log.Infof("checkNetwork: node is on %s", NetworkName(params))
+	cli.RPCClient = backend
 
 	go cli.RunProcess(btcNodeAddress)
 	return cli, nil
 }
 
-func (c *Client) RunProcess(btcNodeAddress string) error {
-	log.Info("Run Process")
+// emit appends the event to the outbox
+func (c *Client) emit(kind string, msg proto.Message) {
+	if _, err := c.Events.Append(kind, msg); err != nil {
+		log.Errorf("emit:Events.Append: %s: %s", kind, err.Error())
+	}
+}
 
-	ntfnHandlers := rpcclient.NotificationHandlers{
-		OnBlockConnected: func(hash *chainhash.Hash, height int32, t time.Time) {
-			log.Debugf("OnBlockConnected: %v (%d) %v", hash, height, t)
-			if hash != nil {
-				go c.BlockTransactions(hash)
+// handlers queue notifications of the backend or the watchdog poller.
+// Both may deliver the same block or transaction, it's processed once.
+func (c *Client) handlers(push bool) BackendHandlers {
+	return BackendHandlers{
+		OnBlockConnected: func(hash *chainhash.Hash, height int32) {
+			if !c.delivery.blockConnected(hash, height, push) {
+				return
 			}
-			if hash == nil {
-				log.Errorf("OnBlockConnected:hash is nil")
-			}
-			c.Block <- pb.BlockHeight{Height: int64(height)}
+			c.blocks <- blockNotification{hash: hash, height: height}
 		},
-		OnTxAcceptedVerbose: func(txDetails *btcjson.TxRawResult) {
-			if txDetails != nil {
-				go c.mempoolTransaction(txDetails)
-			}
-			if txDetails == nil {
-				log.Errorf("OnTxAcceptedVerbose:txDetails is nil")
+		OnTxAccepted: func(txDetails *btcjson.TxRawResult) {
+			if !c.delivery.tx(txDetails.Txid, push) {
+				return
 			}
+			go c.mempoolTransaction(txDetails)
 		},
-		OnFilteredBlockDisconnected: func(height int32, header *wire.BlockHeader) {
-
+		OnBlockDisconnected: func(height int32, header *wire.BlockHeader) {
+			c.delivery.blockDisconnected(height, &header.PrevBlock)
+			c.blocks <- blockNotification{header: header, height: height, disconnected: true}
 		},
 	}
+}
 
-	RPCClient, err := rpcclient.New(c.rpcConf, &ntfnHandlers)
-	if err != nil {
-		log.Errorf("RunProcess(): RPCclient.New %s\n", err.Error())
-		return err
-	}
+func (c *Client) RunProcess(btcNodeAddress string) error {
+	log.Info("Run Process")
 
-	// Register for block connect and disconnect notifications.
-	if err = RPCClient.NotifyBlocks(); err != nil {
+	if err := c.backend.Subscribe(); err != nil {
+		log.Errorf("RunProcess(): backend.Subscribe %s\n", err.Error())
 		return err
 	}
-	log.Info("NotifyBlocks: Registration Complete")
 
-	// Register for new transaction in mempool notifications.
-	if err = RPCClient.NotifyNewTransactions(true); err != nil {
-		return err
+	go c.processBlocks()
+
+	if c.index.Enabled {
+		go c.backfillIndex()
 	}
-	log.Info("NotifyNewTransactions: Registration Complete")
 
-	c.RPCClient = RPCClient
+	go c.watch()
+	go c.syncMempool()
 
 	c.RPCClient.WaitForShutdown()
 	return nil
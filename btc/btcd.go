@@ -0,0 +1,118 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package btc
+
+import (
+	"time"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/rpcclient"
+	"github.com/btcsuite/btcd/wire"
+)
+
+/*
+BtcdBackend is btcd connected by websocket which notifies about blocks
+and verbose mempool transactions. In HTTP POST mode there are no
+notifications and the node is polled.
+*/
+type BtcdBackend struct {
+	*rpcclient.Client
+
+	conf        RPCConf
+	host        string
+	certificate []byte
+	poller      *poller
+	quit        chan struct{}
+}
+
+func NewBtcdBackend(conf RPCConf, host string, certificate []byte) *BtcdBackend {
+	return &BtcdBackend{
+		conf:        conf,
+		host:        host,
+		certificate: certificate,
+		quit:        make(chan struct{}),
+	}
+}
+
+func (b *BtcdBackend) Connect(handlers BackendHandlers) error {
+	rpcConf, err := b.conf.connConfig(b.host, b.certificate)
+	if err != nil {
+		return err
+	}
+
+	ntfnHandlers := &rpcclient.NotificationHandlers{
+		OnBlockConnected: func(hash *chainhash.Hash, height int32, t time.Time) {
+			log.Debugf("OnBlockConnected: %v (%d) %v", hash, height, t)
+			if hash == nil {
+				log.Errorf("OnBlockConnected:hash is nil")
+				return
+			}
+			handlers.OnBlockConnected(hash, height)
+		},
+		OnTxAcceptedVerbose: func(txDetails *btcjson.TxRawResult) {
+			if txDetails == nil {
+				log.Errorf("OnTxAcceptedVerbose:txDetails is nil")
+				return
+			}
+			handlers.OnTxAccepted(txDetails)
+		},
+		OnFilteredBlockDisconnected: func(height int32, header *wire.BlockHeader) {
+			log.Debugf("OnFilteredBlockDisconnected: %d", height)
+			if header == nil {
+				log.Errorf("OnFilteredBlockDisconnected:header is nil")
+				return
+			}
+			handlers.OnBlockDisconnected(height, header)
+		},
+	}
+	// notifications are not supported in HTTP POST mode
+	if rpcConf.HTTPPostMode {
+		ntfnHandlers = nil
+	}
+
+	client, err := rpcclient.New(rpcConf, ntfnHandlers)
+	if err != nil {
+		return err
+	}
+	b.Client = client
+	b.poller = newPoller(b, handlers)
+	return nil
+}
+
+func (b *BtcdBackend) Subscribe() error {
+	if b.conf.HTTPPostMode {
+		log.Warn("Subscribe: HTTP POST mode, polling the node")
+		b.poller.start(b.conf.pollInterval(), b.quit, func() bool { return true })
+		return nil
+	}
+
+	// Register for block connect and disconnect notifications.
+	if err := b.NotifyBlocks(); err != nil {
+		return err
+	}
+	log.Info("NotifyBlocks: Registration Complete")
+
+	// Register for new transaction in mempool notifications.
+	if err := b.NotifyNewTransactions(true); err != nil {
+		return err
+	}
+	log.Info("NotifyNewTransactions: Registration Complete")
+	return nil
+}
+
+func (b *BtcdBackend) Shutdown() {
+	select {
+	case <-b.quit:
+	default:
+		close(b.quit)
+	}
+	b.Client.Shutdown()
+}
+
+func (b *BtcdBackend) SendRawTransaction(txHex string, allowHighFees bool) (*chainhash.Hash, error) {
+	return b.SendCyberRawTransaction(txHex, allowHighFees)
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package btc
+
+import (
+	"fmt"
+
+	"github.com/Multy-io/Multy-BTC-node-service/storage"
+	"github.com/Multy-io/Multy-back/store"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// DefaultConfirmationDepth is the number of confirmations after which transaction is final
+const DefaultConfirmationDepth = 6
+
+// maxConfirmationFailures is the number of blocks a tracked transaction can't be read on before it's untracked
+const maxConfirmationFailures = 3
+
+// trackConfirmations keeps block transaction to report its confirmations on next blocks
+func (c *Client) trackConfirmations(tx *Tx, blockHeight int64, transactions []store.MultyTX) {
+	final := true
+	for _, transaction := range transactions {
+		if transaction.Confirmations < c.confirmationDepth {
+			final = false
+		}
+	}
+	if final {
+		c.untrackConfirmations(tx.Txid)
+		return
+	}
+
+	err := c.Storage.AddConfirmingTx(storage.ConfirmingTx{
+		TxID:        tx.Txid,
+		BlockHash:   tx.BlockHash,
+		BlockHeight: blockHeight,
+	})
+	if err != nil {
+		log.Errorf("trackConfirmations:Storage.AddConfirmingTx: %s", err.Error())
+	}
+}
+
+func (c *Client) untrackConfirmations(txid string) {
+	if err := c.Storage.DeleteConfirmingTx(txid); err != nil {
+		log.Errorf("untrackConfirmations:Storage.DeleteConfirmingTx: %s", err.Error())
+	}
+}
+
+/*
+updateConfirmations is called on every new block.
+It sends tracked transactions again with the new number of confirmations
+and status. Transaction is sent for the last time with TxStatusInBlockConfirmed*
+status when it reaches the confirmation depth.
+Transactions are decoded from their blocks, so the node needs no tx index.
+*/
+func (c *Client) updateConfirmations(blockHeight int64) {
+	confTxs, err := c.Storage.ConfirmingTxs()
+	if err != nil {
+		log.Errorf("updateConfirmations:Storage.ConfirmingTxs: %s", err.Error())
+		return
+	}
+
+	// failures of transactions untracked since the last update are forgotten
+	tracked := map[string]bool{}
+	for _, confTx := range confTxs {
+		tracked[confTx.TxID] = true
+	}
+	for txid := range c.confFailures {
+		if !tracked[txid] {
+			delete(c.confFailures, txid)
+		}
+	}
+
+	blocks := map[string]*wire.MsgBlock{}
+	for _, confTx := range confTxs {
+		if confTx.BlockHeight >= blockHeight {
+			continue
+		}
+
+		mainChain, err := c.onMainChain(confTx.BlockHash, confTx.BlockHeight)
+		if err != nil {
+			c.confirmationFailed(confTx.TxID, err)
+			continue
+		}
+		if !mainChain {
+			// block was disconnected, rollback is reported separately
+			log.Warnf("updateConfirmations: %s is not in block %s anymore", confTx.TxID, confTx.BlockHash)
+			c.untrackConfirmations(confTx.TxID)
+			continue
+		}
+
+		tx, err := c.blockTx(blocks, confTx.BlockHash, confTx.TxID)
+		if err != nil {
+			c.confirmationFailed(confTx.TxID, err)
+			continue
+		}
+		delete(c.confFailures, confTx.TxID)
+
+		multyTx, related := c.ParseRawTransaction(confTx.BlockHeight, tx)
+		if !related {
+			// address was removed from the watch set
+			c.untrackConfirmations(confTx.TxID)
+			continue
+		}
+		multyTx.BlockHeight = confTx.BlockHeight
+		c.setTransactionInfo(multyTx, tx, confTx.BlockHeight, false)
+
+		transactions := c.splitTransaction(*multyTx, confTx.BlockHeight)
+		final := true
+		for _, transaction := range transactions {
+			finalizeTransaction(&transaction, tx)
+			transaction.BlockTime = tx.Blocktime
+			if transaction.Confirmations < c.confirmationDepth {
+				final = false
+			}
+			c.saveMultyTransaction(transaction, tx, false)
+		}
+
+		if final {
+			log.Debugf("updateConfirmations: %s is final", confTx.TxID)
+			c.untrackConfirmations(confTx.TxID)
+		}
+	}
+}
+
+// onMainChain reports if the block at height is still on the main chain
+func (c *Client) onMainChain(blockHash string, height int64) (bool, error) {
+	hash, err := c.RPCClient.GetBlockHash(height)
+	if err != nil {
+		return false, err
+	}
+	return hash.String() == blockHash, nil
+}
+
+// blockTx decodes the transaction from its block, blocks are fetched once per update
+func (c *Client) blockTx(blocks map[string]*wire.MsgBlock, blockHash, txid string) (*Tx, error) {
+	block, ok := blocks[blockHash]
+	if !ok {
+		hash, err := chainhash.NewHashFromStr(blockHash)
+		if err != nil {
+			return nil, err
+		}
+		block, err = c.RPCClient.GetBlock(hash)
+		if err != nil {
+			return nil, err
+		}
+		blocks[blockHash] = block
+	}
+	for _, msgTx := range block.Transactions {
+		if msgTx.TxHash().String() != txid {
+			continue
+		}
+		tx := txFromMsg(msgTx, &block.Header, c.params)
+		c.resolvePrevouts(tx)
+		return tx, nil
+	}
+	return nil, fmt.Errorf("%s is not found in block %s", txid, blockHash)
+}
+
+// confirmationFailed untracks the transaction if it can't be read on several blocks in a row
+func (c *Client) confirmationFailed(txid string, err error) {
+	c.confFailures[txid]++
+	log.Errorf("updateConfirmations: %s (%d of %d): %s", txid, c.confFailures[txid], maxConfirmationFailures, err.Error())
+	if c.confFailures[txid] >= maxConfirmationFailures {
+		log.Warnf("updateConfirmations: %s is untracked", txid)
+		delete(c.confFailures, txid)
+		c.untrackConfirmations(txid)
+	}
+}
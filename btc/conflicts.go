@@ -0,0 +1,321 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package btc
+
+import (
+	"sort"
+	"sync"
+
+	pb "github.com/Multy-io/Multy-BTC-node-service/node-streamer"
+	"github.com/Multy-io/Multy-BTC-node-service/outbox"
+	"github.com/btcsuite/btcutil"
+)
+
+// maxTrackedSpends limits remembered mempool transactions
+const maxTrackedSpends = 300000
+
+// spendingTx is a mempool transaction with outpoints it spends
+type spendingTx struct {
+	txid    string
+	inputs  []string
+	outputs int
+	// spent is txids of spent outputs, one for each input
+	spent []string
+	// tx is kept only for watched transactions to roll them back
+	tx *Tx
+
+	fee    btcutil.Amount
+	vsize  int32
+	weight int32
+	// category is the last reported package rate
+	category int32
+	// memberOnly transactions are known to be in the node's mempool by txid only
+	memberOnly bool
+	// added orders transactions by the time they were tracked
+	added uint64
+}
+
+/*
+mempoolSpends tracks outpoints spent by mempool transactions, so a transaction
+spending the same outpoint as a mempool one is detected as its replacement or
+a double spend. Transactions leave it when they are mined or conflicted.
+Spent outpoints also link mempool parents and children for package fee rates.
+*/
+type mempoolSpends struct {
+	m sync.Mutex
+	// outpoint to spending txid
+	spenders map[string]string
+	txs      map[string]*spendingTx
+	// missing transactions were not in the node's mempool on the last reconciliation
+	missing map[string]bool
+	// replacedBy is the conflicting txid of removed transactions,
+	// replacedOrder is their txids from the oldest
+	replacedBy    map[string]string
+	replacedOrder []string
+	// added is the order of the last tracked transaction
+	added uint64
+}
+
+func newMempoolSpends() *mempoolSpends {
+	return &mempoolSpends{
+		spenders:   map[string]string{},
+		txs:        map[string]*spendingTx{},
+		missing:    map[string]bool{},
+		replacedBy: map[string]string{},
+	}
+}
+
+// add remembers outpoints spent by the mempool transaction
+func (ms *mempoolSpends) add(tx *Tx, watched bool) {
+	ms.m.Lock()
+	defer ms.m.Unlock()
+	ms.trim()
+
+	fee, _ := tx.Fee()
+	spending := &spendingTx{
+		txid:    tx.Txid,
+		inputs:  make([]string, 0, len(tx.In)),
+		outputs: len(tx.Out),
+		fee:     fee,
+		vsize:   tx.Vsize,
+		weight:  tx.Weight,
+	}
+	if watched {
+		spending.tx = tx
+	}
+	ms.track(spending)
+	for _, in := range tx.In {
+		if in.Coinbase {
+			continue
+		}
+		key := outpointKey(in.Txid, in.Vout)
+		spending.inputs = append(spending.inputs, key)
+		ms.spenders[key] = tx.Txid
+		spending.spent = append(spending.spent, in.Txid)
+	}
+	ms.txs[tx.Txid] = spending
+}
+
+// track orders the transaction after already tracked ones, lock must be held
+func (ms *mempoolSpends) track(spending *spendingTx) {
+	ms.added++
+	spending.added = ms.added
+}
+
+/*
+trim forgets the oldest transactions if there are too many until the half is left,
+lock must be held. Linked parents and children are forgotten together, so package
+rates of kept transactions stay whole. Clusters with watched transactions are kept.
+*/
+func (ms *mempoolSpends) trim() {
+	if len(ms.txs) < maxTrackedSpends {
+		return
+	}
+	oldest := make([]*spendingTx, 0, len(ms.txs))
+	for _, spending := range ms.txs {
+		oldest = append(oldest, spending)
+	}
+	sort.Slice(oldest, func(i, j int) bool {
+		return oldest[i].added < oldest[j].added
+	})
+
+	checked := map[string]bool{}
+	for _, spending := range oldest {
+		if len(ms.txs) <= maxTrackedSpends/2 {
+			return
+		}
+		if checked[spending.txid] {
+			continue
+		}
+		cluster := append([]string{spending.txid}, relatives(spending.txid, ms.linked)...)
+		watched := false
+		for _, txid := range cluster {
+			checked[txid] = true
+			if member, ok := ms.txs[txid]; ok && member.tx != nil {
+				watched = true
+			}
+		}
+		if watched {
+			continue
+		}
+		for _, txid := range cluster {
+			ms.remove(txid)
+			delete(ms.missing, txid)
+		}
+	}
+}
+
+// linked returns tracked parents and children of the transaction, lock must be held
+func (ms *mempoolSpends) linked(txid string) []string {
+	return append(ms.parents(txid), ms.children(txid)...)
+}
+
+// remove forgets the transaction, lock must be held
+func (ms *mempoolSpends) remove(txid string) {
+	spending, ok := ms.txs[txid]
+	if !ok {
+		return
+	}
+	for _, key := range spending.inputs {
+		if ms.spenders[key] == txid {
+			delete(ms.spenders, key)
+		}
+	}
+	delete(ms.txs, txid)
+}
+
+// mined forgets transactions included in a block
+func (ms *mempoolSpends) mined(txid string) {
+	ms.m.Lock()
+	defer ms.m.Unlock()
+	ms.remove(txid)
+}
+
+// replaced remembers the conflicting transaction, the oldest ones are forgotten first, lock must be held
+func (ms *mempoolSpends) replaced(txid, by string) {
+	if _, ok := ms.replacedBy[txid]; !ok {
+		ms.replacedOrder = append(ms.replacedOrder, txid)
+	}
+	ms.replacedBy[txid] = by
+	for len(ms.replacedBy) > maxTrackedSpends {
+		delete(ms.replacedBy, ms.replacedOrder[0])
+		ms.replacedOrder = ms.replacedOrder[1:]
+	}
+}
+
+// replacement returns the transaction which replaced or double spent txid
+func (ms *mempoolSpends) replacement(txid string) (string, bool) {
+	ms.m.Lock()
+	defer ms.m.Unlock()
+	by, ok := ms.replacedBy[txid]
+	return by, ok
+}
+
+/*
+conflicts removes mempool transactions spending the same outpoints as tx
+and their descendants which spend outputs of removed transactions.
+Removed transactions are returned parents first.
+*/
+func (ms *mempoolSpends) conflicts(tx *Tx) []*spendingTx {
+	ms.m.Lock()
+	defer ms.m.Unlock()
+
+	removed := []*spendingTx{}
+	queue := []string{}
+	for _, in := range tx.In {
+		if in.Coinbase {
+			continue
+		}
+		if spender, ok := ms.spenders[outpointKey(in.Txid, in.Vout)]; ok && spender != tx.Txid {
+			queue = append(queue, spender)
+		}
+	}
+	for len(queue) > 0 {
+		txid := queue[0]
+		queue = queue[1:]
+		spending, ok := ms.txs[txid]
+		if !ok {
+			continue
+		}
+		ms.remove(txid)
+		ms.replaced(txid, tx.Txid)
+		removed = append(removed, spending)
+		for n := 0; n < spending.outputs; n++ {
+			if spender, ok := ms.spenders[outpointKey(txid, uint32(n))]; ok {
+				queue = append(queue, spender)
+			}
+		}
+	}
+	return removed
+}
+
+/*
+mempoolConflicts reports mempool transactions which can't be mined anymore
+because tx spends the same outpoints. blockHeight is -1 for a mempool tx,
+then conflicting transactions are replaced (by fee bumping or a double spend
+accepted by the node), otherwise they are conflicted by a mined transaction.
+*/
+func (c *Client) mempoolConflicts(tx *Tx, blockHeight int64) {
+	removed := c.mempoolSpends.conflicts(tx)
+	if len(removed) == 0 {
+		return
+	}
+
+	kind, reason := outbox.KindTxReplaced, pb.MempoolDeleteReason_REPLACED
+	if blockHeight != -1 {
+		kind, reason = outbox.KindTxConflicted, pb.MempoolDeleteReason_CONFLICTED
+	}
+
+	// outpoints which are not spendable again
+	spent := map[string]bool{}
+	for _, in := range tx.In {
+		spent[outpointKey(in.Txid, in.Vout)] = true
+	}
+	removedTxids := map[string]bool{}
+	for _, spending := range removed {
+		removedTxids[spending.txid] = true
+	}
+
+	for _, spending := range removed {
+		log.Warnf("mempoolConflicts: %s is %s by %s", spending.txid, kind, tx.Txid)
+		c.fees.removeMempoolTx(spending.txid)
+		c.emit(outbox.KindDeleteMempool, &pb.MempoolToDelete{
+			Hash:   spending.txid,
+			Reason: reason,
+		})
+		if spending.tx == nil {
+			continue
+		}
+		conflict := c.conflictRollback(spending.tx, spent, removedTxids)
+		conflict.ByTxID = tx.Txid
+		conflict.BlockHeight = blockHeight
+		conflict.Reason = reason
+		c.emit(kind, &conflict)
+	}
+}
+
+/*
+conflictRollback reports the removed watched transaction with a negative (rejected)
+status, deletes spendable outputs created by it and restores outputs it spent
+unless they are spent by the conflicting transaction or created by removed ones.
+*/
+func (c *Client) conflictRollback(tx *Tx, spent, removedTxids map[string]bool) pb.TxConflict {
+	conflict := pb.TxConflict{
+		TxID: tx.Txid,
+	}
+
+	if multyTx, related := c.ParseRawTransaction(-1, tx); multyTx != nil && related {
+		c.setTransactionInfo(multyTx, tx, -1, false)
+		for _, transaction := range c.splitTransaction(*multyTx, -1) {
+			finalizeTransaction(&transaction, tx)
+			transaction.TxStatus = transaction.TxStatus * -1
+			if generated, ok := multyTxToGenerated(transaction); ok {
+				setTxDetails(&generated, tx)
+				conflict.Txs = append(conflict.Txs, &generated)
+			}
+		}
+	}
+
+	for _, spendableOutput := range c.spendableOutputs(tx, -1) {
+		c.removeSpendableOutput(spendableOutput.TxID, spendableOutput.TxOutID)
+		conflict.SpOutDelete = append(conflict.SpOutDelete, &pb.ReqDeleteSpOut{
+			UserID:  spendableOutput.UserID,
+			TxID:    spendableOutput.TxID,
+			Address: spendableOutput.Address,
+			TxOutID: int32(spendableOutput.TxOutID),
+		})
+	}
+
+	for _, spendableOutput := range c.spentOutputs(tx) {
+		if spent[outpointKey(spendableOutput.TxID, uint32(spendableOutput.TxOutID))] || removedTxids[spendableOutput.TxID] {
+			continue
+		}
+		c.saveSpendableOutput(spendableOutput)
+		spOut := spOutToGenerated(spendableOutput)
+		conflict.SpOuts = append(conflict.SpOuts, &spOut)
+	}
+	return conflict
+}
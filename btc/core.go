@@ -0,0 +1,313 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package btc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/Multy-io/Multy-BTC-node-service/zmq"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	topicRawBlock = "rawblock"
+	topicRawTx    = "rawtx"
+
+	zmqRetryInterval = 5 * time.Second
+)
+
+/*
+CoreBackend is Bitcoin Core connected by HTTP POST rpc.
+
+The cookie is reread when the node rejects credentials after a restart.
+Notifications come from zmqpubrawblock and zmqpubrawtx. A raw block makes
+the poller catch up with the best block, so disconnected blocks are found
+by comparing the chain with the last seen tip. Raw transactions are looked up
+in mempool. The node is polled when ZMQ is not configured, is not connected
+or skipped a message sequence number.
+*/
+type CoreBackend struct {
+	*postClient
+
+	conf        RPCConf
+	host        string
+	certificate []byte
+	poller      *poller
+	quit        chan struct{}
+
+	m           sync.Mutex
+	subscribers map[string]*zmq.Subscriber
+}
+
+func NewCoreBackend(conf RPCConf, host string, certificate []byte) *CoreBackend {
+	return &CoreBackend{
+		conf:        conf,
+		host:        host,
+		certificate: certificate,
+		quit:        make(chan struct{}),
+		subscribers: map[string]*zmq.Subscriber{},
+	}
+}
+
+func (b *CoreBackend) Connect(handlers BackendHandlers) error {
+	client, err := newPostClient(b.conf, b.host, b.certificate)
+	if err != nil {
+		return err
+	}
+	b.postClient = client
+	b.poller = newPoller(b, handlers)
+	return nil
+}
+
+func (b *CoreBackend) Subscribe() error {
+	b.poller.start(b.conf.pollInterval(), b.quit, b.pollMempool)
+
+	if b.conf.ZMQBlock != "" {
+		go b.listen(b.conf.ZMQBlock, topicRawBlock, b.rawBlock, b.poller.triggerBlocks)
+	}
+	if b.conf.ZMQTx != "" {
+		go b.listen(b.conf.ZMQTx, topicRawTx, b.rawTx, b.poller.triggerMempool)
+	}
+	if b.conf.ZMQBlock == "" || b.conf.ZMQTx == "" {
+		log.Warn("Subscribe: ZMQ is not configured, polling the node")
+	}
+	return nil
+}
+
+func (b *CoreBackend) Shutdown() {
+	select {
+	case <-b.quit:
+	default:
+		close(b.quit)
+	}
+	b.m.Lock()
+	for _, sub := range b.subscribers {
+		sub.Close()
+	}
+	b.m.Unlock()
+	b.postClient.Shutdown()
+}
+
+// pollMempool reports if mempool should be polled because transactions are not received by ZMQ
+func (b *CoreBackend) pollMempool() bool {
+	b.m.Lock()
+	defer b.m.Unlock()
+	return b.subscribers[topicRawTx] == nil
+}
+
+func (b *CoreBackend) setSubscriber(topic string, sub *zmq.Subscriber) {
+	b.m.Lock()
+	defer b.m.Unlock()
+	if sub == nil {
+		delete(b.subscribers, topic)
+		return
+	}
+	b.subscribers[topic] = sub
+}
+
+/*
+listen receives the topic and reconnects until shutdown. Messages are
+[topic, body, sequence]. Missed messages are recovered by polling on
+reconnect and on sequence gaps.
+*/
+func (b *CoreBackend) listen(address, topic string, handle func(body []byte), missed func()) {
+	for {
+		select {
+		case <-b.quit:
+			return
+		default:
+		}
+
+		sub, err := zmq.Subscribe(address, topic)
+		if err != nil {
+			log.Errorf("listen:zmq.Subscribe %s %s: %s", topic, address, err.Error())
+			select {
+			case <-time.After(zmqRetryInterval):
+			case <-b.quit:
+				return
+			}
+			continue
+		}
+		b.setSubscriber(topic, sub)
+		log.Infof("listen: subscribed to %s at %s", topic, address)
+		missed()
+
+		var last uint32
+		sequenced := false
+		for {
+			parts, err := sub.Receive()
+			if err != nil {
+				log.Errorf("listen:Receive %s: %s", topic, err.Error())
+				break
+			}
+			if len(parts) < 2 || string(parts[0]) != topic {
+				continue
+			}
+			if len(parts) > 2 && len(parts[2]) == 4 {
+				seq := binary.LittleEndian.Uint32(parts[2])
+				if sequenced && seq != last+1 {
+					log.Warnf("listen: %s sequence %d after %d", topic, seq, last)
+					missed()
+				}
+				last, sequenced = seq, true
+			}
+			handle(parts[1])
+		}
+
+		b.setSubscriber(topic, nil)
+		sub.Close()
+	}
+}
+
+func (b *CoreBackend) rawBlock(body []byte) {
+	header := wire.BlockHeader{}
+	if err := header.Deserialize(bytes.NewReader(body)); err != nil {
+		log.Errorf("rawBlock:header.Deserialize: %s", err.Error())
+	} else {
+		log.Debugf("rawBlock: %s", header.BlockHash())
+	}
+	b.poller.triggerBlocks()
+}
+
+func (b *CoreBackend) rawTx(body []byte) {
+	tx := wire.MsgTx{}
+	if err := tx.Deserialize(bytes.NewReader(body)); err != nil {
+		log.Errorf("rawTx:tx.Deserialize: %s", err.Error())
+		return
+	}
+	hash := tx.TxHash()
+	if !b.poller.markSeen(hash.String()) {
+		return
+	}
+	b.poller.acceptTx(&hash)
+}
+
+// GetBestBlock is btcd extension, Core answers it with two calls
+func (b *CoreBackend) GetBestBlock() (*chainhash.Hash, int32, error) {
+	raw, err := b.RawRequest("getbestblockhash", nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	var hashStr string
+	if err := json.Unmarshal(raw, &hashStr); err != nil {
+		return nil, 0, err
+	}
+	hash, err := chainhash.NewHashFromStr(hashStr)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	param, _ := json.Marshal(hashStr)
+	raw, err = b.RawRequest("getblockheader", []json.RawMessage{param})
+	if err != nil {
+		return nil, 0, err
+	}
+	header := struct {
+		Height int32 `json:"height"`
+	}{}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, 0, err
+	}
+	return hash, header.Height, nil
+}
+
+// SendRawTransaction uses maxfeerate of Core, zero fee rate means no limit
+func (b *CoreBackend) SendRawTransaction(txHex string, allowHighFees bool) (*chainhash.Hash, error) {
+	param, _ := json.Marshal(txHex)
+	params := []json.RawMessage{param}
+	if allowHighFees {
+		params = append(params, json.RawMessage("0"))
+	}
+	raw, err := b.RawRequest("sendrawtransaction", params)
+	if err != nil {
+		return nil, err
+	}
+	var txid string
+	if err := json.Unmarshal(raw, &txid); err != nil {
+		return nil, err
+	}
+	return chainhash.NewHashFromStr(txid)
+}
+
+func (b *CoreBackend) GetRawTransactionVerbose(hash *chainhash.Hash) (*btcjson.TxRawResult, error) {
+	txid, _ := json.Marshal(hash.String())
+	raw, err := b.RawRequest("getrawtransaction", []json.RawMessage{txid, json.RawMessage("1")})
+	if err != nil {
+		return nil, err
+	}
+	return coreTxResult(raw)
+}
+
+func (b *CoreBackend) DecodeRawTransaction(serializedTx []byte) (*btcjson.TxRawResult, error) {
+	param, _ := json.Marshal(hex.EncodeToString(serializedTx))
+	raw, err := b.RawRequest("decoderawtransaction", []json.RawMessage{param})
+	if err != nil {
+		return nil, err
+	}
+	return coreTxResult(raw)
+}
+
+// GetRawMempoolVerbose fills fee and size removed from recent Core versions
+func (b *CoreBackend) GetRawMempoolVerbose() (map[string]btcjson.GetRawMempoolVerboseResult, error) {
+	raw, err := b.RawRequest("getrawmempool", []json.RawMessage{json.RawMessage("true")})
+	if err != nil {
+		return nil, err
+	}
+	mempool := map[string]btcjson.GetRawMempoolVerboseResult{}
+	if err := json.Unmarshal(raw, &mempool); err != nil {
+		return nil, err
+	}
+	fees := map[string]struct {
+		Fees struct {
+			Base float64 `json:"base"`
+		} `json:"fees"`
+	}{}
+	if err := json.Unmarshal(raw, &fees); err != nil {
+		return nil, err
+	}
+	for txid, entry := range mempool {
+		if entry.Fee == 0 {
+			entry.Fee = fees[txid].Fees.Base
+		}
+		if entry.Size == 0 {
+			entry.Size = entry.Vsize
+		}
+		mempool[txid] = entry
+	}
+	return mempool, nil
+}
+
+// coreTxResult decodes the transaction, Core since 22.0 returns
+// a single address of the output instead of addresses
+func coreTxResult(raw json.RawMessage) (*btcjson.TxRawResult, error) {
+	tx := btcjson.TxRawResult{}
+	if err := json.Unmarshal(raw, &tx); err != nil {
+		return nil, err
+	}
+	outputs := struct {
+		Vout []struct {
+			ScriptPubKey struct {
+				Address string `json:"address"`
+			} `json:"scriptPubKey"`
+		} `json:"vout"`
+	}{}
+	if err := json.Unmarshal(raw, &outputs); err != nil {
+		return nil, err
+	}
+	for i, output := range outputs.Vout {
+		if i < len(tx.Vout) && len(tx.Vout[i].ScriptPubKey.Addresses) == 0 && output.ScriptPubKey.Address != "" {
+			tx.Vout[i].ScriptPubKey.Addresses = []string{output.ScriptPubKey.Address}
+		}
+	}
+	return &tx, nil
+}
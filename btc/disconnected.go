@@ -5,43 +5,243 @@ See LICENSE for details
 */
 package btc
 
-// import (
-// 	"github.com/Multy-io/Multy-back/store"
-// 	"github.com/btcsuite/btcd/wire"
-// 	"gopkg.in/mgo.v2"
-// 	"gopkg.in/mgo.v2/bson"
-// )
-
-// func blockDisconnected(header *wire.BlockHeader) {
-// 	disconnectedHash := header.BlockHash()
-// 	disconnectedBlock, err := RpcClient.GetBlock(&disconnectedHash)
-// 	if err != nil {
-// 		log.Errorf("blockDisconnected:RpcClient.GetBlock: %s", err.Error())
-// 	}
-
-// 	rejectedTx := store.MultyTX{}
-// 	for _, tx := range disconnectedBlock.Transactions {
-// 		query := bson.M{"transactions.txhash": tx.TxHash()}
-// 		txsData.Find(query).One(&rejectedTx)
-// 		if err != nil {
-// 			if err == mgo.ErrNotFound {
-// 				continue
-// 			}
-// 			log.Errorf("blockDisconnected:txsData.Find: %s", err.Error())
-// 			continue
-// 		}
-
-// 		query = bson.M{"transactions.txhash": tx.TxHash()}
-// 		update := bson.M{
-// 			"$set": bson.M{
-// 				"transactions.$.txstatus": rejectedTx.TxStatus * -1,
-// 			},
-// 		}
-// 		err = txsData.Update(query, update)
-// 		if err != nil {
-// 			log.Errorf("txsData.Update add new tx to user: %s", err.Error())
-// 		}
-
-// 	}
-
-// }
+import (
+	pb "github.com/Multy-io/Multy-BTC-node-service/node-streamer"
+	"github.com/Multy-io/Multy-BTC-node-service/outbox"
+	"github.com/Multy-io/Multy-back/store"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// maxReorgScan is the number of main chain blocks checked for transactions of a disconnected block
+const maxReorgScan = 100
+
+/*
+blockDisconnected rolls back watched transactions of an orphaned block.
+
+Transactions already included in a block replacing the orphaned one are left
+as they are, they are reported when that block is processed.
+Every other related transaction is reported with a mempool status if the node put it
+back to mempool, otherwise with a negative (rejected) status.
+Spent outpoints of transactions which went back to mempool are tracked again
+to detect replacements, outputs are rolled back by rollbackOutputs.
+*/
+func (c *Client) blockDisconnected(height int32, header *wire.BlockHeader) {
+	hash := header.BlockHash()
+	log.Warnf("Block disconnected %s (%d)", hash.String(), height)
+
+	disconnectedBlock, err := c.RPCClient.GetBlock(&hash)
+	if err != nil {
+		log.Errorf("blockDisconnected:RPCClient.GetBlock: %s", err.Error())
+		return
+	}
+
+	mempool := map[string]bool{}
+	mempoolHashes, err := c.RPCClient.GetRawMempool()
+	if err != nil {
+		log.Errorf("blockDisconnected:RPCClient.GetRawMempool: %s", err.Error())
+	}
+	for _, txHash := range mempoolHashes {
+		mempool[txHash.String()] = true
+	}
+
+	// the disconnect is processed from the queue, so blocks of the new chain may be already connected
+	mainChain, err := c.mainChainTxs(int64(height))
+	if err != nil {
+		log.Errorf("blockDisconnected:mainChainTxs: %s", err.Error())
+	}
+
+	c.unindexBlock(int64(height))
+
+	disconnected := pb.BlockDisconnected{
+		Height: int64(height),
+		Hash:   hash.String(),
+	}
+
+	// transaction could be already removed from the node's tx index,
+	// so we decode it from the block itself
+	txs := c.blockTransactions(disconnectedBlock, false)
+
+	// the whole block is tracked first, a child back in mempool spends outputs of its parent
+	for _, tx := range txs {
+		if mempool[tx.Txid] {
+			c.mempoolSpends.add(tx, c.watchedTx(tx))
+		}
+	}
+
+	rolledBack := []*Tx{}
+	for _, tx := range txs {
+		multyTx, related := c.ParseRawTransaction(-1, tx)
+		if !related {
+			continue
+		}
+
+		c.untrackConfirmations(tx.Txid)
+
+		if mainChain[tx.Txid] {
+			log.Debugf("blockDisconnected: %s is mined again on the main chain", tx.Txid)
+			continue
+		}
+
+		inMempool := mempool[tx.Txid]
+
+		c.setTransactionInfo(multyTx, tx, -1, false)
+		transactions := c.splitTransaction(*multyTx, -1)
+		for _, transaction := range transactions {
+			finalizeTransaction(&transaction, tx)
+			if !inMempool {
+				transaction.TxStatus = transaction.TxStatus * -1
+			}
+			if generated, ok := multyTxToGenerated(transaction); ok {
+				setTxDetails(&generated, tx)
+				disconnected.Txs = append(disconnected.Txs, &generated)
+			}
+		}
+
+		rolledBack = append(rolledBack, tx)
+	}
+
+	saved, deleted := c.rollbackOutputs(rolledBack, mempool)
+	for _, spendableOutput := range deleted {
+		c.removeSpendableOutput(spendableOutput.TxID, spendableOutput.TxOutID)
+		disconnected.SpOutDelete = append(disconnected.SpOutDelete, &pb.ReqDeleteSpOut{
+			UserID:  spendableOutput.UserID,
+			TxID:    spendableOutput.TxID,
+			Address: spendableOutput.Address,
+			TxOutID: int32(spendableOutput.TxOutID),
+		})
+	}
+	for _, spendableOutput := range saved {
+		c.saveSpendableOutput(spendableOutput)
+		spOut := spOutToGenerated(spendableOutput)
+		disconnected.SpOuts = append(disconnected.SpOuts, &spOut)
+	}
+
+	c.emit(outbox.KindBlockDisconnected, &disconnected)
+
+	c.setLastBlock(header.PrevBlock.String(), int64(height)-1)
+}
+
+/*
+rollbackOutputs returns spendable outputs to save and to delete for rolled back
+transactions of the orphaned block, spends of the ones back in mempool are already tracked.
+
+Outputs of transactions which went back to mempool are re-added with mempool status
+unless a mempool transaction spends them. Outputs of rejected transactions are
+deleted and outputs spent by them are restored, except the ones created by
+rolled back transactions of the block and the ones spent in mempool.
+*/
+func (c *Client) rollbackOutputs(txs []*Tx, mempool map[string]bool) ([]store.SpendableOutputs, []store.SpendableOutputs) {
+	rolledBack := map[string]bool{}
+	for _, tx := range txs {
+		rolledBack[tx.Txid] = true
+	}
+
+	saved := []store.SpendableOutputs{}
+	deleted := []store.SpendableOutputs{}
+	for _, tx := range txs {
+		if mempool[tx.Txid] {
+			for _, spendableOutput := range c.spendableOutputs(tx, -1) {
+				if _, spent := c.mempoolSpender(spendableOutput.TxID, uint32(spendableOutput.TxOutID)); spent {
+					continue
+				}
+				saved = append(saved, spendableOutput)
+			}
+			continue
+		}
+
+		deleted = append(deleted, c.spendableOutputs(tx, -1)...)
+		for _, spendableOutput := range c.spentOutputs(tx) {
+			if rolledBack[spendableOutput.TxID] {
+				continue
+			}
+			if _, spent := c.mempoolSpender(spendableOutput.TxID, uint32(spendableOutput.TxOutID)); spent {
+				continue
+			}
+			saved = append(saved, spendableOutput)
+		}
+	}
+	return saved, deleted
+}
+
+// mainChainTxs returns transactions of main chain blocks from the height up to the best block
+func (c *Client) mainChainTxs(from int64) (map[string]bool, error) {
+	txs := map[string]bool{}
+	best, err := c.RPCClient.GetBlockCount()
+	if err != nil {
+		return txs, err
+	}
+	if best-from >= maxReorgScan {
+		from = best - maxReorgScan + 1
+	}
+	for height := from; height <= best; height++ {
+		hash, err := c.RPCClient.GetBlockHash(height)
+		if err != nil {
+			return txs, err
+		}
+		block, err := c.RPCClient.GetBlock(hash)
+		if err != nil {
+			return txs, err
+		}
+		for _, msgTx := range block.Transactions {
+			txs[msgTx.TxHash().String()] = true
+		}
+	}
+	return txs, nil
+}
+
+// spendableOutputs returns outputs of the transaction for each owning wallet
+func (c *Client) spendableOutputs(tx *Tx, blockHeight int64) []store.SpendableOutputs {
+	spOuts := []store.SpendableOutputs{}
+	for _, output := range tx.Out {
+		txStatus := store.TxStatusAppearedInBlockIncoming
+		if blockHeight == -1 {
+			txStatus = store.TxStatusAppearedInMempoolIncoming
+		}
+
+		for _, owner := range c.spendableOwners(&output) {
+			spOuts = append(spOuts, store.SpendableOutputs{
+				TxID:         tx.Txid,
+				TxOutID:      int(output.N),
+				TxOutAmount:  int64(output.Value),
+				TxOutScript:  output.Script,
+				Address:      owner.Address,
+				UserID:       owner.UserID,
+				TxStatus:     txStatus,
+				WalletIndex:  owner.WalletIndex,
+				AddressIndex: owner.AddressIndex,
+			})
+		}
+	}
+	return spOuts
+}
+
+// spentOutputs returns previous outputs of our users spent by the transaction
+// with the status of the transaction which created them
+func (c *Client) spentOutputs(tx *Tx) []store.SpendableOutputs {
+	spOuts := []store.SpendableOutputs{}
+	for _, input := range tx.In {
+		output := input.Prevout
+		if output == nil {
+			continue
+		}
+		txStatus := store.TxStatusAppearedInBlockIncoming
+		if !input.PrevoutMined {
+			txStatus = store.TxStatusAppearedInMempoolIncoming
+		}
+
+		for _, owner := range c.spendableOwners(output) {
+			spOuts = append(spOuts, store.SpendableOutputs{
+				TxID:         input.Txid,
+				TxOutID:      int(input.Vout),
+				TxOutAmount:  int64(output.Value),
+				TxOutScript:  output.Script,
+				Address:      owner.Address,
+				UserID:       owner.UserID,
+				TxStatus:     txStatus,
+				WalletIndex:  owner.WalletIndex,
+				AddressIndex: owner.AddressIndex,
+			})
+		}
+	}
+	return spOuts
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package btc
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/Multy-io/Multy-back/store"
+	"github.com/btcsuite/btcutil"
+)
+
+const (
+	testUser     = "user"
+	testAddress  = "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2"
+	otherAddress = "1JwSSubhmg6iPtRjtyqhUYYH7bZg3Lfy1T"
+)
+
+func testClient() *Client {
+	usersData := &sync.Map{}
+	usersData.Store(testAddress, store.AddressExtended{UserID: testUser})
+	return &Client{
+		UsersData:     usersData,
+		mempoolSpends: newMempoolSpends(),
+	}
+}
+
+func testOut(n uint32, value int64, address string) TxOut {
+	return TxOut{N: n, Value: btcutil.Amount(value), Addresses: []string{address}, ReqSigs: 1}
+}
+
+func outpoints(spOuts []store.SpendableOutputs) []string {
+	keys := []string{}
+	for _, spOut := range spOuts {
+		keys = append(keys, outpointKey(spOut.TxID, uint32(spOut.TxOutID)))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// TestRollbackOutputs rolls back the parent and its child from one orphaned block
+func TestRollbackOutputs(t *testing.T) {
+	funding := testOut(0, 10000, testAddress)
+	parent := &Tx{
+		Txid: "parent",
+		In:   []TxIn{{Txid: "funding", Vout: 0, Prevout: &funding, PrevoutMined: true}},
+		Out:  []TxOut{testOut(0, 6000, testAddress), testOut(1, 3000, testAddress)},
+	}
+	child := &Tx{
+		Txid: "child",
+		In:   []TxIn{{Txid: "parent", Vout: 0, Prevout: &parent.Out[0], PrevoutMined: true}},
+		Out:  []TxOut{testOut(0, 5000, otherAddress), testOut(1, 500, testAddress)},
+	}
+
+	tests := []struct {
+		name        string
+		mempool     map[string]bool
+		wantSaved   []string
+		wantDeleted []string
+	}{
+		{
+			name:        "both rejected",
+			mempool:     map[string]bool{},
+			wantSaved:   []string{"funding:0"},
+			wantDeleted: []string{"child:1", "parent:0", "parent:1"},
+		},
+		{
+			name:        "both back in mempool",
+			mempool:     map[string]bool{"parent": true, "child": true},
+			wantSaved:   []string{"child:1", "parent:1"},
+			wantDeleted: []string{},
+		},
+		{
+			name:        "parent back in mempool, child rejected",
+			mempool:     map[string]bool{"parent": true},
+			wantSaved:   []string{"parent:0", "parent:1"},
+			wantDeleted: []string{"child:1"},
+		},
+	}
+	for _, test := range tests {
+		c := testClient()
+		for _, tx := range []*Tx{parent, child} {
+			if test.mempool[tx.Txid] {
+				c.mempoolSpends.add(tx, true)
+			}
+		}
+
+		saved, deleted := c.rollbackOutputs([]*Tx{parent, child}, test.mempool)
+		if got := outpoints(saved); !reflect.DeepEqual(got, test.wantSaved) {
+			t.Errorf("%s: saved %v, want %v", test.name, got, test.wantSaved)
+		}
+		if got := outpoints(deleted); !reflect.DeepEqual(got, test.wantDeleted) {
+			t.Errorf("%s: deleted %v, want %v", test.name, got, test.wantDeleted)
+		}
+	}
+}
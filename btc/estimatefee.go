@@ -0,0 +1,322 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package btc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcjson"
+)
+
+const (
+	// maxBlockSize is a block space in vbytes used to split mempool into future blocks
+	maxBlockSize = 1000000
+	// minRelayFeeRate is the lowest fee rate in sat/vB accepted by nodes
+	minRelayFeeRate = 1
+	// recentBlocks is a number of last blocks used for inclusion stats
+	recentBlocks = 12
+	// maxTrackedRates limits remembered mempool fee rates
+	maxTrackedRates = 300000
+	// blockFloorPercentile is a percentile of included fee rates treated as the block floor
+	blockFloorPercentile = 0.1
+)
+
+// DefaultFeeTargets are confirmation targets in blocks used if none requested
+var DefaultFeeTargets = []int{1, 3, 6, 12}
+
+// FeeConf configures fee estimation
+type FeeConf struct {
+	// Targets are default confirmation targets in blocks
+	Targets []int
+	// NodeWeight from 0 to 1 is a share of node's estimatesmartfee in the result.
+	// Zero disables node estimation.
+	NodeWeight float64
+}
+
+// Validate checks the node weight is a share and targets are positive
+func (conf FeeConf) Validate() error {
+	if conf.NodeWeight < 0 || conf.NodeWeight > 1 {
+		return fmt.Errorf("fee NodeWeight %v is not within 0..1", conf.NodeWeight)
+	}
+	for _, target := range conf.Targets {
+		if target < 1 {
+			return fmt.Errorf("fee target %d is not positive", target)
+		}
+	}
+	return nil
+}
+
+// FeeEstimate is a recommended fee rate in sat/vB for the confirmation target
+type FeeEstimate struct {
+	Target         int
+	FeeRate        int64
+	MempoolFeeRate int64
+	BlocksFeeRate  int64
+	NodeFeeRate    int64
+}
+
+// feeStats remembers fee rates of mempool transactions to know
+// which rates got into recent blocks
+type feeStats struct {
+	m      sync.Mutex
+	rates  map[string]int
+	floors []blockFloor
+}
+
+type blockFloor struct {
+	height int64
+	rate   int
+}
+
+func newFeeStats() *feeStats {
+	return &feeStats{
+		rates: map[string]int{},
+	}
+}
+
+// addMempoolTx remembers the fee rate of the mempool transaction
+func (f *feeStats) addMempoolTx(txid string, rate int) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	if len(f.rates) >= maxTrackedRates {
+		// evicted transactions are never mined, forget the half of rates
+		i := 0
+		for txid := range f.rates {
+			if i%2 == 0 {
+				delete(f.rates, txid)
+			}
+			i++
+		}
+	}
+	f.rates[txid] = rate
+}
+
+// removeMempoolTx forgets the rate of the transaction which can't be mined anymore
+func (f *feeStats) removeMempoolTx(txid string) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	delete(f.rates, txid)
+}
+
+// addBlock saves inclusion stats of the block made from rates of transactions seen in mempool
+func (f *feeStats) addBlock(height int64, txids []string) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	included := []int{}
+	for _, txid := range txids {
+		if rate, ok := f.rates[txid]; ok {
+			included = append(included, rate)
+			delete(f.rates, txid)
+		}
+	}
+	if len(included) == 0 {
+		return
+	}
+	sort.Ints(included)
+	floor := blockFloor{
+		height: height,
+		rate:   included[int(float64(len(included)-1)*blockFloorPercentile)],
+	}
+
+	// disconnected blocks are replaced
+	floors := []blockFloor{}
+	for _, f := range f.floors {
+		if f.height < height {
+			floors = append(floors, f)
+		}
+	}
+	floors = append(floors, floor)
+	if len(floors) > recentBlocks {
+		floors = floors[len(floors)-recentBlocks:]
+	}
+	f.floors = floors
+}
+
+// blocksFeeRate returns the fee rate which got into blocks often enough for the target.
+// Floors of recent blocks are sorted from the highest and the target-th one is used.
+func (f *feeStats) blocksFeeRate(target int) int64 {
+	f.m.Lock()
+	defer f.m.Unlock()
+	if len(f.floors) == 0 {
+		return 0
+	}
+	rates := []int{}
+	for _, floor := range f.floors {
+		rates = append(rates, floor.rate)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(rates)))
+	i := target - 1
+	if i >= len(rates) {
+		i = len(rates) - 1
+	}
+	return int64(rates[i])
+}
+
+type mempoolTxRate struct {
+	rate float64
+	size int32
+}
+
+/*
+EstimateFee returns fee rates in sat/vB for confirmation targets.
+
+Mempool is sorted by fee rate and split into blocks, the rate at the end of
+the target-th block is the mempool estimate. It is averaged with the rate
+which got into recent blocks and blended with the node's estimatesmartfee
+if it's enabled. Rates never grow with the target.
+*/
+func (c *Client) EstimateFee(targets []int) ([]FeeEstimate, int, int64, error) {
+	if len(targets) == 0 {
+		targets = c.feeConf.Targets
+	}
+	if len(targets) == 0 {
+		targets = DefaultFeeTargets
+	}
+	targets = append([]int{}, targets...)
+	sort.Ints(targets)
+
+	mempool, err := c.RPCClient.GetRawMempoolVerbose()
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	// children paying for their parents are counted with package rates
+	g := newVerboseGraph(mempool)
+	rates := packageRates(g, g.txids())
+
+	txRates := []mempoolTxRate{}
+	var mempoolBytes int64
+	for txid, txInfo := range mempool {
+		vsize := mempoolVsize(txInfo)
+		if vsize <= 0 {
+			continue
+		}
+		txRates = append(txRates, mempoolTxRate{
+			rate: rates[txid],
+			size: vsize,
+		})
+		mempoolBytes += int64(vsize)
+	}
+	sort.Slice(txRates, func(i, j int) bool {
+		return txRates[i].rate > txRates[j].rate
+	})
+
+	estimates := []FeeEstimate{}
+	var previous int64
+	for _, target := range targets {
+		if target < 1 {
+			continue
+		}
+		estimate := FeeEstimate{
+			Target:         target,
+			MempoolFeeRate: mempoolFeeRate(txRates, target),
+			BlocksFeeRate:  c.fees.blocksFeeRate(target),
+		}
+
+		rate := float64(estimate.MempoolFeeRate)
+		if estimate.BlocksFeeRate > 0 {
+			rate = (rate + float64(estimate.BlocksFeeRate)) / 2
+		}
+
+		if c.feeConf.NodeWeight > 0 {
+			nodeRate, err := c.nodeFeeRate(target)
+			if err != nil {
+				log.Errorf("EstimateFee:nodeFeeRate: %s", err.Error())
+			}
+			if nodeRate > 0 {
+				estimate.NodeFeeRate = nodeRate
+				rate = rate*(1-c.feeConf.NodeWeight) + float64(nodeRate)*c.feeConf.NodeWeight
+			}
+		}
+
+		estimate.FeeRate = int64(math.Ceil(rate))
+		if estimate.FeeRate < minRelayFeeRate {
+			estimate.FeeRate = minRelayFeeRate
+		}
+		if previous > 0 && estimate.FeeRate > previous {
+			estimate.FeeRate = previous
+		}
+		previous = estimate.FeeRate
+
+		estimates = append(estimates, estimate)
+	}
+	return estimates, len(txRates), mempoolBytes, nil
+}
+
+// mempoolFeeRate returns the lowest rate which fits into target blocks of sorted mempool
+func mempoolFeeRate(txRates []mempoolTxRate, target int) int64 {
+	space := int64(target) * maxBlockSize
+	var used int64
+	for _, tx := range txRates {
+		used += int64(tx.size)
+		if used >= space {
+			return int64(math.Ceil(tx.rate))
+		}
+	}
+	// mempool is cleared before the target
+	return minRelayFeeRate
+}
+
+/*
+nodeFeeRate asks the node estimatesmartfee, the result is in sat/vB.
+Nodes without estimatesmartfee like btcd are asked estimatefee instead.
+*/
+func (c *Client) nodeFeeRate(target int) (int64, error) {
+	param, err := json.Marshal(target)
+	if err != nil {
+		return 0, err
+	}
+	raw, err := c.RPCClient.RawRequest("estimatesmartfee", []json.RawMessage{param})
+	if isMethodNotFound(err) {
+		return c.nodeEstimateFee(param)
+	}
+	if err != nil {
+		return 0, err
+	}
+	result := struct {
+		// BTC per kilobyte
+		FeeRate float64  `json:"feerate"`
+		Errors  []string `json:"errors"`
+	}{}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return 0, err
+	}
+	if result.FeeRate <= 0 {
+		return 0, nil
+	}
+	return int64(math.Ceil(result.FeeRate * SatoshiToBitcoin / 1000)), nil
+}
+
+// nodeEstimateFee asks the node estimatefee, the result is in sat/vB
+func (c *Client) nodeEstimateFee(param json.RawMessage) (int64, error) {
+	raw, err := c.RPCClient.RawRequest("estimatefee", []json.RawMessage{param})
+	if isMethodNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	// BTC per kilobyte, negative if the node has no estimate
+	var feeRate float64
+	if err := json.Unmarshal(raw, &feeRate); err != nil {
+		return 0, err
+	}
+	if feeRate <= 0 {
+		return 0, nil
+	}
+	return int64(math.Ceil(feeRate * SatoshiToBitcoin / 1000)), nil
+}
+
+// isMethodNotFound tells the node doesn't support the RPC
+func isMethodNotFound(err error) bool {
+	rpcErr, ok := err.(*btcjson.RPCError)
+	return ok && rpcErr.Code == btcjson.ErrRPCMethodNotFound.Code
+}
@@ -1,33 +1,43 @@
 package btc
 
 import (
-	"math"
-
-	"github.com/Multy-io/Multy-back/store"
+	pb "github.com/Multy-io/Multy-BTC-node-service/node-streamer"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcutil"
 	_ "github.com/jekabolt/slflog"
 )
 
-const (
-	btcToSatoshi = 100000000
-)
+// mempoolVsize is the virtual size of mempool entry, old nodes report only size
+func mempoolVsize(txInfo btcjson.GetRawMempoolVerboseResult) int32 {
+	if txInfo.Vsize > 0 {
+		return txInfo.Vsize
+	}
+	return txInfo.Size
+}
 
-func (c *Client) GetAllMempool() ([]store.MempoolRecord, error) {
-	allMempool := []store.MempoolRecord{}
+func (c *Client) GetAllMempool() ([]pb.MempoolRecord, error) {
+	allMempool := []pb.MempoolRecord{}
 	mempool, err := c.RPCClient.GetRawMempoolVerbose()
 	if err != nil {
 		return allMempool, err
 	}
 	log.Warnf("MEMPOOL SIZE == %v", len(mempool))
 
+	g := newVerboseGraph(mempool)
+	rates := packageRates(g, g.txids())
 	for hash, txInfo := range mempool {
+		fee, err := btcutil.NewAmount(txInfo.Fee)
+		if err != nil {
+			log.Errorf("GetAllMempool:btcutil.NewAmount: %s", err.Error())
+			continue
+		}
+		vsize := mempoolVsize(txInfo)
 
-		floatFee := txInfo.Fee / float64(txInfo.Size) * btcToSatoshi
-
-		//It's some kind of Round function to prefent 0 FeeRates while casting from float to int
-		intFee := int(math.Floor(floatFee + 0.5))
+		// mempool entries have no weight, it's rounded up to the virtual size
+		rec := newMempoolRecord(feeRate(fee, vsize), rates[hash], hash, vsize, vsize*witnessScaleFactor)
 		// Node has transatctions withch not exist
-		if intFee > 0 {
-			allMempool = append(allMempool, newMempoolRecord(intFee, hash))
+		if rec.Category > 0 {
+			allMempool = append(allMempool, rec)
 		}
 	}
 	return allMempool, err
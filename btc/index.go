@@ -0,0 +1,186 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package btc
+
+import (
+	"github.com/Multy-io/Multy-BTC-node-service/storage"
+	"github.com/Multy-io/Multy-back/store"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// IndexConf configures the local address to transactions index.
+// The file storage keeps the index in memory, the mongo storage suits large ranges better.
+type IndexConf struct {
+	Enabled bool
+	// BackfillFrom and BackfillTo is a range of heights indexed on start.
+	// Zero BackfillTo means the best block.
+	BackfillFrom int64
+	BackfillTo   int64
+}
+
+// indexBlock adds addresses and script hashes of block transactions outputs and spent outputs to the index
+func (c *Client) indexBlock(height int64, txs []*Tx) {
+	addressTxs := []storage.AddressTx{}
+	for _, tx := range txs {
+		addresses := map[string]bool{}
+		for _, output := range tx.Out {
+			for _, address := range output.watchKeys() {
+				addresses[address] = true
+			}
+		}
+
+		// outputs of the same block are resolved in memory
+		for _, input := range tx.In {
+			if input.Prevout == nil {
+				continue
+			}
+			for _, address := range input.Prevout.watchKeys() {
+				addresses[address] = true
+			}
+		}
+
+		for address := range addresses {
+			addressTxs = append(addressTxs, storage.AddressTx{
+				Address: address,
+				TxID:    tx.Txid,
+				Height:  height,
+			})
+		}
+	}
+
+	if err := c.Storage.IndexTxs(height, addressTxs); err != nil {
+		log.Errorf("indexBlock:Storage.IndexTxs: %s", err.Error())
+	}
+}
+
+func (c *Client) unindexBlock(height int64) {
+	if !c.index.Enabled {
+		return
+	}
+	if err := c.Storage.UnindexHeight(height); err != nil {
+		log.Errorf("unindexBlock:Storage.UnindexHeight: %s", err.Error())
+	}
+}
+
+// backfillIndex indexes configured range of blocks, it continues from
+// the last backfilled height after restart
+func (c *Client) backfillIndex() {
+	to := c.index.BackfillTo
+	if to == 0 {
+		best, err := c.RPCClient.GetBlockCount()
+		if err != nil {
+			log.Errorf("backfillIndex:RPCClient.GetBlockCount: %s", err.Error())
+			return
+		}
+		to = best
+	}
+
+	from := c.index.BackfillFrom
+	backfilled, err := c.Storage.BackfilledHeight()
+	if err != nil {
+		log.Errorf("backfillIndex:Storage.BackfilledHeight: %s", err.Error())
+	}
+	if backfilled >= from && backfilled <= to {
+		from = backfilled + 1
+	}
+	if from > to {
+		return
+	}
+	log.Infof("backfillIndex: indexing blocks %d - %d", from, to)
+
+	for height := from; height <= to; height++ {
+		hash, err := c.RPCClient.GetBlockHash(height)
+		if err != nil {
+			log.Errorf("backfillIndex:RPCClient.GetBlockHash: %s", err.Error())
+			return
+		}
+		block, err := c.RPCClient.GetBlock(hash)
+		if err != nil {
+			log.Errorf("backfillIndex:RPCClient.GetBlock: %s", err.Error())
+			return
+		}
+		c.indexBlock(height, c.backfillTransactions(block))
+
+		if err := c.Storage.SetBackfilledHeight(height); err != nil {
+			log.Errorf("backfillIndex:Storage.SetBackfilledHeight: %s", err.Error())
+		}
+		if height%1000 == 0 {
+			log.Infof("backfillIndex: indexed block %d", height)
+		}
+	}
+	log.Infof("backfillIndex: done")
+}
+
+/*
+backfillTransactions decodes transactions of the block without changing the prevout cache,
+so old blocks don't evict outputs needed by live ones. Spent outputs are taken from the block
+itself, found in the cache or fetched from the node.
+*/
+func (c *Client) backfillTransactions(block *wire.MsgBlock) []*Tx {
+	outputs := map[string]prevout{}
+	txs := make([]*Tx, 0, len(block.Transactions))
+	for _, msgTx := range block.Transactions {
+		tx := txFromMsg(msgTx, &block.Header, c.params)
+		for _, out := range tx.Out {
+			outputs[outpointKey(tx.Txid, out.N)] = prevout{TxOut: out, Mined: true}
+		}
+		txs = append(txs, tx)
+	}
+
+	fetched := map[string]bool{}
+	for _, tx := range txs {
+		for i, in := range tx.In {
+			if in.Coinbase {
+				continue
+			}
+			key := outpointKey(in.Txid, in.Vout)
+			output, ok := outputs[key]
+			if !ok {
+				output, ok = c.prevouts.peek(key)
+			}
+			if !ok && !fetched[in.Txid] {
+				fetched[in.Txid] = true
+				txVerbose, err := c.rawTxByTxid(in.Txid)
+				if err != nil {
+					log.Errorf("backfillTransactions:rawTxByTxid: %s", err.Error())
+					continue
+				}
+				prevTx := newTx(txVerbose, c.params)
+				for _, out := range prevTx.Out {
+					outputs[outpointKey(prevTx.Txid, out.N)] = prevout{TxOut: out, Mined: prevTx.BlockHash != ""}
+				}
+				output, ok = outputs[key]
+			}
+			if !ok {
+				continue
+			}
+			tx.In[i].Prevout = &output.TxOut
+			tx.In[i].PrevoutMined = output.Mined
+		}
+	}
+	return txs
+}
+
+// AddressHistory returns transactions of the address from the local index
+func (c *Client) AddressHistory(address string) ([]store.ResyncTx, error) {
+	addressTxs, err := c.Storage.AddressTxs(address)
+	if err != nil {
+		return nil, err
+	}
+	history := []store.ResyncTx{}
+	for _, tx := range addressTxs {
+		history = append(history, store.ResyncTx{
+			Hash:        tx.TxID,
+			BlockHeight: int(tx.Height),
+		})
+	}
+	return history, nil
+}
+
+// IndexEnabled reports if the local address index is used
+func (c *Client) IndexEnabled() bool {
+	return c.index.Enabled
+}
@@ -1,19 +1,29 @@
 package btc
 
 import (
-	pb "github.com/Multy-io/Multy-BTC-node-service/node-streamer"
+	"github.com/Multy-io/Multy-BTC-node-service/outbox"
 	"github.com/btcsuite/btcd/btcjson"
 )
 
 // ProcessTransaction from mempool
 func (c *Client) mempoolTransaction(inTx *btcjson.TxRawResult) {
-	// Brodcast new mempool transaction to mempool event
-	rec := c.rawTxToMempoolRec(inTx)
-	c.AddToMempool <- pb.MempoolRecord{
-		Category: int32(rec.Category),
-		HashTX:   rec.HashTX,
+	tx := c.transaction(inTx)
+	c.cacheOutputs(tx, false)
+	if _, ok := tx.Fee(); !ok {
+		log.Errorf("mempoolTransaction: fee of %s is unknown, spent outputs are not found", tx.Txid)
+	}
+
+	// mempool transactions spending the same outputs are replaced by this one
+	c.mempoolConflicts(tx, -1)
+	c.mempoolSpends.add(tx, c.watchedTx(tx))
+
+	// Brodcast new mempool transaction to mempool event,
+	// ancestors are sent again if the transaction raises their package rate
+	for _, rec := range c.mempoolSpends.records(tx.Txid) {
+		c.fees.addMempoolTx(rec.HashTX, int(rec.Category))
+		c.emit(outbox.KindAddMempool, &rec)
 	}
 
 	// Process tx for tx history and spendable outs
-	c.ProcessTransaction(-1, inTx, false)
+	c.ProcessTransaction(-1, tx, false)
 }
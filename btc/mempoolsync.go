@@ -0,0 +1,173 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package btc
+
+import (
+	"time"
+
+	pb "github.com/Multy-io/Multy-BTC-node-service/node-streamer"
+	"github.com/Multy-io/Multy-BTC-node-service/outbox"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// DefaultMempoolSyncInterval is how often the mempool view is reconciled with the node
+const DefaultMempoolSyncInterval = 60 * time.Second
+
+// MempoolSyncConf configures reconciliation of the mempool view
+type MempoolSyncConf struct {
+	Disabled bool
+	// Interval in seconds between reconciliations, 60 by default
+	Interval int
+}
+
+func (conf MempoolSyncConf) interval() time.Duration {
+	if conf.Interval <= 0 {
+		return DefaultMempoolSyncInterval
+	}
+	return time.Duration(conf.Interval) * time.Second
+}
+
+// addMember remembers a node's mempool transaction which is known only by txid, lock must be held
+func (ms *mempoolSpends) addMember(txid string) {
+	ms.trim()
+	spending := &spendingTx{
+		txid:       txid,
+		memberOnly: true,
+	}
+	ms.track(spending)
+	ms.txs[txid] = spending
+}
+
+/*
+reconcile compares the view with txids of the node's mempool.
+Transactions missing on two reconciliations in a row are removed and returned,
+so transactions of a block which is not processed yet aren't taken as dropped.
+Node's transactions which are not in the view are added by txid.
+*/
+func (ms *mempoolSpends) reconcile(mempool map[string]bool) []*spendingTx {
+	ms.m.Lock()
+	defer ms.m.Unlock()
+
+	gone := []*spendingTx{}
+	missing := map[string]bool{}
+	for txid, spending := range ms.txs {
+		if mempool[txid] {
+			continue
+		}
+		if !ms.missing[txid] {
+			missing[txid] = true
+			continue
+		}
+		ms.remove(txid)
+		gone = append(gone, spending)
+	}
+	ms.missing = missing
+
+	for txid := range mempool {
+		if _, ok := ms.txs[txid]; !ok {
+			ms.addMember(txid)
+		}
+	}
+	return gone
+}
+
+// syncMempool reconciles the mempool view with the node until the backend is shut down
+func (c *Client) syncMempool() {
+	if c.mempoolSync.Disabled {
+		return
+	}
+	ticker := time.NewTicker(c.mempoolSync.interval())
+	defer ticker.Stop()
+	for range ticker.C {
+		c.reconcileMempool()
+	}
+}
+
+/*
+reconcileMempool reports transactions which left the node's mempool without
+being mined or replaced in front of us: evicted for low fee, expired or lost on
+the node restart. Deletions of transactions known only by txid have unknown reason.
+Watched transactions are rolled back like conflicted ones with a dropped event.
+It's skipped while the node has blocks which are not processed yet, transactions
+mined in them left mempool and can't be found without txindex.
+*/
+func (c *Client) reconcileMempool() {
+	hashes, err := c.RPCClient.GetRawMempool()
+	if err != nil {
+		log.Errorf("reconcileMempool:GetRawMempool: %s", err.Error())
+		return
+	}
+	pending, err := c.blocksPending()
+	if err != nil {
+		log.Errorf("reconcileMempool:blocksPending: %s", err.Error())
+		return
+	}
+	if pending {
+		log.Debugf("reconcileMempool: blocks are not processed yet, skipped")
+		return
+	}
+	mempool := map[string]bool{}
+	for _, hash := range hashes {
+		mempool[hash.String()] = true
+	}
+
+	gone := c.mempoolSpends.reconcile(mempool)
+	goneTxids := map[string]bool{}
+	for _, spending := range gone {
+		goneTxids[spending.txid] = true
+	}
+
+	for _, spending := range gone {
+		if spending.tx != nil && c.minedTx(spending.txid) {
+			// the block is reported with its transactions
+			continue
+		}
+		reason := pb.MempoolDeleteReason_EVICTED
+		if spending.memberOnly {
+			reason = pb.MempoolDeleteReason_REASON_UNKNOWN
+		}
+		log.Infof("reconcileMempool: %s left mempool, %s", spending.txid, reason)
+
+		c.fees.removeMempoolTx(spending.txid)
+		c.emit(outbox.KindDeleteMempool, &pb.MempoolToDelete{
+			Hash:   spending.txid,
+			Reason: reason,
+		})
+		if spending.tx == nil {
+			continue
+		}
+		dropped := c.conflictRollback(spending.tx, map[string]bool{}, goneTxids)
+		dropped.Reason = reason
+		c.emit(outbox.KindTxDropped, &dropped)
+	}
+}
+
+// minedTx reports if the node knows the transaction in a block
+func (c *Client) minedTx(txid string) bool {
+	hash, err := chainhash.NewHashFromStr(txid)
+	if err != nil {
+		return false
+	}
+	tx, err := c.RPCClient.GetRawTransactionVerbose(hash)
+	if err != nil {
+		// transaction which left mempool isn't found without txindex
+		return false
+	}
+	return tx.BlockHash != ""
+}
+
+// blocksPending reports if the best block of the node is not the last processed one
+func (c *Client) blocksPending() (bool, error) {
+	bestHash, _, err := c.RPCClient.GetBestBlock()
+	if err != nil {
+		return false, err
+	}
+	last, err := c.Storage.LastBlock()
+	if err != nil {
+		return false, err
+	}
+	return last.Hash != bestHash.String(), nil
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package btc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil/base58"
+)
+
+// Network names used in configuration
+const (
+	NetworkMainnet = "mainnet"
+	NetworkTestnet = "testnet"
+	NetworkRegtest = "regtest"
+	NetworkSignet  = "signet"
+)
+
+// signetNet is the magic of the default signet
+const signetNet wire.BitcoinNet = 0x40cf030a
+
+// RegtestParams are regression test network parameters as Bitcoin Core uses them.
+// Vendored chaincfg shares the testnet bech32 prefix with regtest.
+var RegtestParams = regtestParams()
+
+// SignetParams are the default signet network parameters (BIP 325).
+// Vendored chaincfg has no signet, addresses are encoded like on testnet.
+var SignetParams = signetParams()
+
+func regtestParams() chaincfg.Params {
+	params := chaincfg.RegressionNetParams
+	params.Bech32HRPSegwit = "bcrt"
+	return params
+}
+
+func signetParams() chaincfg.Params {
+	params := chaincfg.TestNet3Params
+	params.Name = NetworkSignet
+	params.Net = signetNet
+	params.DefaultPort = "38333"
+	params.DNSSeeds = []chaincfg.DNSSeed{
+		{Host: "seed.signet.bitcoin.sprovoost.nl", HasFiltering: false},
+	}
+	params.GenesisBlock = nil
+	params.GenesisHash = newHashFromStr("00000008819873e925422c1ff0f99f7cc9bbb232af63a077a480a3633bee1ef6")
+	params.Checkpoints = nil
+	params.ReduceMinDifficulty = false
+	params.GenerateSupported = false
+	return params
+}
+
+func newHashFromStr(hexStr string) *chainhash.Hash {
+	hash, err := chainhash.NewHashFromStr(hexStr)
+	if err != nil {
+		panic(err)
+	}
+	return hash
+}
+
+// NetworkParams returns chain parameters of the network by its name.
+// Chain names of blockcypher and bitcoind are accepted as aliases.
+func NetworkParams(network string) (*chaincfg.Params, error) {
+	switch strings.ToLower(network) {
+	case NetworkMainnet, "main":
+		return &chaincfg.MainNetParams, nil
+	case NetworkTestnet, "testnet3", "test3", "test":
+		return &chaincfg.TestNet3Params, nil
+	case NetworkRegtest:
+		return &RegtestParams, nil
+	case NetworkSignet:
+		return &SignetParams, nil
+	}
+	return nil, fmt.Errorf("unknown network %q", network)
+}
+
+// NetworkName returns the configuration name of the network
+func NetworkName(params *chaincfg.Params) string {
+	switch *params.GenesisHash {
+	case *chaincfg.MainNetParams.GenesisHash:
+		return NetworkMainnet
+	case *chaincfg.TestNet3Params.GenesisHash:
+		return NetworkTestnet
+	case *RegtestParams.GenesisHash:
+		return NetworkRegtest
+	case *SignetParams.GenesisHash:
+		return NetworkSignet
+	}
+	return params.Name
+}
+
+/*
+ValidateAddress checks that the address is encoded for the network.
+
+Base58 addresses are matched by version byte and segwit addresses by
+bech32 prefix, so mainnet addresses are rejected on testnet and vice versa.
+Testnet, regtest and signet share base58 versions, they can't be told apart.
+Script hashes of outputs without addresses are accepted on any network.
+*/
+func ValidateAddress(address string, params *chaincfg.Params) error {
+	if IsScriptHash(address) {
+		return nil
+	}
+	if i := strings.LastIndexByte(address, '1'); i > 0 {
+		if strings.ToLower(address[:i]) == params.Bech32HRPSegwit {
+			hrp, _, _, err := decodeSegwitAddress(address)
+			if err != nil {
+				return fmt.Errorf("wrong bech32 address %s: %s", address, err.Error())
+			}
+			if hrp != params.Bech32HRPSegwit {
+				return fmt.Errorf("wrong bech32 address %s", address)
+			}
+			return nil
+		}
+	}
+
+	_, version, err := base58.CheckDecode(address)
+	if err != nil {
+		return fmt.Errorf("wrong address %s for %s: %s", address, NetworkName(params), err.Error())
+	}
+	if version != params.PubKeyHashAddrID && version != params.ScriptHashAddrID {
+		return fmt.Errorf("address %s is not for %s", address, NetworkName(params))
+	}
+	return nil
+}
+
+/*
+NormalizeAddress returns the form of the valid address used in scripts.
+Bech32 may be uppercase but addresses of outputs are lowercase,
+base58 addresses are case sensitive and returned as is.
+*/
+func NormalizeAddress(address string, params *chaincfg.Params) string {
+	if i := strings.LastIndexByte(address, '1'); i > 0 && strings.ToLower(address[:i]) == params.Bech32HRPSegwit {
+		return strings.ToLower(address)
+	}
+	return address
+}
+
+// checkNetwork compares genesis block of the node with the configured network
+func (c *Client) checkNetwork(rpc ChainBackend) error {
+	genesis, err := rpc.GetBlockHash(0)
+	if err != nil {
+		return fmt.Errorf("GetBlockHash: %s", err.Error())
+	}
+	if !genesis.IsEqual(c.params.GenesisHash) {
+		return fmt.Errorf("node genesis %s doesn't match %s genesis %s", genesis, NetworkName(c.params), c.params.GenesisHash)
+	}
+	return nil
+}
+
+// Params returns chain parameters of the configured network
+func (c *Client) Params() *chaincfg.Params {
+	return c.params
+}
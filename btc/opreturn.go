@@ -0,0 +1,156 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package btc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+
+	pb "github.com/Multy-io/Multy-BTC-node-service/node-streamer"
+	"github.com/Multy-io/Multy-BTC-node-service/outbox"
+	"github.com/Multy-io/Multy-back/store"
+)
+
+const (
+	opPushData1 = 0x4c
+	opPushData2 = 0x4d
+	opPushData4 = 0x4e
+	op1Negate   = 0x4f
+)
+
+// OpReturnConf configures watching of OP_RETURN outputs
+type OpReturnConf struct {
+	// Prefix is hex encoded data, transactions with OP_RETURN data starting
+	// with it are reported even if no watched address is involved.
+	// They are sent to subscribers of OP_RETURN_TX events only.
+	// Empty prefix disables the mode.
+	Prefix string
+}
+
+// Validate checks the prefix is hex
+func (conf OpReturnConf) Validate() error {
+	if _, err := hex.DecodeString(conf.Prefix); err != nil {
+		return fmt.Errorf("OP_RETURN prefix %q is not hex: %s", conf.Prefix, err.Error())
+	}
+	return nil
+}
+
+func (conf OpReturnConf) prefix() []byte {
+	prefix, _ := hex.DecodeString(conf.Prefix)
+	return prefix
+}
+
+/*
+nullData returns data pushed after OP_RETURN, pushes are concatenated.
+Data is read up to the first opcode which is not a push.
+*/
+func nullData(script []byte) ([]byte, bool) {
+	if len(script) == 0 || script[0] != opReturn {
+		return nil, false
+	}
+	data := []byte{}
+	for i := 1; i < len(script); {
+		op := script[i]
+		i++
+		size := 0
+		switch {
+		case op == op0:
+			continue
+		case op < opPushData1:
+			size = int(op)
+		case op == opPushData1 && i+1 <= len(script):
+			size = int(script[i])
+			i++
+		case op == opPushData2 && i+2 <= len(script):
+			size = int(binary.LittleEndian.Uint16(script[i:]))
+			i += 2
+		case op == opPushData4 && i+4 <= len(script):
+			size = int(binary.LittleEndian.Uint32(script[i:]))
+			i += 4
+		case op == op1Negate:
+			data = append(data, 0x81)
+			continue
+		case op >= op1 && op <= op16:
+			data = append(data, op-op1+1)
+			continue
+		default:
+			return data, true
+		}
+		if size < 0 || i+size > len(script) {
+			return data, true
+		}
+		data = append(data, script[i:i+size]...)
+		i += size
+	}
+	return data, true
+}
+
+// opReturns decodes OP_RETURN outputs of the transaction
+func opReturns(tx *Tx) []*pb.BTCTransaction_OpReturn {
+	opReturns := []*pb.BTCTransaction_OpReturn{}
+	for _, output := range tx.Out {
+		if output.Type != ScriptNullData {
+			continue
+		}
+		script, err := hex.DecodeString(output.Script)
+		if err != nil {
+			continue
+		}
+		data, ok := nullData(script)
+		if !ok {
+			continue
+		}
+		opReturns = append(opReturns, &pb.BTCTransaction_OpReturn{
+			TxOutIndex: int32(output.N),
+			Data:       data,
+			// invalid sequences are replaced with U+FFFD
+			Text: string([]rune(string(data))),
+		})
+	}
+	return opReturns
+}
+
+// matchOpReturn reports if some OP_RETURN data of the transaction starts with the configured prefix
+func (c *Client) matchOpReturn(tx *Tx) bool {
+	prefix := c.opReturn.prefix()
+	if len(prefix) == 0 {
+		return false
+	}
+	for _, opReturn := range opReturns(tx) {
+		if bytes.HasPrefix(opReturn.Data, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+opReturnTransaction reports transaction which has no watched addresses
+but has OP_RETURN data with the configured prefix. It's sent once on
+mempool and once on block without confirmations tracking. It has no owner,
+so it's a separate event kind and never goes to the NewTx stream.
+*/
+func (c *Client) opReturnTransaction(tx *Tx, blockHeight int64) {
+	multyTx := store.MultyTX{
+		TxID:        tx.Txid,
+		TxHash:      tx.Hash,
+		BlockHeight: blockHeight,
+		TxStatus:    TxStatusAppearedInMempoolIncoming,
+		BlockTime:   -1,
+	}
+	if blockHeight != -1 {
+		multyTx.TxStatus = TxStatusAppearedInBlockIncoming
+		multyTx.Confirmations = 1
+	}
+	c.setTransactionInfo(&multyTx, tx, blockHeight, false)
+
+	generated := storeTxToGenerated(multyTx)
+	setTxDetails(&generated, tx)
+	generated.OpReturnMatch = true
+	c.emit(outbox.KindOpReturnTx, &generated)
+}
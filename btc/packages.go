@@ -0,0 +1,205 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package btc
+
+import (
+	pb "github.com/Multy-io/Multy-BTC-node-service/node-streamer"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcutil"
+)
+
+/*
+Unconfirmed transactions are mined only together with their unconfirmed
+ancestors, so a fee rate of a single transaction is misleading: a low fee
+parent is mined quickly if its child pays for both (CPFP) and a high fee
+child waits for its low fee parent.
+
+The ancestor rate is the fee rate of the transaction with all its unconfirmed
+ancestors. The package rate is the effective rate: the best ancestor rate of
+the transaction itself and its descendants, since a descendant pulls all its
+ancestors into a block.
+*/
+
+// txGraph is a view of mempool transactions with their unconfirmed parents and children
+type txGraph interface {
+	// tx returns fee and virtual size of the mempool transaction
+	tx(txid string) (btcutil.Amount, int32, bool)
+	parents(txid string) []string
+	children(txid string) []string
+}
+
+// relatives returns transactions reachable from txid by next, txid itself is not included
+func relatives(txid string, next func(string) []string) []string {
+	found := []string{}
+	seen := map[string]bool{txid: true}
+	queue := []string{txid}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, relative := range next(current) {
+			if seen[relative] {
+				continue
+			}
+			seen[relative] = true
+			found = append(found, relative)
+			queue = append(queue, relative)
+		}
+	}
+	return found
+}
+
+// ancestorRate is the fee rate in sat/vB of the transaction with its unconfirmed ancestors
+func ancestorRate(g txGraph, txid string) float64 {
+	fee, vsize, ok := g.tx(txid)
+	if !ok {
+		return 0
+	}
+	for _, ancestor := range relatives(txid, g.parents) {
+		if ancestorFee, ancestorVsize, ok := g.tx(ancestor); ok {
+			fee += ancestorFee
+			vsize += ancestorVsize
+		}
+	}
+	return feeRate(fee, vsize)
+}
+
+// packageRates returns package rates of transactions, ancestor rates are calculated once
+func packageRates(g txGraph, txids []string) map[string]float64 {
+	ancestorRates := map[string]float64{}
+	ancestor := func(txid string) float64 {
+		rate, ok := ancestorRates[txid]
+		if !ok {
+			rate = ancestorRate(g, txid)
+			ancestorRates[txid] = rate
+		}
+		return rate
+	}
+
+	rates := map[string]float64{}
+	for _, txid := range txids {
+		rate := ancestor(txid)
+		for _, descendant := range relatives(txid, g.children) {
+			if descendantRate := ancestor(descendant); descendantRate > rate {
+				rate = descendantRate
+			}
+		}
+		rates[txid] = rate
+	}
+	return rates
+}
+
+// verboseGraph is the node's mempool, parents are known from depends
+type verboseGraph struct {
+	mempool    map[string]btcjson.GetRawMempoolVerboseResult
+	childrenOf map[string][]string
+}
+
+func newVerboseGraph(mempool map[string]btcjson.GetRawMempoolVerboseResult) verboseGraph {
+	g := verboseGraph{
+		mempool:    mempool,
+		childrenOf: map[string][]string{},
+	}
+	for txid, txInfo := range mempool {
+		for _, parent := range txInfo.Depends {
+			g.childrenOf[parent] = append(g.childrenOf[parent], txid)
+		}
+	}
+	return g
+}
+
+func (g verboseGraph) tx(txid string) (btcutil.Amount, int32, bool) {
+	txInfo, ok := g.mempool[txid]
+	if !ok {
+		return 0, 0, false
+	}
+	fee, err := btcutil.NewAmount(txInfo.Fee)
+	if err != nil {
+		return 0, 0, false
+	}
+	return fee, mempoolVsize(txInfo), true
+}
+
+func (g verboseGraph) parents(txid string) []string {
+	return g.mempool[txid].Depends
+}
+
+func (g verboseGraph) children(txid string) []string {
+	return g.childrenOf[txid]
+}
+
+// txids returns all transactions of the node's mempool
+func (g verboseGraph) txids() []string {
+	txids := make([]string, 0, len(g.mempool))
+	for txid := range g.mempool {
+		txids = append(txids, txid)
+	}
+	return txids
+}
+
+// tx returns fee and virtual size of the tracked transaction, lock must be held
+func (ms *mempoolSpends) tx(txid string) (btcutil.Amount, int32, bool) {
+	spending, ok := ms.txs[txid]
+	if !ok {
+		return 0, 0, false
+	}
+	return spending.fee, spending.vsize, true
+}
+
+// parents returns tracked transactions whose outputs are spent, lock must be held
+func (ms *mempoolSpends) parents(txid string) []string {
+	spending, ok := ms.txs[txid]
+	if !ok {
+		return nil
+	}
+	parents := []string{}
+	for _, parent := range spending.spent {
+		if _, ok := ms.txs[parent]; ok {
+			parents = append(parents, parent)
+		}
+	}
+	return parents
+}
+
+// children returns tracked transactions spending outputs, lock must be held
+func (ms *mempoolSpends) children(txid string) []string {
+	spending, ok := ms.txs[txid]
+	if !ok {
+		return nil
+	}
+	children := []string{}
+	for n := 0; n < spending.outputs; n++ {
+		if child, ok := ms.spenders[outpointKey(txid, uint32(n))]; ok {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+/*
+records returns the mempool record of the tracked transaction and records
+of its ancestors whose package rate category is raised by it.
+*/
+func (ms *mempoolSpends) records(txid string) []pb.MempoolRecord {
+	ms.m.Lock()
+	defer ms.m.Unlock()
+	if _, ok := ms.txs[txid]; !ok {
+		return nil
+	}
+	txids := append([]string{txid}, relatives(txid, ms.parents)...)
+	rates := packageRates(ms, txids)
+
+	records := []pb.MempoolRecord{}
+	for i, current := range txids {
+		spending := ms.txs[current]
+		rec := newMempoolRecord(feeRate(spending.fee, spending.vsize), rates[current], current, spending.vsize, spending.weight)
+		if i > 0 && rec.Category <= spending.category {
+			continue
+		}
+		spending.category = rec.Category
+		records = append(records, rec)
+	}
+	return records
+}
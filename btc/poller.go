@@ -0,0 +1,211 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package btc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+const (
+	// DefaultPollInterval is used when notifications are unavailable or lost
+	DefaultPollInterval = 10 * time.Second
+	// maxSeenTxs limits remembered mempool transactions
+	maxSeenTxs = 300000
+)
+
+/*
+poller follows the node by polling. It turns best block changes into
+disconnected and connected blocks and mempool changes into accepted transactions.
+
+Blocks are compared with the tip seen last time, so the first catchUp
+only remembers the best block and the first pollMempool only remembers mempool.
+*/
+type poller struct {
+	backend  ChainBackend
+	handlers BackendHandlers
+
+	tipHash   *chainhash.Hash
+	tipHeight int32
+
+	m        sync.Mutex
+	seen     map[string]struct{}
+	seenInit bool
+
+	blocks  chan struct{}
+	mempool chan struct{}
+}
+
+func newPoller(backend ChainBackend, handlers BackendHandlers) *poller {
+	return &poller{
+		backend:  backend,
+		handlers: handlers,
+		seen:     map[string]struct{}{},
+		blocks:   make(chan struct{}, 1),
+		mempool:  make(chan struct{}, 1),
+	}
+}
+
+// start runs polling loops until quit is closed.
+// Mempool is polled on ticks only while pollMempool returns true.
+func (p *poller) start(interval time.Duration, quit chan struct{}, pollMempool func() bool) {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	p.catchUp()
+	p.pollMempool()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-p.blocks:
+			case <-quit:
+				return
+			}
+			p.catchUp()
+		}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if !pollMempool() {
+					continue
+				}
+			case <-p.mempool:
+			case <-quit:
+				return
+			}
+			p.pollMempool()
+		}
+	}()
+}
+
+// triggerBlocks asks for catch up without waiting for the next tick
+func (p *poller) triggerBlocks() {
+	select {
+	case p.blocks <- struct{}{}:
+	default:
+	}
+}
+
+// triggerMempool asks for mempool poll without waiting for the next tick
+func (p *poller) triggerMempool() {
+	select {
+	case p.mempool <- struct{}{}:
+	default:
+	}
+}
+
+// catchUp reports blocks disconnected and connected since the last call
+func (p *poller) catchUp() {
+	bestHash, bestHeight, err := p.backend.GetBestBlock()
+	if err != nil {
+		log.Errorf("catchUp:GetBestBlock: %s", err.Error())
+		return
+	}
+	if p.tipHash == nil {
+		p.tipHash, p.tipHeight = bestHash, bestHeight
+		return
+	}
+	if p.tipHash.IsEqual(bestHash) {
+		return
+	}
+
+	// roll back the tip until it's on the best chain
+	for p.tipHeight > 0 {
+		hash, err := p.backend.GetBlockHash(int64(p.tipHeight))
+		if err == nil && hash.IsEqual(p.tipHash) {
+			break
+		}
+		if err != nil && p.tipHeight <= bestHeight {
+			log.Errorf("catchUp:GetBlockHash: %s", err.Error())
+			return
+		}
+		header, err := p.backend.GetBlockHeader(p.tipHash)
+		if err != nil {
+			log.Errorf("catchUp:GetBlockHeader: %s", err.Error())
+			return
+		}
+		p.handlers.OnBlockDisconnected(p.tipHeight, header)
+		p.tipHash = &header.PrevBlock
+		p.tipHeight--
+	}
+
+	for height := p.tipHeight + 1; height <= bestHeight; height++ {
+		hash, err := p.backend.GetBlockHash(int64(height))
+		if err != nil {
+			log.Errorf("catchUp:GetBlockHash: %s", err.Error())
+			return
+		}
+		p.handlers.OnBlockConnected(hash, height)
+		p.tipHash, p.tipHeight = hash, height
+	}
+}
+
+// pollMempool reports transactions which appeared in mempool since the last poll
+func (p *poller) pollMempool() {
+	hashes, err := p.backend.GetRawMempool()
+	if err != nil {
+		log.Errorf("pollMempool:GetRawMempool: %s", err.Error())
+		return
+	}
+
+	p.m.Lock()
+	first := !p.seenInit
+	p.seenInit = true
+	current := map[string]struct{}{}
+	fresh := []*chainhash.Hash{}
+	for _, hash := range hashes {
+		txid := hash.String()
+		current[txid] = struct{}{}
+		if _, ok := p.seen[txid]; !ok && !first {
+			fresh = append(fresh, hash)
+		}
+	}
+	p.seen = current
+	p.m.Unlock()
+
+	for _, hash := range fresh {
+		p.acceptTx(hash)
+	}
+}
+
+// markSeen remembers the transaction, false is returned if it was seen before
+func (p *poller) markSeen(txid string) bool {
+	p.m.Lock()
+	defer p.m.Unlock()
+	if _, ok := p.seen[txid]; ok {
+		return false
+	}
+	if len(p.seen) >= maxSeenTxs {
+		p.seen = map[string]struct{}{}
+	}
+	p.seen[txid] = struct{}{}
+	return true
+}
+
+// acceptTx reports the mempool transaction, mined ones are skipped
+func (p *poller) acceptTx(hash *chainhash.Hash) {
+	tx, err := p.backend.GetRawTransactionVerbose(hash)
+	if err != nil {
+		// transactions of connected blocks are not found without txindex
+		log.Debugf("acceptTx:GetRawTransactionVerbose: %s: %s", hash, err.Error())
+		return
+	}
+	if tx.BlockHash != "" {
+		return
+	}
+	p.handlers.OnTxAccepted(tx)
+}
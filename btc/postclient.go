@@ -0,0 +1,191 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package btc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/rpcclient"
+	"github.com/btcsuite/btcd/wire"
+)
+
+/*
+postClient is the node rpc client in HTTP POST mode. bitcoind writes a new
+cookie on restart, so when the node rejects credentials the configuration
+is made again with the cookie reread and the call is retried once.
+*/
+type postClient struct {
+	conf        RPCConf
+	host        string
+	certificate []byte
+	quit        chan struct{}
+
+	m      sync.Mutex
+	client *rpcclient.Client
+}
+
+func newPostClient(conf RPCConf, host string, certificate []byte) (*postClient, error) {
+	p := &postClient{
+		conf:        conf,
+		host:        host,
+		certificate: certificate,
+		quit:        make(chan struct{}),
+	}
+	client, err := p.connect()
+	if err != nil {
+		return nil, err
+	}
+	p.client = client
+	return p, nil
+}
+
+func (p *postClient) connect() (*rpcclient.Client, error) {
+	rpcConf, err := p.conf.connConfig(p.host, p.certificate)
+	if err != nil {
+		return nil, err
+	}
+	return rpcclient.New(rpcConf, nil)
+}
+
+func (p *postClient) current() *rpcclient.Client {
+	p.m.Lock()
+	defer p.m.Unlock()
+	return p.client
+}
+
+// reconnect replaces the client which got the auth error, it's done once for concurrent calls
+func (p *postClient) reconnect(failed *rpcclient.Client) error {
+	p.m.Lock()
+	defer p.m.Unlock()
+	if p.client != failed {
+		return nil
+	}
+	select {
+	case <-p.quit:
+		return rpcclient.ErrClientShutdown
+	default:
+	}
+	client, err := p.connect()
+	if err != nil {
+		return err
+	}
+	log.Warn("reconnect: node rejected credentials, reconnected with the cookie reread")
+	p.client = client
+	failed.Shutdown()
+	return nil
+}
+
+// call runs the request and retries it once with the new client after an auth error
+func (p *postClient) call(request func(client *rpcclient.Client) error) error {
+	client := p.current()
+	err := request(client)
+	if err == nil {
+		return nil
+	}
+	if isAuthError(err) {
+		if reconnectErr := p.reconnect(client); reconnectErr != nil {
+			log.Errorf("call:reconnect: %s", reconnectErr.Error())
+			return err
+		}
+	}
+	// the request could be cut by the shutdown of the replaced client
+	if current := p.current(); current != client {
+		return request(current)
+	}
+	return err
+}
+
+// isAuthError tells the node answered 401, rpcclient reports the status of non json responses
+func isAuthError(err error) bool {
+	return strings.HasPrefix(err.Error(), fmt.Sprintf("status code: %d,", http.StatusUnauthorized))
+}
+
+func (p *postClient) Shutdown() {
+	p.m.Lock()
+	defer p.m.Unlock()
+	select {
+	case <-p.quit:
+	default:
+		close(p.quit)
+	}
+	p.client.Shutdown()
+}
+
+// WaitForShutdown returns after Shutdown, not after a reconnect
+func (p *postClient) WaitForShutdown() {
+	<-p.quit
+	p.current().WaitForShutdown()
+}
+
+func (p *postClient) GetBlockCount() (count int64, err error) {
+	err = p.call(func(client *rpcclient.Client) (err error) {
+		count, err = client.GetBlockCount()
+		return err
+	})
+	return count, err
+}
+
+func (p *postClient) GetBlockHash(height int64) (hash *chainhash.Hash, err error) {
+	err = p.call(func(client *rpcclient.Client) (err error) {
+		hash, err = client.GetBlockHash(height)
+		return err
+	})
+	return hash, err
+}
+
+func (p *postClient) GetBlockHeader(hash *chainhash.Hash) (header *wire.BlockHeader, err error) {
+	err = p.call(func(client *rpcclient.Client) (err error) {
+		header, err = client.GetBlockHeader(hash)
+		return err
+	})
+	return header, err
+}
+
+func (p *postClient) GetBlock(hash *chainhash.Hash) (block *wire.MsgBlock, err error) {
+	err = p.call(func(client *rpcclient.Client) (err error) {
+		block, err = client.GetBlock(hash)
+		return err
+	})
+	return block, err
+}
+
+func (p *postClient) GetBlockVerbose(hash *chainhash.Hash) (block *btcjson.GetBlockVerboseResult, err error) {
+	err = p.call(func(client *rpcclient.Client) (err error) {
+		block, err = client.GetBlockVerbose(hash)
+		return err
+	})
+	return block, err
+}
+
+func (p *postClient) GetTxOut(hash *chainhash.Hash, index uint32, mempool bool) (out *btcjson.GetTxOutResult, err error) {
+	err = p.call(func(client *rpcclient.Client) (err error) {
+		out, err = client.GetTxOut(hash, index, mempool)
+		return err
+	})
+	return out, err
+}
+
+func (p *postClient) GetRawMempool() (txids []*chainhash.Hash, err error) {
+	err = p.call(func(client *rpcclient.Client) (err error) {
+		txids, err = client.GetRawMempool()
+		return err
+	})
+	return txids, err
+}
+
+func (p *postClient) RawRequest(method string, params []json.RawMessage) (raw json.RawMessage, err error) {
+	err = p.call(func(client *rpcclient.Client) (err error) {
+		raw, err = client.RawRequest(method, params)
+		return err
+	})
+	return raw, err
+}
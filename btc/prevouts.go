@@ -0,0 +1,198 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package btc
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+)
+
+// DefaultPrevoutCacheSize is the number of outputs kept when the size is not configured
+const DefaultPrevoutCacheSize = 500000
+
+// prevout is an output spent by a transaction input
+type prevout struct {
+	TxOut
+	// Mined is true if the transaction of the output is in a block
+	Mined bool
+}
+
+// PrevoutStats shows how previous outputs were found
+type PrevoutStats struct {
+	Size      int
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+type prevoutEntry struct {
+	key    string
+	output prevout
+}
+
+/*
+prevoutCache keeps outputs of processed transactions, so inputs of following
+transactions are resolved without asking the node. Outputs of blocks and
+mempool transactions are added when they are processed. On a miss the whole
+previous transaction is fetched once and all its outputs are added, concurrent
+lookups of the same transaction wait for that fetch. Least recently used
+outputs are evicted when the cache is full.
+*/
+type prevoutCache struct {
+	size int
+
+	m       sync.Mutex
+	outputs map[string]*list.Element
+	order   *list.List
+	pending map[string]chan struct{}
+	stats   PrevoutStats
+}
+
+func newPrevoutCache(size int) *prevoutCache {
+	if size <= 0 {
+		size = DefaultPrevoutCacheSize
+	}
+	return &prevoutCache{
+		size:    size,
+		outputs: map[string]*list.Element{},
+		order:   list.New(),
+		pending: map[string]chan struct{}{},
+	}
+}
+
+func outpointKey(txid string, index uint32) string {
+	return fmt.Sprintf("%s:%d", txid, index)
+}
+
+// get returns the cached output, lock must be held
+func (pc *prevoutCache) get(key string) (prevout, bool) {
+	element, ok := pc.outputs[key]
+	if !ok {
+		return prevout{}, false
+	}
+	pc.order.MoveToFront(element)
+	return element.Value.(*prevoutEntry).output, true
+}
+
+// peek returns the cached output without changing its order and statistics
+func (pc *prevoutCache) peek(key string) (prevout, bool) {
+	pc.m.Lock()
+	defer pc.m.Unlock()
+	element, ok := pc.outputs[key]
+	if !ok {
+		return prevout{}, false
+	}
+	return element.Value.(*prevoutEntry).output, true
+}
+
+// put adds or replaces the output, lock must be held
+func (pc *prevoutCache) put(key string, output prevout) {
+	if element, ok := pc.outputs[key]; ok {
+		element.Value.(*prevoutEntry).output = output
+		pc.order.MoveToFront(element)
+		return
+	}
+	pc.outputs[key] = pc.order.PushFront(&prevoutEntry{key: key, output: output})
+	for pc.order.Len() > pc.size {
+		oldest := pc.order.Back()
+		pc.order.Remove(oldest)
+		delete(pc.outputs, oldest.Value.(*prevoutEntry).key)
+		pc.stats.Evictions++
+	}
+}
+
+// add puts all outputs of the transaction to the cache
+func (pc *prevoutCache) add(txid string, outputs []TxOut, mined bool) {
+	pc.m.Lock()
+	defer pc.m.Unlock()
+	for _, output := range outputs {
+		pc.put(outpointKey(txid, output.N), prevout{
+			TxOut: output,
+			Mined: mined,
+		})
+	}
+}
+
+// lookup returns the output, fetch is called once per transaction on a miss
+func (pc *prevoutCache) lookup(txid string, index uint32, fetch func() (*Tx, error)) (prevout, error) {
+	key := outpointKey(txid, index)
+
+	pc.m.Lock()
+	if output, ok := pc.get(key); ok {
+		pc.stats.Hits++
+		pc.m.Unlock()
+		return output, nil
+	}
+	if wait, ok := pc.pending[txid]; ok {
+		pc.m.Unlock()
+		<-wait
+		pc.m.Lock()
+		output, ok := pc.get(key)
+		if ok {
+			pc.stats.Hits++
+		}
+		pc.m.Unlock()
+		if !ok {
+			return prevout{}, fmt.Errorf("output %s is not found", key)
+		}
+		return output, nil
+	}
+	pc.stats.Misses++
+	done := make(chan struct{})
+	pc.pending[txid] = done
+	pc.m.Unlock()
+
+	defer func() {
+		pc.m.Lock()
+		delete(pc.pending, txid)
+		pc.m.Unlock()
+		close(done)
+	}()
+
+	tx, err := fetch()
+	if err != nil {
+		return prevout{}, err
+	}
+	pc.add(tx.Txid, tx.Out, tx.BlockHash != "")
+
+	pc.m.Lock()
+	output, ok := pc.get(key)
+	pc.m.Unlock()
+	if !ok {
+		return prevout{}, fmt.Errorf("output %s is not found", key)
+	}
+	return output, nil
+}
+
+func (pc *prevoutCache) statistics() PrevoutStats {
+	pc.m.Lock()
+	defer pc.m.Unlock()
+	stats := pc.stats
+	stats.Size = pc.order.Len()
+	return stats
+}
+
+// prevout returns the output spent by an input, the node is asked only on a cache miss
+func (c *Client) prevout(txid string, index uint32) (prevout, error) {
+	return c.prevouts.lookup(txid, index, func() (*Tx, error) {
+		txVerbose, err := c.rawTxByTxid(txid)
+		if err != nil {
+			return nil, err
+		}
+		return newTx(txVerbose, c.params), nil
+	})
+}
+
+// cacheOutputs remembers outputs of the processed transaction for its spenders
+func (c *Client) cacheOutputs(tx *Tx, mined bool) {
+	c.prevouts.add(tx.Txid, tx.Out, mined)
+}
+
+// PrevoutStats returns hits and misses of previous outputs lookups
+func (c *Client) PrevoutStats() PrevoutStats {
+	return c.prevouts.statistics()
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package btc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/rpcclient"
+)
+
+// RPCConf configures connection to the node
+type RPCConf struct {
+	// Backend is btcd (default) or core
+	Backend string
+	// User and Pass are node rpc credentials
+	User string
+	Pass string
+	// CookieFile is a path to the bitcoind .cookie file used instead of User and Pass.
+	// It's read on every connect because bitcoind rewrites it on restart,
+	// core backend reconnects when the node rejects the old cookie.
+	CookieFile string
+	// DisableTLS is needed for bitcoind which doesn't provide TLS
+	DisableTLS bool
+	// HTTPPostMode disables btcd websocket notifications, the node is polled.
+	// Core backend always uses it.
+	HTTPPostMode bool
+	// ZMQBlock and ZMQTx are zmqpubrawblock and zmqpubrawtx addresses
+	// of Core backend, like tcp://127.0.0.1:28332
+	ZMQBlock string
+	ZMQTx    string
+	// PollInterval in seconds, 10 by default
+	PollInterval int
+	// Proxy is a socks5 proxy host:port, a proxy URL in HTTP POST mode.
+	// ProxyUser and ProxyPass authenticate on the proxy in both modes.
+	Proxy     string
+	ProxyUser string
+	ProxyPass string
+}
+
+// Validate checks that the node connection can be made with the configuration
+func (conf RPCConf) Validate(certificate []byte) error {
+	switch conf.Backend {
+	case "", BackendBtcd:
+		if conf.ZMQBlock != "" || conf.ZMQTx != "" {
+			return errors.New("ZMQ is supported by core backend only")
+		}
+	case BackendCore:
+	default:
+		return fmt.Errorf("unknown backend %q", conf.Backend)
+	}
+	for _, address := range []string{conf.ZMQBlock, conf.ZMQTx} {
+		if address == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(strings.TrimPrefix(address, "tcp://")); err != nil {
+			return fmt.Errorf("ZMQ address %q is not a tcp://host:port", address)
+		}
+	}
+	if conf.PollInterval < 0 {
+		return errors.New("negative poll interval")
+	}
+
+	switch {
+	case conf.CookieFile != "" && (conf.User != "" || conf.Pass != ""):
+		return errors.New("both credentials and cookie file are set")
+	case conf.CookieFile != "":
+		if _, _, err := readCookie(conf.CookieFile); err != nil {
+			return err
+		}
+	case conf.User == "" || conf.Pass == "":
+		return errors.New("credentials or cookie file are required")
+	}
+
+	if !conf.DisableTLS && len(certificate) == 0 {
+		return errors.New("certificate is required for TLS")
+	}
+
+	if conf.Proxy != "" {
+		if conf.httpPostMode() {
+			if proxyURL, err := url.Parse(conf.Proxy); err != nil || proxyURL.Host == "" {
+				return fmt.Errorf("proxy %q is not an URL", conf.Proxy)
+			}
+		} else if _, _, err := net.SplitHostPort(conf.Proxy); err != nil {
+			return fmt.Errorf("proxy %q is not a host:port", conf.Proxy)
+		}
+	}
+	if conf.ProxyUser != "" && conf.Proxy == "" {
+		return errors.New("proxy credentials are set without proxy")
+	}
+	if conf.ProxyPass != "" && conf.ProxyUser == "" {
+		return errors.New("proxy password is set without proxy user")
+	}
+	return nil
+}
+
+// connConfig makes rpcclient configuration, cookie is read here
+func (conf RPCConf) connConfig(host string, certificate []byte) (*rpcclient.ConnConfig, error) {
+	user, pass := conf.User, conf.Pass
+	if conf.CookieFile != "" {
+		var err error
+		if user, pass, err = readCookie(conf.CookieFile); err != nil {
+			return nil, err
+		}
+	}
+
+	endpoint := "ws"
+	if conf.httpPostMode() {
+		endpoint = ""
+	}
+
+	// rpcclient uses proxy credentials for socks5 only, the proxy URL carries them in HTTP POST mode
+	proxy := conf.Proxy
+	if conf.httpPostMode() && conf.ProxyUser != "" {
+		proxyURL, err := url.Parse(conf.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		proxyURL.User = url.UserPassword(conf.ProxyUser, conf.ProxyPass)
+		proxy = proxyURL.String()
+	}
+
+	return &rpcclient.ConnConfig{
+		Host:         host,
+		User:         user,
+		Pass:         pass,
+		Endpoint:     endpoint,
+		Certificates: certificate,
+		HTTPPostMode: conf.httpPostMode(),
+		DisableTLS:   conf.DisableTLS,
+		Proxy:        proxy,
+		ProxyUser:    conf.ProxyUser,
+		ProxyPass:    conf.ProxyPass,
+	}, nil
+}
+
+// httpPostMode reports if websocket is not used, Core has no websocket api
+func (conf RPCConf) httpPostMode() bool {
+	return conf.HTTPPostMode || conf.Backend == BackendCore
+}
+
+func (conf RPCConf) pollInterval() time.Duration {
+	if conf.PollInterval <= 0 {
+		return DefaultPollInterval
+	}
+	return time.Duration(conf.PollInterval) * time.Second
+}
+
+// readCookie reads user:password written by bitcoind
+func readCookie(path string) (string, string, error) {
+	cookie, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", "", fmt.Errorf("cookie file: %s", err.Error())
+	}
+	parts := strings.SplitN(string(bytes.TrimSpace(cookie)), ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("cookie file %s has wrong format", path)
+	}
+	return parts[0], parts[1], nil
+}
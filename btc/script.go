@@ -0,0 +1,173 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package btc
+
+import (
+	"crypto/sha256"
+	"regexp"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcutil"
+)
+
+// Script classes named as in verbose transactions of the node
+const (
+	ScriptPubKeyHash        = "pubkeyhash"
+	ScriptScriptHash        = "scripthash"
+	ScriptWitnessPubKeyHash = "witness_v0_keyhash"
+	ScriptWitnessScriptHash = "witness_v0_scripthash"
+	ScriptWitnessTaproot    = "witness_v1_taproot"
+	ScriptWitnessUnknown    = "witness_unknown"
+	ScriptPubKey            = "pubkey"
+	ScriptMultiSig          = "multisig"
+	ScriptNullData          = "nulldata"
+	ScriptNonStandard       = "nonstandard"
+)
+
+const (
+	op0             = 0x00
+	opData20        = 0x14
+	opData32        = 0x20
+	opData33        = 0x21
+	opData65        = 0x41
+	op1             = 0x51
+	op16            = 0x60
+	opReturn        = 0x6a
+	opDup           = 0x76
+	opEqual         = 0x87
+	opEqualVerify   = 0x88
+	opHash160       = 0xa9
+	opCheckSig      = 0xac
+	opCheckMultiSig = 0xae
+)
+
+/*
+scriptAddresses recognizes standard output scripts by their templates
+and returns the class, required signatures and addresses for the network.
+
+Pay to pubkey and bare multisig keys are shown as pay to pubkey hash
+addresses like the node does. Witness programs of version 1 and later
+are bech32m addresses. Unknown scripts are nonstandard without addresses.
+*/
+func scriptAddresses(script []byte, params *chaincfg.Params) (string, int32, []string) {
+	var (
+		class   = ScriptNonStandard
+		address btcutil.Address
+		err     error
+	)
+	switch {
+	case len(script) == 25 && script[0] == opDup && script[1] == opHash160 && script[2] == opData20 &&
+		script[23] == opEqualVerify && script[24] == opCheckSig:
+		class = ScriptPubKeyHash
+		address, err = btcutil.NewAddressPubKeyHash(script[3:23], params)
+	case len(script) == 23 && script[0] == opHash160 && script[1] == opData20 && script[22] == opEqual:
+		class = ScriptScriptHash
+		address, err = btcutil.NewAddressScriptHashFromHash(script[2:22], params)
+	case len(script) == 22 && script[0] == op0 && script[1] == opData20:
+		class = ScriptWitnessPubKeyHash
+		address, err = btcutil.NewAddressWitnessPubKeyHash(script[2:], params)
+	case len(script) == 34 && script[0] == op0 && script[1] == opData32:
+		class = ScriptWitnessScriptHash
+		address, err = btcutil.NewAddressWitnessScriptHash(script[2:], params)
+	case len(script) == 35 && script[0] == opData33 && script[34] == opCheckSig,
+		len(script) == 67 && script[0] == opData65 && script[66] == opCheckSig:
+		class = ScriptPubKey
+		address, err = btcutil.NewAddressPubKey(script[1:len(script)-1], params)
+	case len(script) > 0 && script[0] == opReturn:
+		return ScriptNullData, 0, nil
+	default:
+		if version, program, ok := witnessProgram(script); ok {
+			return witnessAddresses(version, program, params)
+		}
+		if reqSigs, pubKeys, ok := multiSig(script); ok {
+			addresses := []string{}
+			for _, pubKey := range pubKeys {
+				if address, err := btcutil.NewAddressPubKey(pubKey, params); err == nil {
+					addresses = append(addresses, address.EncodeAddress())
+				}
+			}
+			return ScriptMultiSig, reqSigs, addresses
+		}
+		return class, 0, nil
+	}
+	if err != nil {
+		// a pubkey which is not on the curve
+		return ScriptNonStandard, 0, nil
+	}
+	return class, 1, []string{address.EncodeAddress()}
+}
+
+// witnessProgram returns version and program of OP_n <2 to 40 bytes> script
+func witnessProgram(script []byte) (byte, []byte, bool) {
+	if len(script) < 4 || len(script) > 42 || int(script[1]) != len(script)-2 {
+		return 0, nil, false
+	}
+	switch {
+	case script[0] == op0:
+		return 0, script[2:], true
+	case script[0] >= op1 && script[0] <= op16:
+		return script[0] - op1 + 1, script[2:], true
+	}
+	return 0, nil, false
+}
+
+// witnessAddresses encodes witness programs which btcutil doesn't know
+func witnessAddresses(version byte, program []byte, params *chaincfg.Params) (string, int32, []string) {
+	if version == 0 {
+		// only 20 and 32 bytes programs are valid for version 0
+		return ScriptNonStandard, 0, nil
+	}
+	class := ScriptWitnessUnknown
+	if version == 1 && len(program) == 32 {
+		class = ScriptWitnessTaproot
+	}
+	address, err := encodeSegwitAddress(params.Bech32HRPSegwit, version, program)
+	if err != nil {
+		return ScriptNonStandard, 0, nil
+	}
+	return class, 1, []string{address}
+}
+
+// multiSig parses OP_m <pubkey>... OP_n OP_CHECKMULTISIG
+func multiSig(script []byte) (int32, [][]byte, bool) {
+	if len(script) < 3 || script[len(script)-1] != opCheckMultiSig {
+		return 0, nil, false
+	}
+	m, n := script[0], script[len(script)-2]
+	if m < op1 || m > op16 || n < op1 || n > op16 || m > n {
+		return 0, nil, false
+	}
+	pubKeys := [][]byte{}
+	for i := 1; i < len(script)-2; {
+		size := int(script[i])
+		if (size != 33 && size != 65) || i+1+size > len(script)-2 {
+			return 0, nil, false
+		}
+		pubKeys = append(pubKeys, script[i+1:i+1+size])
+		i += 1 + size
+	}
+	if len(pubKeys) != int(n-op1+1) {
+		return 0, nil, false
+	}
+	return int32(m - op1 + 1), pubKeys, true
+}
+
+/*
+ScriptHash is the canonical hash of an output script: sha256 of the script
+in reversed byte order like Electrum protocol uses it. Any output script,
+also one without an address, can be watched by its script hash.
+*/
+func ScriptHash(script []byte) string {
+	return chainhash.Hash(sha256.Sum256(script)).String()
+}
+
+var scriptHashPattern = regexp.MustCompile("^[0-9a-f]{64}$")
+
+// IsScriptHash reports if the watched value is a script hash and not an address
+func IsScriptHash(value string) bool {
+	return scriptHashPattern.MatchString(value)
+}
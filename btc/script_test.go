@@ -0,0 +1,159 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package btc
+
+import (
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+const (
+	// pubKey1 and pubKey2 are keys of private keys 1 and 2
+	pubKey1             = "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	pubKey1Uncompressed = "0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8"
+	pubKey2             = "02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5"
+	// keyHash1 is hash160 of pubKey1
+	keyHash1 = "751e76e8199196d454941c45d1b3a323f1433bd6"
+
+	address1             = "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"
+	address1Uncompressed = "1EHNa6Q4Jz2uvNExL497mE43ikXhwF6kZm"
+	address2             = "1cMh228HTCiwS8ZsaakH8A8wze1JR5ZsP"
+)
+
+func TestScriptAddresses(t *testing.T) {
+	tests := []struct {
+		name          string
+		script        string
+		params        *chaincfg.Params
+		wantClass     string
+		wantReqSigs   int32
+		wantAddresses []string
+	}{
+		{
+			name:          "p2pk compressed",
+			script:        "21" + pubKey1 + "ac",
+			wantClass:     ScriptPubKey,
+			wantReqSigs:   1,
+			wantAddresses: []string{address1},
+		},
+		{
+			name:          "p2pk uncompressed",
+			script:        "41" + pubKey1Uncompressed + "ac",
+			wantClass:     ScriptPubKey,
+			wantReqSigs:   1,
+			wantAddresses: []string{address1Uncompressed},
+		},
+		{
+			name:      "p2pk off the curve",
+			script:    "21" + "02" + "0000000000000000000000000000000000000000000000000000000000000005" + "ac",
+			wantClass: ScriptNonStandard,
+		},
+		{
+			name:          "p2pkh",
+			script:        "76a914" + keyHash1 + "88ac",
+			wantClass:     ScriptPubKeyHash,
+			wantReqSigs:   1,
+			wantAddresses: []string{address1},
+		},
+		{
+			name:          "p2sh",
+			script:        "a914" + "748284390f9e263a4b766a75d0633c50426eb875" + "87",
+			wantClass:     ScriptScriptHash,
+			wantReqSigs:   1,
+			wantAddresses: []string{"3CK4fEwbMP7heJarmU4eqA3sMbVJyEnU3V"},
+		},
+		{
+			name:          "p2wpkh",
+			script:        "0014" + keyHash1,
+			wantClass:     ScriptWitnessPubKeyHash,
+			wantReqSigs:   1,
+			wantAddresses: []string{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"},
+		},
+		{
+			name:          "p2wsh testnet",
+			script:        "00201863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262",
+			params:        &chaincfg.TestNet3Params,
+			wantClass:     ScriptWitnessScriptHash,
+			wantReqSigs:   1,
+			wantAddresses: []string{"tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7"},
+		},
+		{
+			name:          "p2tr",
+			script:        "5120" + pubKey1[2:],
+			wantClass:     ScriptWitnessTaproot,
+			wantReqSigs:   1,
+			wantAddresses: []string{"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0"},
+		},
+		{
+			name:          "future witness version",
+			script:        "5210751e76e8199196d454941c45d1b3a323",
+			wantClass:     ScriptWitnessUnknown,
+			wantReqSigs:   1,
+			wantAddresses: []string{"bc1zw508d6qejxtdg4y5r3zarvaryvaxxpcs"},
+		},
+		{
+			name:      "witness v0 of wrong length",
+			script:    "0010751e76e8199196d454941c45d1b3a323",
+			wantClass: ScriptNonStandard,
+		},
+		{
+			name:          "bare multisig 1 of 2",
+			script:        "51" + "21" + pubKey1 + "21" + pubKey2 + "52" + "ae",
+			wantClass:     ScriptMultiSig,
+			wantReqSigs:   1,
+			wantAddresses: []string{address1, address2},
+		},
+		{
+			name:          "bare multisig 2 of 2",
+			script:        "52" + "21" + pubKey1 + "21" + pubKey2 + "52" + "ae",
+			wantClass:     ScriptMultiSig,
+			wantReqSigs:   2,
+			wantAddresses: []string{address1, address2},
+		},
+		{
+			name:      "multisig with fewer keys than declared",
+			script:    "51" + "21" + pubKey1 + "52" + "ae",
+			wantClass: ScriptNonStandard,
+		},
+		{
+			name:      "op_return",
+			script:    "6a04deadbeef",
+			wantClass: ScriptNullData,
+		},
+		{
+			name:      "empty",
+			script:    "",
+			wantClass: ScriptNonStandard,
+		},
+		{
+			name:      "op_true",
+			script:    "51",
+			wantClass: ScriptNonStandard,
+		},
+		{
+			name:      "p2pkh without checksig",
+			script:    "76a914" + keyHash1 + "88",
+			wantClass: ScriptNonStandard,
+		},
+	}
+	for _, test := range tests {
+		params := test.params
+		if params == nil {
+			params = &chaincfg.MainNetParams
+		}
+		script, err := hex.DecodeString(test.script)
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err.Error())
+		}
+		class, reqSigs, addresses := scriptAddresses(script, params)
+		if class != test.wantClass || reqSigs != test.wantReqSigs || !reflect.DeepEqual(addresses, test.wantAddresses) {
+			t.Errorf("%s: %s %d %v, want %s %d %v", test.name, class, reqSigs, addresses, test.wantClass, test.wantReqSigs, test.wantAddresses)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package btc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcutil/bech32"
+)
+
+/*
+Segwit addresses of witness version 0 are bech32 (BIP 173), addresses of
+version 1 and later like taproot are bech32m (BIP 350). Vendored bech32
+knows only the first checksum, so both are encoded and decoded here.
+*/
+
+const (
+	bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+	bech32Const  = 1
+	bech32mConst = 0x2bc830a3
+)
+
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func bech32HrpExpand(hrp string) []byte {
+	expanded := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]>>5)
+	}
+	expanded = append(expanded, 0)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]&31)
+	}
+	return expanded
+}
+
+func segwitConst(version byte) uint32 {
+	if version == 0 {
+		return bech32Const
+	}
+	return bech32mConst
+}
+
+// encodeSegwitAddress encodes the witness program with bech32 or bech32m by its version
+func encodeSegwitAddress(hrp string, version byte, program []byte) (string, error) {
+	converted, err := bech32.ConvertBits(program, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	data := append([]byte{version}, converted...)
+
+	values := append(bech32HrpExpand(hrp), data...)
+	values = append(values, 0, 0, 0, 0, 0, 0)
+	polymod := bech32Polymod(values) ^ segwitConst(version)
+
+	address := append([]byte(hrp), '1')
+	for _, v := range data {
+		address = append(address, bech32Charset[v])
+	}
+	for i := 0; i < 6; i++ {
+		address = append(address, bech32Charset[(polymod>>uint(5*(5-i)))&31])
+	}
+	return string(address), nil
+}
+
+// decodeSegwitAddress returns prefix, witness version and program of the address
+func decodeSegwitAddress(address string) (string, byte, []byte, error) {
+	if len(address) < 8 || len(address) > 90 {
+		return "", 0, nil, fmt.Errorf("wrong length %d", len(address))
+	}
+	lower := strings.ToLower(address)
+	if lower != address && strings.ToUpper(address) != address {
+		return "", 0, nil, errors.New("mixed case")
+	}
+	separator := strings.LastIndexByte(lower, '1')
+	if separator < 1 || separator+7 > len(lower) {
+		return "", 0, nil, errors.New("wrong separator position")
+	}
+	hrp := lower[:separator]
+
+	data := make([]byte, 0, len(lower)-separator-1)
+	for _, char := range lower[separator+1:] {
+		v := strings.IndexRune(bech32Charset, char)
+		if v < 0 {
+			return "", 0, nil, fmt.Errorf("wrong character %q", char)
+		}
+		data = append(data, byte(v))
+	}
+	if len(data) < 7 {
+		return "", 0, nil, errors.New("no witness program")
+	}
+	version := data[0]
+	if version > 16 {
+		return "", 0, nil, fmt.Errorf("wrong witness version %d", version)
+	}
+	if bech32Polymod(append(bech32HrpExpand(hrp), data...)) != segwitConst(version) {
+		return "", 0, nil, errors.New("wrong checksum")
+	}
+
+	program, err := bech32.ConvertBits(data[1:len(data)-6], 5, 8, false)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	if len(program) < 2 || len(program) > 40 {
+		return "", 0, nil, fmt.Errorf("wrong witness program length %d", len(program))
+	}
+	if version == 0 && len(program) != 20 && len(program) != 32 {
+		return "", 0, nil, fmt.Errorf("wrong witness v0 program length %d", len(program))
+	}
+	return hrp, version, program, nil
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package btc
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// valid segwit addresses of BIP 173 and BIP 350 with their output scripts
+var validSegwitAddresses = []struct {
+	address string
+	hrp     string
+	script  string
+}{
+	{"BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", "bc", "0014751e76e8199196d454941c45d1b3a323f1433bd6"},
+	{"tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7", "tb", "00201863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262"},
+	{"bc1pw508d6qejxtdg4y5r3zarvary0c5xw7kw508d6qejxtdg4y5r3zarvary0c5xw7kt5nd6y", "bc", "5128751e76e8199196d454941c45d1b3a323f1433bd6751e76e8199196d454941c45d1b3a323f1433bd6"},
+	{"BC1SW50QGDZ25J", "bc", "6002751e"},
+	{"bc1zw508d6qejxtdg4y5r3zarvaryvaxxpcs", "bc", "5210751e76e8199196d454941c45d1b3a323"},
+	{"tb1qqqqqp399et2xygdj5xreqhjjvcmzhxw4aywxecjdzew6hylgvsesrxh6hy", "tb", "0020000000c4a5cad46221b2a187905e5266362b99d5e91c6ce24d165dab93e86433"},
+	{"tb1pqqqqp399et2xygdj5xreqhjjvcmzhxw4aywxecjdzew6hylgvsesf3hn0c", "tb", "5120000000c4a5cad46221b2a187905e5266362b99d5e91c6ce24d165dab93e86433"},
+	{"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", "bc", "512079be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"},
+}
+
+func TestDecodeSegwitAddress(t *testing.T) {
+	for _, test := range validSegwitAddresses {
+		hrp, version, program, err := decodeSegwitAddress(test.address)
+		if err != nil {
+			t.Errorf("%s: %s", test.address, err.Error())
+			continue
+		}
+		script, _ := hex.DecodeString(test.script)
+		wantVersion, wantProgram, ok := witnessProgram(script)
+		if !ok {
+			t.Fatalf("%s: script %s is not a witness program", test.address, test.script)
+		}
+		if hrp != test.hrp || version != wantVersion || hex.EncodeToString(program) != hex.EncodeToString(wantProgram) {
+			t.Errorf("%s: decoded %s %d %x, want %s %d %x", test.address, hrp, version, program, test.hrp, wantVersion, wantProgram)
+		}
+	}
+}
+
+func TestEncodeSegwitAddress(t *testing.T) {
+	for _, test := range validSegwitAddresses {
+		script, _ := hex.DecodeString(test.script)
+		version, program, _ := witnessProgram(script)
+		address, err := encodeSegwitAddress(test.hrp, version, program)
+		if err != nil {
+			t.Errorf("%s: %s", test.address, err.Error())
+			continue
+		}
+		if address != strings.ToLower(test.address) {
+			t.Errorf("encoded %s, want %s", address, strings.ToLower(test.address))
+		}
+	}
+}
+
+// TestDecodeInvalidSegwitAddress checks invalid addresses of BIP 173 and BIP 350,
+// prefixes are checked by ValidateAddress so the ones invalid only by prefix aren't here
+func TestDecodeInvalidSegwitAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		reason  string
+	}{
+		// BIP 173
+		{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5", "invalid checksum"},
+		{"BC13W508D6QEJXTDG4Y5R3ZARVARY0C5XW7KN40WF2", "invalid witness version"},
+		{"bc1rw5uspcuh", "invalid program length"},
+		{"bc10w508d6qejxtdg4y5r3zarvary0c5xw7kw508d6qejxtdg4y5r3zarvary0c5xw7kw5rljs90", "invalid program length"},
+		{"BC1QR508D6QEJXTDG4Y5R3ZARVARYV98GJ9P", "invalid program length for witness version 0"},
+		{"tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sL5k7", "mixed case"},
+		{"bc1zw508d6qejxtdg4y5r3zarvaryvqyzf3du", "zero padding of more than 4 bits"},
+		{"tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3pjxtptv", "non-zero padding in 8-to-5 conversion"},
+		{"bc1gmk9yu", "empty data section"},
+		{"pzry9x0s0muk", "no separator character"},
+		{"1pzry9x0s0muk", "empty hrp"},
+		{"x1b4n0q5v", "invalid data character"},
+		{"li1dgmt3", "too short checksum"},
+		{"an84characterslonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1569pvx", "overall max length exceeded"},
+		// BIP 350
+		{"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqh2y7hd", "bech32 instead of bech32m"},
+		{"tb1z0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqglt7rf", "bech32 instead of bech32m"},
+		{"BC1S0XLXVLHEMJA6C4DQV22UAPCTQUPFHLXM9H8Z3K2E72Q4K9HCZ7VQ54WELL", "bech32 instead of bech32m"},
+		{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kemeawh", "bech32m instead of bech32"},
+		{"tb1q0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq24jc47", "bech32m instead of bech32"},
+		{"bc1p38j9r5y49hruaue7wxjce0updqjuyyx0kh56v8s25huc6995vvpql3jow4", "invalid character in checksum"},
+		{"BC130XLXVLHEMJA6C4DQV22UAPCTQUPFHLXM9H8Z3K2E72Q4K9HCZ7VQ7ZWS8R", "invalid witness version"},
+		{"bc1pw5dgrnzv", "invalid program length"},
+		{"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7v8n0nx0muaewav253zgeav", "invalid program length"},
+		{"tb1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq47Zagq", "mixed case"},
+		{"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7v07qwwzcrf", "zero padding of more than 4 bits"},
+		{"tb1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vpggkg4j", "non-zero padding in 8-to-5 conversion"},
+	}
+	for _, test := range tests {
+		if hrp, version, program, err := decodeSegwitAddress(test.address); err == nil {
+			t.Errorf("%s (%s) is decoded to %s %d %x", test.address, test.reason, hrp, version, program)
+		}
+	}
+}
+
+func TestValidateSegwitAddressPrefix(t *testing.T) {
+	mainnet, _ := NetworkParams("mainnet")
+	tests := []struct {
+		address string
+		valid   bool
+	}{
+		{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", true},
+		{"BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", true},
+		{"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", true},
+		// BIP 173 and BIP 350 invalid human-readable parts
+		{"tc1qw508d6qejxtdg4y5r3zarvary0c5xw7kg3g4ty", false},
+		{"tc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq5zuyut", false},
+		// valid testnet address on mainnet
+		{"tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7", false},
+	}
+	for _, test := range tests {
+		err := ValidateAddress(test.address, mainnet)
+		if (err == nil) != test.valid {
+			t.Errorf("%s: valid %v, error %v", test.address, test.valid, err)
+		}
+	}
+}
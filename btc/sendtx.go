@@ -0,0 +1,275 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package btc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+)
+
+const (
+	// absurdFeeRate in sat/vB is the default maxfeerate of Core
+	absurdFeeRate = 10000
+	// dustRelayFeeRate in sat/vB makes outputs cheaper than their spending dust
+	dustRelayFeeRate = 3
+	// lockTimeThreshold separates block heights from timestamps in locktime
+	lockTimeThreshold = 500000000
+	// medianTimeBlocks is the number of blocks of the median time past
+	medianTimeBlocks = 11
+)
+
+// Reasons of rejected raw transactions
+const (
+	RejectParse        = "parse"
+	RejectMissingInput = "missing-input"
+	RejectSpentInput   = "spent-input"
+	RejectFeeTooLow    = "fee-too-low"
+	RejectAbsurdFee    = "absurd-fee"
+	RejectDust         = "dust"
+	RejectNonFinal     = "non-final"
+	// RejectNode is a rejection by the node on broadcast
+	RejectNode = "node"
+)
+
+// TxProblem is a reason the raw transaction can't be sent
+type TxProblem struct {
+	Reason string
+	// Index of the input or output, -1 for the whole transaction
+	Index   int
+	Message string
+}
+
+// TxCheck is the result of raw transaction validation
+type TxCheck struct {
+	Txid  string
+	Vsize int32
+	// Fee and FeeRate are zero if some inputs are not found
+	Fee      btcutil.Amount
+	FeeRate  float64
+	Problems []TxProblem
+	// Sent is true if the transaction is broadcasted
+	Sent bool
+}
+
+func (check *TxCheck) problem(reason string, index int, format string, args ...interface{}) {
+	check.Problems = append(check.Problems, TxProblem{
+		Reason:  reason,
+		Index:   index,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+/*
+SendRawTx validates the hex encoded transaction and broadcasts it if it's valid.
+With dryRun the transaction is only validated. A validation failure is not an
+error, problems are returned with the check; an error is returned only if the
+node can't be asked.
+*/
+func (c *Client) SendRawTx(txHex string, dryRun bool) (TxCheck, error) {
+	check, err := c.CheckRawTx(txHex)
+	if err != nil || dryRun || len(check.Problems) > 0 {
+		return check, err
+	}
+
+	// fee rates are checked above, so the node's limit is not needed
+	hash, err := c.RPCClient.SendRawTransaction(txHex, true)
+	if err != nil {
+		log.Errorf("SendRawTx:RPCClient.SendRawTransaction: %s", err.Error())
+		check.problem(RejectNode, -1, "%s", err.Error())
+		return check, nil
+	}
+	check.Txid = hash.String()
+	check.Sent = true
+	return check, nil
+}
+
+/*
+CheckRawTx checks what the node would reject the transaction for: it's decoded,
+spent outputs are looked up with gettxout including mempool, the fee rate is
+compared with the relay minimum and the absurd rate, outputs are checked to be
+worth spending and locktime is checked to be final for the next block.
+*/
+func (c *Client) CheckRawTx(txHex string) (TxCheck, error) {
+	check := TxCheck{}
+
+	serialized, err := hex.DecodeString(txHex)
+	if err != nil {
+		check.problem(RejectParse, -1, "not hex: %s", err.Error())
+		return check, nil
+	}
+	msgTx := wire.MsgTx{}
+	if err := msgTx.Deserialize(bytes.NewReader(serialized)); err != nil {
+		check.problem(RejectParse, -1, "%s", err.Error())
+		return check, nil
+	}
+	if len(msgTx.TxIn) == 0 || len(msgTx.TxOut) == 0 {
+		check.problem(RejectParse, -1, "transaction has %d inputs and %d outputs", len(msgTx.TxIn), len(msgTx.TxOut))
+		return check, nil
+	}
+	if isCoinBase(&msgTx) {
+		check.problem(RejectParse, -1, "coinbase transaction")
+		return check, nil
+	}
+	check.Txid = msgTx.TxHash().String()
+	_, check.Vsize, _ = txSizes(&msgTx)
+
+	inputs, found, err := c.inputsValue(&msgTx, &check)
+	if err != nil {
+		return check, err
+	}
+
+	var outputs btcutil.Amount
+	for i, txOut := range msgTx.TxOut {
+		outputs += btcutil.Amount(txOut.Value)
+		if isDust(txOut) {
+			check.problem(RejectDust, i, "output of %d sat is less than %d sat it costs to spend", txOut.Value, dustThreshold(txOut))
+		}
+	}
+
+	if found {
+		check.Fee = inputs - outputs
+		check.FeeRate = feeRate(check.Fee, check.Vsize)
+		switch {
+		case check.Fee < 0:
+			check.problem(RejectFeeTooLow, -1, "outputs of %d sat exceed inputs of %d sat", outputs, inputs)
+		case check.FeeRate < minRelayFeeRate:
+			check.problem(RejectFeeTooLow, -1, "fee rate %.2f sat/vB is below %d sat/vB", check.FeeRate, minRelayFeeRate)
+		case check.FeeRate > absurdFeeRate:
+			check.problem(RejectAbsurdFee, -1, "fee rate %.2f sat/vB is above %d sat/vB", check.FeeRate, absurdFeeRate)
+		}
+	}
+
+	if err := c.checkFinal(&msgTx, &check); err != nil {
+		return check, err
+	}
+	return check, nil
+}
+
+// inputsValue sums outputs spent by the transaction, false is returned if some of them are not found
+func (c *Client) inputsValue(msgTx *wire.MsgTx, check *TxCheck) (btcutil.Amount, bool, error) {
+	var sum btcutil.Amount
+	found := true
+	for i, txIn := range msgTx.TxIn {
+		outpoint := txIn.PreviousOutPoint
+		txOut, err := c.RPCClient.GetTxOut(&outpoint.Hash, outpoint.Index, true)
+		if err != nil {
+			return 0, false, err
+		}
+		if txOut == nil {
+			found = false
+			// gettxout doesn't tell spent outputs from missing ones
+			if spender, ok := c.mempoolSpender(outpoint.Hash.String(), outpoint.Index); ok {
+				check.problem(RejectSpentInput, i, "%s is spent by mempool transaction %s", outpoint.String(), spender)
+			} else if _, err := c.RPCClient.GetRawTransactionVerbose(&outpoint.Hash); err == nil {
+				check.problem(RejectSpentInput, i, "%s is spent", outpoint.String())
+			} else {
+				check.problem(RejectMissingInput, i, "%s is not found", outpoint.String())
+			}
+			continue
+		}
+		value, err := btcutil.NewAmount(txOut.Value)
+		if err != nil {
+			return 0, false, err
+		}
+		sum += value
+	}
+	return sum, found, nil
+}
+
+// mempoolSpender returns the tracked mempool transaction spending the output
+func (c *Client) mempoolSpender(txid string, index uint32) (string, bool) {
+	c.mempoolSpends.m.Lock()
+	defer c.mempoolSpends.m.Unlock()
+	spender, ok := c.mempoolSpends.spenders[outpointKey(txid, index)]
+	return spender, ok
+}
+
+/*
+dustThreshold is the fee to spend the output at the dust relay rate like Core
+calculates it: the output itself and a typical input spending it, witness
+inputs are discounted.
+*/
+func dustThreshold(txOut *wire.TxOut) int64 {
+	size := int64(txOut.SerializeSize())
+	if _, _, ok := witnessProgram(txOut.PkScript); ok {
+		// outpoint, script length, sequence and a quarter of the witness
+		size += 32 + 4 + 1 + 107/witnessScaleFactor + 4
+	} else {
+		// outpoint, script length, signature script and sequence
+		size += 32 + 4 + 1 + 107 + 4
+	}
+	return size * dustRelayFeeRate
+}
+
+// isDust reports if the output costs more to spend than it's worth, OP_RETURN outputs are never spent
+func isDust(txOut *wire.TxOut) bool {
+	if len(txOut.PkScript) > 0 && txOut.PkScript[0] == opReturn {
+		return false
+	}
+	return txOut.Value < dustThreshold(txOut)
+}
+
+// checkFinal checks the locktime of the transaction allows it in the next block
+func (c *Client) checkFinal(msgTx *wire.MsgTx, check *TxCheck) error {
+	if msgTx.LockTime == 0 {
+		return nil
+	}
+	final := true
+	for _, txIn := range msgTx.TxIn {
+		if txIn.Sequence != wire.MaxTxInSequenceNum {
+			final = false
+		}
+	}
+	if final {
+		// locktime is ignored if all inputs are final
+		return nil
+	}
+
+	bestHash, bestHeight, err := c.RPCClient.GetBestBlock()
+	if err != nil {
+		return err
+	}
+	if msgTx.LockTime < lockTimeThreshold {
+		if int64(msgTx.LockTime) >= int64(bestHeight)+1 {
+			check.problem(RejectNonFinal, -1, "locktime is block %d, next block is %d", msgTx.LockTime, bestHeight+1)
+		}
+		return nil
+	}
+	medianTime, err := c.medianTimePast(bestHash)
+	if err != nil {
+		return err
+	}
+	if int64(msgTx.LockTime) >= medianTime {
+		check.problem(RejectNonFinal, -1, "locktime is time %d, median time past is %d", msgTx.LockTime, medianTime)
+	}
+	return nil
+}
+
+// medianTimePast is the median timestamp of the last blocks ending with hash
+func (c *Client) medianTimePast(hash *chainhash.Hash) (int64, error) {
+	times := []int64{}
+	for i := 0; i < medianTimeBlocks; i++ {
+		header, err := c.RPCClient.GetBlockHeader(hash)
+		if err != nil {
+			return 0, err
+		}
+		times = append(times, header.Timestamp.Unix())
+		if header.PrevBlock == (chainhash.Hash{}) {
+			break
+		}
+		hash = &header.PrevBlock
+	}
+	sort.Slice(times, func(i, j int) bool {
+		return times[i] < times[j]
+	})
+	return times[len(times)/2], nil
+}
@@ -0,0 +1,305 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package btc
+
+import (
+	"bytes"
+	"encoding/hex"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+)
+
+// witnessScaleFactor is the weight of a non-witness byte
+const witnessScaleFactor = 4
+
+// Tx is a transaction with amounts in satoshi and resolved previous outputs
+type Tx struct {
+	Txid string
+	Hash string
+	Size int32
+	// Vsize is the virtual size in vbytes, weight is in weight units
+	Vsize    int32
+	Weight   int32
+	LockTime uint32
+	// BlockHash is empty for mempool transactions
+	BlockHash string
+	Time      int64
+	Blocktime int64
+	In        []TxIn
+	Out       []TxOut
+}
+
+// TxIn is a transaction input
+type TxIn struct {
+	// Txid and Vout point to the spent output
+	Txid     string
+	Vout     uint32
+	Sequence uint32
+	Coinbase bool
+	// Prevout is the spent output, nil if it's not found
+	Prevout *TxOut
+	// PrevoutMined is true if the spent output is in a block
+	PrevoutMined bool
+}
+
+// TxOut is a transaction output
+type TxOut struct {
+	N         uint32
+	Value     btcutil.Amount
+	Script    string
+	Type      string
+	Addresses []string
+	// ReqSigs is a number of signatures needed to spend, it's more than one for shared multisig
+	ReqSigs int32
+	// ScriptHash identifies the script, it's set for outputs without addresses too
+	ScriptHash string
+}
+
+// InputsValue is the sum of spent outputs, false is returned if some of them are not resolved
+func (tx *Tx) InputsValue() (btcutil.Amount, bool) {
+	var sum btcutil.Amount
+	for _, in := range tx.In {
+		if in.Coinbase {
+			continue
+		}
+		if in.Prevout == nil {
+			return 0, false
+		}
+		sum += in.Prevout.Value
+	}
+	return sum, true
+}
+
+// OutputsValue is the sum of transaction outputs
+func (tx *Tx) OutputsValue() btcutil.Amount {
+	var sum btcutil.Amount
+	for _, out := range tx.Out {
+		sum += out.Value
+	}
+	return sum
+}
+
+// Fee is zero for coinbase, false is returned if some spent outputs are not resolved
+func (tx *Tx) Fee() (btcutil.Amount, bool) {
+	if tx.IsCoinBase() {
+		return 0, true
+	}
+	inputs, ok := tx.InputsValue()
+	if !ok {
+		return 0, false
+	}
+	return inputs - tx.OutputsValue(), true
+}
+
+// FeeRate is the fee in sat/vB, zero if the fee is unknown
+func (tx *Tx) FeeRate() float64 {
+	fee, ok := tx.Fee()
+	if !ok {
+		return 0
+	}
+	return feeRate(fee, tx.Vsize)
+}
+
+func (tx *Tx) IsCoinBase() bool {
+	return len(tx.In) == 1 && tx.In[0].Coinbase
+}
+
+/*
+newTxOut converts the verbose output, amount is rounded to satoshi.
+Addresses are derived from the script, so outputs which the node shows
+without addresses (taproot on old nodes) are recognized too.
+*/
+func newTxOut(vout btcjson.Vout, params *chaincfg.Params) TxOut {
+	value, err := btcutil.NewAmount(vout.Value)
+	if err != nil {
+		log.Errorf("newTxOut:btcutil.NewAmount: %s", err.Error())
+	}
+	out := TxOut{
+		N:         vout.N,
+		Value:     value,
+		Script:    vout.ScriptPubKey.Hex,
+		Type:      vout.ScriptPubKey.Type,
+		Addresses: vout.ScriptPubKey.Addresses,
+		ReqSigs:   vout.ScriptPubKey.ReqSigs,
+	}
+	if script, err := hex.DecodeString(vout.ScriptPubKey.Hex); err == nil {
+		out.ScriptHash = ScriptHash(script)
+		if class, reqSigs, addresses := scriptAddresses(script, params); len(addresses) > 0 {
+			out.Type, out.ReqSigs, out.Addresses = class, reqSigs, addresses
+		}
+	}
+	return out
+}
+
+// feeRate is sat/vB
+func feeRate(fee btcutil.Amount, vsize int32) float64 {
+	if vsize <= 0 {
+		return 0
+	}
+	return float64(fee) / float64(vsize)
+}
+
+// txSizes returns serialized size, virtual size and weight of the transaction
+func txSizes(msgTx *wire.MsgTx) (int32, int32, int32) {
+	size := msgTx.SerializeSize()
+	weight := msgTx.SerializeSizeStripped()*(witnessScaleFactor-1) + size
+	vsize := (weight + witnessScaleFactor - 1) / witnessScaleFactor
+	return int32(size), int32(vsize), int32(weight)
+}
+
+/*
+newTx converts the verbose transaction of the node, previous outputs are not resolved.
+Sizes are calculated from the raw transaction, the node reports no weight and
+old nodes report no virtual size.
+*/
+func newTx(txVerbose *btcjson.TxRawResult, params *chaincfg.Params) *Tx {
+	tx := &Tx{
+		Txid:      txVerbose.Txid,
+		Hash:      txVerbose.Hash,
+		Size:      txVerbose.Size,
+		Vsize:     txVerbose.Vsize,
+		Weight:    txVerbose.Vsize * witnessScaleFactor,
+		LockTime:  txVerbose.LockTime,
+		BlockHash: txVerbose.BlockHash,
+		Time:      txVerbose.Time,
+		Blocktime: txVerbose.Blocktime,
+		In:        make([]TxIn, 0, len(txVerbose.Vin)),
+		Out:       make([]TxOut, 0, len(txVerbose.Vout)),
+	}
+	for _, vin := range txVerbose.Vin {
+		tx.In = append(tx.In, TxIn{
+			Txid:     vin.Txid,
+			Vout:     vin.Vout,
+			Sequence: vin.Sequence,
+			Coinbase: vin.IsCoinBase(),
+		})
+	}
+	for _, vout := range txVerbose.Vout {
+		tx.Out = append(tx.Out, newTxOut(vout, params))
+	}
+
+	if serialized, err := hex.DecodeString(txVerbose.Hex); err == nil && len(serialized) > 0 {
+		msgTx := wire.MsgTx{}
+		if err := msgTx.Deserialize(bytes.NewReader(serialized)); err == nil {
+			tx.Size, tx.Vsize, tx.Weight = txSizes(&msgTx)
+		}
+	}
+	if tx.Vsize == 0 {
+		// without witness the virtual size is the size
+		tx.Vsize, tx.Weight = tx.Size, tx.Size*witnessScaleFactor
+	}
+	return tx
+}
+
+/*
+txFromMsg decodes the transaction, so block transactions are processed
+without asking the node for each of them. Header is nil for transactions
+which are not in a block.
+*/
+func txFromMsg(msgTx *wire.MsgTx, header *wire.BlockHeader, params *chaincfg.Params) *Tx {
+	size, vsize, weight := txSizes(msgTx)
+
+	tx := &Tx{
+		Txid:     msgTx.TxHash().String(),
+		Hash:     msgTx.WitnessHash().String(),
+		Size:     size,
+		Vsize:    vsize,
+		Weight:   weight,
+		LockTime: msgTx.LockTime,
+		In:       make([]TxIn, 0, len(msgTx.TxIn)),
+		Out:      make([]TxOut, 0, len(msgTx.TxOut)),
+	}
+	if header != nil {
+		tx.BlockHash = header.BlockHash().String()
+		tx.Time = header.Timestamp.Unix()
+		tx.Blocktime = header.Timestamp.Unix()
+	}
+
+	coinbase := isCoinBase(msgTx)
+	for _, txIn := range msgTx.TxIn {
+		in := TxIn{
+			Sequence: txIn.Sequence,
+			Coinbase: coinbase,
+		}
+		if !coinbase {
+			in.Txid = txIn.PreviousOutPoint.Hash.String()
+			in.Vout = txIn.PreviousOutPoint.Index
+		}
+		tx.In = append(tx.In, in)
+	}
+
+	for n, txOut := range msgTx.TxOut {
+		class, reqSigs, addresses := scriptAddresses(txOut.PkScript, params)
+		tx.Out = append(tx.Out, TxOut{
+			N:          uint32(n),
+			Value:      btcutil.Amount(txOut.Value),
+			Script:     hex.EncodeToString(txOut.PkScript),
+			Type:       class,
+			Addresses:  addresses,
+			ReqSigs:    reqSigs,
+			ScriptHash: ScriptHash(txOut.PkScript),
+		})
+	}
+	return tx
+}
+
+// isCoinBase reports if the only input spends nothing
+func isCoinBase(msgTx *wire.MsgTx) bool {
+	if len(msgTx.TxIn) != 1 {
+		return false
+	}
+	prevOut := msgTx.TxIn[0].PreviousOutPoint
+	return prevOut.Index == wire.MaxPrevOutIndex && prevOut.Hash == (chainhash.Hash{})
+}
+
+// resolvePrevouts finds outputs spent by the transaction in the cache or on the node
+func (c *Client) resolvePrevouts(tx *Tx) {
+	for i, in := range tx.In {
+		if in.Coinbase || in.Prevout != nil {
+			continue
+		}
+		output, err := c.prevout(in.Txid, in.Vout)
+		if err != nil {
+			log.Errorf("resolvePrevouts:prevout: %s", err.Error())
+			continue
+		}
+		tx.In[i].Prevout = &output.TxOut
+		tx.In[i].PrevoutMined = output.Mined
+	}
+}
+
+// transaction converts the verbose transaction and resolves its previous outputs
+func (c *Client) transaction(txVerbose *btcjson.TxRawResult) *Tx {
+	tx := newTx(txVerbose, c.params)
+	c.resolvePrevouts(tx)
+	return tx
+}
+
+/*
+blockTransactions decodes all transactions of the block and caches their outputs
+before previous outputs are resolved, so outputs created earlier in the block
+are found in memory. Transactions of a disconnected block are not mined anymore.
+*/
+func (c *Client) blockTransactions(block *wire.MsgBlock, mined bool) []*Tx {
+	header := &block.Header
+	if !mined {
+		header = nil
+	}
+	txs := make([]*Tx, 0, len(block.Transactions))
+	for _, msgTx := range block.Transactions {
+		tx := txFromMsg(msgTx, header, c.params)
+		c.cacheOutputs(tx, mined)
+		txs = append(txs, tx)
+	}
+	for _, tx := range txs {
+		c.resolvePrevouts(tx)
+	}
+	return txs
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package btc
+
+import (
+	"github.com/Multy-io/Multy-BTC-node-service/storage"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// Statuses of checked transactions
+const (
+	TxUnknown   = "unknown"
+	TxMempool   = "mempool"
+	TxConfirmed = "confirmed"
+	// TxConflicted is replaced in mempool or double spent by a mined transaction
+	TxConflicted = "conflicted"
+)
+
+// TxState is the status of the transaction on the node
+type TxState struct {
+	Txid   string
+	Status string
+	// Height, BlockHash and Confirmations are set for confirmed transactions
+	Height        int64
+	BlockHash     string
+	Confirmations int64
+	// ReplacedBy is the transaction spending the same outputs of a conflicted one
+	ReplacedBy string
+}
+
+/*
+TxStates looks up transactions without the node's wallet.
+Mempool membership is asked from the node. Confirmed transactions are found
+by the node's transaction index or, without it, among watched transactions
+waiting for confirmations and in the local address index. Conflicts are known
+from the service's own mempool tracking, so transactions replaced before
+the service start are unknown. Unknown is not a rejection, a confirmed
+transaction is unknown if neither index has it.
+*/
+func (c *Client) TxStates(txids []string) ([]TxState, error) {
+	hashes, err := c.RPCClient.GetRawMempool()
+	if err != nil {
+		return nil, err
+	}
+	mempool := map[string]bool{}
+	for _, hash := range hashes {
+		mempool[hash.String()] = true
+	}
+	_, bestHeight, err := c.RPCClient.GetBestBlock()
+	if err != nil {
+		return nil, err
+	}
+	confTxs, err := c.Storage.ConfirmingTxs()
+	if err != nil {
+		log.Errorf("TxStates:Storage.ConfirmingTxs: %s", err.Error())
+	}
+	confirming := map[string]storage.ConfirmingTx{}
+	for _, confTx := range confTxs {
+		confirming[confTx.TxID] = confTx
+	}
+
+	states := []TxState{}
+	for _, txid := range txids {
+		state := TxState{
+			Txid:   txid,
+			Status: TxUnknown,
+		}
+		switch {
+		case mempool[txid]:
+			state.Status = TxMempool
+		case c.confirmedState(&state, int64(bestHeight), confirming):
+			state.Status = TxConfirmed
+		default:
+			if replacedBy, ok := c.mempoolSpends.replacement(txid); ok {
+				state.Status = TxConflicted
+				state.ReplacedBy = replacedBy
+			}
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// confirmedState sets the block of the transaction, false is returned if it's not found in the main chain
+func (c *Client) confirmedState(state *TxState, bestHeight int64, confirming map[string]storage.ConfirmingTx) bool {
+	hash, err := chainhash.NewHashFromStr(state.Txid)
+	if err != nil {
+		return false
+	}
+	if txVerbose, err := c.RPCClient.GetRawTransactionVerbose(hash); err == nil && txVerbose.BlockHash != "" {
+		blockHash, err := chainhash.NewHashFromStr(txVerbose.BlockHash)
+		if err != nil {
+			return false
+		}
+		block, err := c.RPCClient.GetBlockVerbose(blockHash)
+		if err != nil {
+			log.Errorf("confirmedState:GetBlockVerbose: %s", err.Error())
+			return false
+		}
+		if block.Confirmations == 0 {
+			// the block is orphaned, nodes report -1 confirmations which is an error here
+			return false
+		}
+		state.Height = block.Height
+		state.BlockHash = block.Hash
+		state.Confirmations = int64(block.Confirmations)
+		return true
+	}
+
+	if confTx, ok := confirming[state.Txid]; ok {
+		mainHash, err := c.RPCClient.GetBlockHash(confTx.BlockHeight)
+		if err == nil && mainHash.String() == confTx.BlockHash {
+			state.Height = confTx.BlockHeight
+			state.BlockHash = confTx.BlockHash
+			state.Confirmations = bestHeight - confTx.BlockHeight + 1
+			return true
+		}
+	}
+	return c.indexedState(state, bestHeight)
+}
+
+// indexedState sets the block of the transaction found in the local index, it's checked to be in the main chain
+func (c *Client) indexedState(state *TxState, bestHeight int64) bool {
+	height, ok, err := c.Storage.TxHeight(state.Txid)
+	if err != nil {
+		log.Errorf("indexedState:Storage.TxHeight: %s", err.Error())
+		return false
+	}
+	if !ok {
+		return false
+	}
+	blockHash, err := c.RPCClient.GetBlockHash(height)
+	if err != nil {
+		log.Errorf("indexedState:GetBlockHash: %s", err.Error())
+		return false
+	}
+	block, err := c.RPCClient.GetBlockVerbose(blockHash)
+	if err != nil {
+		log.Errorf("indexedState:GetBlockVerbose: %s", err.Error())
+		return false
+	}
+	for _, txid := range block.Tx {
+		if txid == state.Txid {
+			state.Height = height
+			state.BlockHash = blockHash.String()
+			state.Confirmations = bestHeight - height + 1
+			return true
+		}
+	}
+	return false
+}
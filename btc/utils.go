@@ -11,6 +11,7 @@ import (
 	"math"
 
 	pb "github.com/Multy-io/Multy-BTC-node-service/node-streamer"
+	"github.com/Multy-io/Multy-BTC-node-service/outbox"
 	"github.com/Multy-io/Multy-back/store"
 	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
@@ -52,50 +53,38 @@ func (c *Client) rawTxByTxid(txid string) (*btcjson.TxRawResult, error) {
 }
 
 // setTransactionInfo set fee, inputs and outputs
-func (c *Client) setTransactionInfo(multyTx *store.MultyTX, txVerbose *btcjson.TxRawResult, blockHeight int64, isReSync bool) error {
+func (c *Client) setTransactionInfo(multyTx *store.MultyTX, tx *Tx, blockHeight int64, isReSync bool) error {
 	inputs := []store.AddresAmount{}
 	outputs := []store.AddresAmount{}
-	var inputSum float64
-	var outputSum float64
 
-	for _, out := range txVerbose.Vout {
-		for _, address := range out.ScriptPubKey.Addresses {
-			amount := int64(out.Value * SatoshiToBitcoin)
-			outputs = append(outputs, newAddresAmount(address, amount))
+	for _, out := range tx.Out {
+		for _, address := range out.Addresses {
+			outputs = append(outputs, newAddresAmount(address, int64(out.Value)))
 		}
-		outputSum += out.Value
 	}
-	for _, input := range txVerbose.Vin {
-		hash, err := chainhash.NewHashFromStr(input.Txid)
-		if err != nil {
-			log.Errorf("setTransactionInfo:chainhash.NewHashFromStr: %s", err.Error())
-		}
-		previousTxVerbose, err := c.RPCClient.GetRawTransactionVerbose(hash)
-		if err != nil {
-			log.Errorf("setTransactionInfo:RPCClient.GetRawTransactionVerbose: %s", err.Error())
+	for _, input := range tx.In {
+		if input.Prevout == nil {
+			continue
 		}
-
-		if len(previousTxVerbose.Vout) >= int(input.Vout) {
-			for _, address := range previousTxVerbose.Vout[input.Vout].ScriptPubKey.Addresses {
-				amount := int64(previousTxVerbose.Vout[input.Vout].Value * SatoshiToBitcoin)
-				inputs = append(inputs, newAddresAmount(address, amount))
-			}
-			inputSum += previousTxVerbose.Vout[input.Vout].Value
+		for _, address := range input.Prevout.Addresses {
+			inputs = append(inputs, newAddresAmount(address, int64(input.Prevout.Value)))
 		}
-
 	}
-	fee := int64((inputSum - outputSum) * SatoshiToBitcoin)
+	fee, ok := tx.Fee()
+	if !ok {
+		log.Errorf("setTransactionInfo: fee of %s is unknown, spent outputs are not found", tx.Txid)
+	}
 
 	if blockHeight == -1 || isReSync {
-		multyTx.MempoolTime = txVerbose.Time
+		multyTx.MempoolTime = tx.Time
 	}
 
 	if blockHeight != -1 {
-		multyTx.BlockTime = txVerbose.Blocktime
+		multyTx.BlockTime = tx.Blocktime
 	}
 	multyTx.TxInputs = inputs
 	multyTx.TxOutputs = outputs
-	multyTx.TxFee = fee
+	multyTx.TxFee = int64(fee)
 
 	return nil
 }
@@ -111,26 +100,34 @@ can be called from:
 
 */
 
-func (c *Client) ProcessTransaction(blockChainBlockHeight int64, txVerbose *btcjson.TxRawResult, isReSync bool) {
-	multyTx, related := c.ParseRawTransaction(blockChainBlockHeight, txVerbose)
-	if related {
-		log.Debugf("ProcessTransaction...")
-		c.CreateSpendableOutputs(txVerbose, blockChainBlockHeight)
-		c.DeleteSpendableOutputs(txVerbose, blockChainBlockHeight)
-	}
+func (c *Client) ProcessTransaction(blockChainBlockHeight int64, tx *Tx, isReSync bool) {
+	multyTx, related := c.ParseRawTransaction(blockChainBlockHeight, tx)
 
 	if multyTx != nil {
 		multyTx.BlockHeight = blockChainBlockHeight
 		log.Debugf("ProcessTransaction... on blockHeight %d", blockChainBlockHeight)
 
-		c.setTransactionInfo(multyTx, txVerbose, blockChainBlockHeight, isReSync)
+		c.setTransactionInfo(multyTx, tx, blockChainBlockHeight, isReSync)
 
 		transactions := c.splitTransaction(*multyTx, blockChainBlockHeight)
 
 		for _, transaction := range transactions {
-			finalizeTransaction(&transaction, txVerbose)
-			c.saveMultyTransaction(transaction, isReSync)
+			finalizeTransaction(&transaction, tx)
+			c.saveMultyTransaction(transaction, tx, isReSync)
+		}
+
+		if blockChainBlockHeight != -1 {
+			c.trackConfirmations(tx, blockChainBlockHeight, transactions)
 		}
+	} else if !isReSync && c.matchOpReturn(tx) {
+		c.opReturnTransaction(tx, blockChainBlockHeight)
+	}
+
+	// spendable outputs are sent after the transaction which changes them
+	if related {
+		log.Debugf("ProcessTransaction...")
+		c.CreateSpendableOutputs(tx, blockChainBlockHeight)
+		c.DeleteSpendableOutputs(tx, blockChainBlockHeight)
 	}
 }
 
@@ -140,100 +137,59 @@ func (c *Client) ResyncAddresses(reTxs []store.ResyncTx, address *pb.AddressToRe
 		DeleteFromQueue: delFromResyncQ,
 	}
 	for _, reTx := range reTxs {
-		rawTx, err := c.rawTxByTxid(reTx.Hash)
+		txVerbose, err := c.rawTxByTxid(reTx.Hash)
 		if err != nil {
-			log.Errorf("ResyncAddresses:chainhash.NewHashFromStr: %v", err.Error())
+			log.Errorf("ResyncAddresses:rawTxByTxid: %v", err.Error())
+			continue
 		}
+		rawTx := c.transaction(txVerbose)
 		SpOut, SpOutDelete := c.ResyncSpendableOutputs(rawTx, int64(reTx.BlockHeight), address.GetAddress(), address.GetUserID())
 		resync.SpOutDelete = append(resync.SpOutDelete, SpOutDelete...)
 		resync.SpOuts = append(resync.SpOuts, SpOut...)
 
-		multyTx, _ := c.ParseRawTransaction(int64(reTx.BlockHeight), rawTx)
+		multyTx, related := c.ParseRawTransaction(int64(reTx.BlockHeight), rawTx)
+		if !related {
+			continue
+		}
 		c.setTransactionInfo(multyTx, rawTx, int64(reTx.BlockHeight), true)
 		multyTx.UserId = address.GetUserID()
 		transactions := c.splitTransaction(*multyTx, int64(reTx.BlockHeight))
 		for _, transaction := range transactions {
 			finalizeTransaction(&transaction, rawTx)
 			sTx := storeTxToGenerated(transaction)
+			setTxDetails(&sTx, rawTx)
 			resync.Txs = append(resync.Txs, &sTx)
 		}
 	}
-	c.ResyncCh <- resync
+	c.emit(outbox.KindResync, &resync)
 }
 
-func (c *Client) ResyncSpendableOutputs(tx *btcjson.TxRawResult, blockHeight int64, address, userid string) ([]*pb.AddSpOut, []*pb.ReqDeleteSpOut) {
+func (c *Client) ResyncSpendableOutputs(tx *Tx, blockHeight int64, address, userid string) ([]*pb.AddSpOut, []*pb.ReqDeleteSpOut) {
 	log.Debugf("ResyncSpendableOutputs")
 	spOuts := []*pb.AddSpOut{}
 	delOuts := []*pb.ReqDeleteSpOut{}
 	// add spout
-	for _, output := range tx.Vout {
-		if len(output.ScriptPubKey.Addresses) > 0 {
-			address := output.ScriptPubKey.Addresses[0]
-
-			ud := *c.UsersData
-			addressExt, ok := ud.Load(address)
-
-			if !ok {
-				continue
-			}
+	for _, spendableOutput := range c.spendableOutputs(tx, blockHeight) {
+		c.saveSpendableOutput(spendableOutput)
 
-			addressEx := addressExt.(store.AddressExtended)
-
-			txStatus := store.TxStatusAppearedInBlockIncoming
-			if blockHeight == -1 {
-				txStatus = store.TxStatusAppearedInMempoolIncoming
-			}
-
-			amount := int64(output.Value * SatoshiToBitcoin)
-			spendableOutput := store.SpendableOutputs{
-				TxID:         tx.Txid,
-				TxOutID:      int(output.N),
-				TxOutAmount:  amount,
-				TxOutScript:  output.ScriptPubKey.Hex,
-				Address:      address,
-				UserID:       addressEx.UserID,
-				TxStatus:     txStatus,
-				WalletIndex:  addressEx.WalletIndex,
-				AddressIndex: addressEx.AddressIndex,
-			}
-
-			spOut := spOutToGenerated(spendableOutput)
-			//send to channel of creation of spendable output
-			spOuts = append(spOuts, &spOut)
-
-		}
+		spOut := spOutToGenerated(spendableOutput)
+		//send to channel of creation of spendable output
+		spOuts = append(spOuts, &spOut)
 	}
 	// delete spout
-	for _, input := range tx.Vin {
-		previousTx, err := c.rawTxByTxid(input.Txid)
-		if err != nil {
-			log.Errorf("DeleteSpendableOutputs:rawTxByTxid: %s", err.Error())
+	for i, input := range tx.In {
+		if input.Prevout == nil {
+			continue
 		}
-
-		if previousTx == nil {
+		owners := c.spendableOwners(input.Prevout)
+		if len(owners) == 0 {
 			continue
 		}
+		c.removeSpendableOutput(input.Txid, int(input.Vout))
 
-		if len(previousTx.Vout[input.Vout].ScriptPubKey.Addresses) > 0 {
-			address := previousTx.Vout[input.Vout].ScriptPubKey.Addresses[0]
-
-			ud := *c.UsersData
-			addressExt, ok := ud.Load(address)
-
-			if !ok {
-				continue
-			}
-
-			addressEx := addressExt.(store.AddressExtended)
-
-			reqDelete := store.DeleteSpendableOutput{
-				UserID:  addressEx.UserID,
-				TxID:    previousTx.Txid,
-				Address: address,
-			}
-			del := delSpOutToGenerated(reqDelete)
+		for _, owner := range owners {
+			del := spentSpOut(owner, tx, i, blockHeight)
 			delOuts = append(delOuts, &del)
-
 		}
 	}
 	log.Errorf("spOuts %v delOuts %v", spOuts, delOuts)
@@ -250,21 +206,20 @@ Inputs:
 -1 in case of block transaction
 max chain height in case of resync
 
-*txVerbose - raw BTC transaction
+*tx - BTC transaction with resolved spent outputs
 _________________________
 Output:
 * multyTX - multy transaction Structure
-
 */
-func (c *Client) ParseRawTransaction(blockChainBlockHeight int64, txVerbose *btcjson.TxRawResult) (*store.MultyTX, bool) {
+func (c *Client) ParseRawTransaction(blockChainBlockHeight int64, tx *Tx) (*store.MultyTX, bool) {
 	multyTx := store.MultyTX{}
 
-	err := c.parseInputs(txVerbose, blockChainBlockHeight, &multyTx)
+	err := c.parseInputs(tx, blockChainBlockHeight, &multyTx)
 	if err != nil {
 		log.Errorf("ParseRawTransaction:parseInputs: %s", err.Error())
 	}
 
-	err = c.parseOutputs(txVerbose, blockChainBlockHeight, &multyTx)
+	err = c.parseOutputs(tx, blockChainBlockHeight, &multyTx)
 	if err != nil {
 		log.Errorf("ParseRawTransaction:parseOutputs: %s", err.Error())
 	}
@@ -309,7 +264,7 @@ func (c *Client) splitTransaction(multyTx store.MultyTX, blockHeight int64) []st
 			}
 		}
 
-		setTransactionStatus(&outgoingTx, blockDiff, currentBlockHeight, true)
+		setTransactionStatus(&outgoingTx, blockDiff, currentBlockHeight, true, c.confirmationDepth)
 		transactions = append(transactions, outgoingTx)
 	}
 
@@ -348,7 +303,7 @@ func (c *Client) splitTransaction(multyTx store.MultyTX, blockHeight int64) []st
 				incomingTx.WalletsInput = nil
 				incomingTx.WalletsOutput = []store.WalletForTx{}
 				incomingTx.WalletsOutput = append(incomingTx.WalletsOutput, walletOutput)
-				setTransactionStatus(&incomingTx, blockDiff, currentBlockHeight, false)
+				setTransactionStatus(&incomingTx, blockDiff, currentBlockHeight, false, c.confirmationDepth)
 				transactions = append(transactions, incomingTx)
 			}
 		}
@@ -379,7 +334,21 @@ func newEntity(multyTx store.MultyTX) store.MultyTX {
 	return newTx
 }
 
-func (c *Client) saveMultyTransaction(tx store.MultyTX, resync bool) {
+func (c *Client) saveMultyTransaction(tx store.MultyTX, btcTx *Tx, resync bool) {
+	generated, ok := multyTxToGenerated(tx)
+	if !ok {
+		return
+	}
+	setTxDetails(&generated, btcTx)
+	// resync flag is used only for incoming transactions
+	if len(tx.WalletsInput) == 0 {
+		generated.Resync = resync
+	}
+	c.emit(outbox.KindNewTx, &generated)
+}
+
+// multyTxToGenerated prepares splited transaction to be sent to the client
+func multyTxToGenerated(tx store.MultyTX) (pb.BTCTransaction, bool) {
 	// This is splited transaction! That means that transaction's WalletsInputs and WalletsOutput have the same WalletIndex!
 	//Here we have outgoing transaction for exact wallet!
 	if tx.WalletsInput != nil && len(tx.WalletsInput) > 0 {
@@ -410,32 +379,8 @@ func (c *Client) saveMultyTransaction(tx store.MultyTX, resync bool) {
 			}
 		}
 
-		outcomingTx := storeTxToGenerated(tx)
-		// send to outcomingTx to chan
-		c.TransactionsCh <- outcomingTx
-
-		return
+		return storeTxToGenerated(tx), true
 	} else if tx.WalletsOutput != nil && len(tx.WalletsOutput) > 0 {
-		if len(tx.WalletsInput) > 0 && len(tx.WalletsOutput) > 0 {
-			var amount int64
-			if len(tx.TxAddress) != 0 {
-				for _, in := range tx.TxInputs {
-					if in.Address == tx.TxAddress[0] {
-						amount += in.Amount
-					}
-				}
-			}
-			if amount == 0 {
-				for _, out := range tx.TxOutputs {
-					if out.Address == tx.TxAddress[0] {
-						amount += out.Amount
-					}
-				}
-			}
-
-			tx.TxOutAmount = amount
-		}
-
 		//HACK: fetching userid like this
 		for _, output := range tx.WalletsOutput {
 			if output.UserId != "" {
@@ -443,15 +388,18 @@ func (c *Client) saveMultyTransaction(tx store.MultyTX, resync bool) {
 				break
 			}
 		}
-		// fmt.Println("[DEBUG] newIncomingTx\n")
 
-		incomingTx := storeTxToGenerated(tx)
-		incomingTx.Resync = resync
-		// send to incomingTx to chan
-		c.TransactionsCh <- incomingTx
-
-		return
+		return storeTxToGenerated(tx), true
 	}
+	return pb.BTCTransaction{}, false
+}
+
+// setTxDetails adds sizes, the fee rate in sat/vB and OP_RETURN data of the transaction
+func setTxDetails(generated *pb.BTCTransaction, tx *Tx) {
+	generated.Vsize = tx.Vsize
+	generated.Weight = tx.Weight
+	generated.FeeRate = tx.FeeRate()
+	generated.OpReturns = opReturns(tx)
 }
 
 func storeTxToGenerated(tx store.MultyTX) pb.BTCTransaction {
@@ -512,46 +460,32 @@ func storeTxToGenerated(tx store.MultyTX) pb.BTCTransaction {
 	}
 }
 
-func (c *Client) parseInputs(txVerbose *btcjson.TxRawResult, blockHeight int64, multyTx *store.MultyTX) error {
+func (c *Client) parseInputs(tx *Tx, blockHeight int64, multyTx *store.MultyTX) error {
 	//Ranging by inputs
-	for _, input := range txVerbose.Vin {
+	for _, input := range tx.In {
 
-		//getting previous verbose transaction from BTC Node for checking addresses
-		previousTxVerbose, err := c.rawTxByTxid(input.Txid)
-		if err != nil {
-			log.Errorf("parseInputs:rawTxByTxid: %s", err.Error())
+		//previous output is resolved from the cache or BTC Node for checking addresses
+		if input.Prevout == nil {
 			continue
 		}
 
-		for _, txInAddress := range previousTxVerbose.Vout[input.Vout].ScriptPubKey.Addresses {
-			// check the ownership of the transaction to our users
-
-			ud := *c.UsersData
-			addressExt, ok := ud.Load(txInAddress)
-
-			if !ok {
-				continue
-			}
-
-			addressEx := addressExt.(store.AddressExtended)
-
-			txInAmount := int64(SatoshiToBitcoin * previousTxVerbose.Vout[input.Vout].Value)
-
+		// check the ownership of the transaction to our users
+		for _, owner := range c.owners(input.Prevout) {
 			currentWallet := store.WalletForTx{
-				UserId:      addressEx.UserID,
-				WalletIndex: addressEx.WalletIndex,
+				UserId:      owner.UserID,
+				WalletIndex: owner.WalletIndex,
 				Address: store.AddressForWallet{
-					AddressIndex:    addressEx.AddressIndex,
-					Address:         txInAddress,
-					Amount:          txInAmount,
+					AddressIndex:    owner.AddressIndex,
+					Address:         owner.Address,
+					Amount:          int64(input.Prevout.Value),
 					AddressOutIndex: int(input.Vout),
 				},
 			}
 
 			multyTx.WalletsInput = append(multyTx.WalletsInput, currentWallet)
 
-			multyTx.TxID = txVerbose.Txid
-			multyTx.TxHash = txVerbose.Hash
+			multyTx.TxID = tx.Txid
+			multyTx.TxHash = tx.Hash
 
 		}
 
@@ -560,42 +494,32 @@ func (c *Client) parseInputs(txVerbose *btcjson.TxRawResult, blockHeight int64,
 	return nil
 }
 
-func (c *Client) parseOutputs(txVerbose *btcjson.TxRawResult, blockHeight int64, multyTx *store.MultyTX) error {
+func (c *Client) parseOutputs(tx *Tx, blockHeight int64, multyTx *store.MultyTX) error {
 
 	//Ranging by outputs
-	for _, output := range txVerbose.Vout {
-		for _, txOutAddress := range output.ScriptPubKey.Addresses {
-
-			ud := *c.UsersData
-			addressExt, ok := ud.Load(txOutAddress)
-
-			if !ok {
-				continue
-			}
-
-			addressEx := addressExt.(store.AddressExtended)
-
+	for _, output := range tx.Out {
+		for _, owner := range c.owners(&output) {
 			currentWallet := store.WalletForTx{
-				UserId:      addressEx.UserID,
-				WalletIndex: addressEx.WalletIndex,
+				UserId:      owner.UserID,
+				WalletIndex: owner.WalletIndex,
 				Address: store.AddressForWallet{
-					AddressIndex:    addressEx.AddressIndex,
-					Address:         txOutAddress,
-					Amount:          int64(SatoshiToBitcoin * output.Value),
+					AddressIndex:    owner.AddressIndex,
+					Address:         owner.Address,
+					Amount:          int64(output.Value),
 					AddressOutIndex: int(output.N),
 				},
 			}
 
 			multyTx.WalletsOutput = append(multyTx.WalletsOutput, currentWallet)
 
-			multyTx.TxID = txVerbose.Txid
-			multyTx.TxHash = txVerbose.Hash
+			multyTx.TxID = tx.Txid
+			multyTx.TxHash = tx.Hash
 		}
 	}
 	return nil
 }
 
-func setTransactionStatus(tx *store.MultyTX, blockDiff int64, currentBlockHeight int64, fromInput bool) {
+func setTransactionStatus(tx *store.MultyTX, blockDiff int64, currentBlockHeight int64, fromInput bool, confirmationDepth int) {
 	transactionTime := time.Now().Unix()
 	if blockDiff > currentBlockHeight {
 		//This call was made from memPool
@@ -609,7 +533,7 @@ func setTransactionStatus(tx *store.MultyTX, blockDiff int64, currentBlockHeight
 			tx.MempoolTime = transactionTime
 			tx.BlockTime = -1
 		}
-	} else if blockDiff >= 0 && blockDiff < 6 {
+	} else if blockDiff >= 0 && blockDiff+1 < int64(confirmationDepth) {
 		//This call was made from block or resync
 		//Transaction have no enough confirmations
 		tx.Confirmations = int(blockDiff + 1)
@@ -620,7 +544,7 @@ func setTransactionStatus(tx *store.MultyTX, blockDiff int64, currentBlockHeight
 			tx.TxStatus = TxStatusAppearedInBlockIncoming
 			tx.BlockTime = transactionTime
 		}
-	} else if blockDiff >= 6 && blockDiff < currentBlockHeight {
+	} else if blockDiff+1 >= int64(confirmationDepth) && blockDiff < currentBlockHeight {
 		//This call was made from resync
 		//Transaction have enough confirmations
 		tx.Confirmations = int(blockDiff + 1)
@@ -633,7 +557,7 @@ func setTransactionStatus(tx *store.MultyTX, blockDiff int64, currentBlockHeight
 	}
 }
 
-func finalizeTransaction(tx *store.MultyTX, txVerbose *btcjson.TxRawResult) {
+func finalizeTransaction(tx *store.MultyTX, btcTx *Tx) {
 
 	if tx.TxAddress == nil {
 		tx.TxAddress = []string{}
@@ -648,12 +572,10 @@ func finalizeTransaction(tx *store.MultyTX, txVerbose *btcjson.TxRawResult) {
 		for i := 0; i < len(tx.WalletsOutput); i++ {
 			//Here we descreasing amount of the current transaction
 			tx.TxOutAmount -= tx.WalletsOutput[i].Address.Amount
-			for _, output := range txVerbose.Vout {
-				for _, outAddr := range output.ScriptPubKey.Addresses {
-					if tx.WalletsOutput[i].Address.Address == outAddr {
-						tx.WalletsOutput[i].Address.AddressOutIndex = int(output.N)
-						tx.TxOutScript = txVerbose.Vout[output.N].ScriptPubKey.Hex
-					}
+			// the output index is set by the ownership of the output
+			for _, output := range btcTx.Out {
+				if tx.WalletsOutput[i].Address.AddressOutIndex == int(output.N) {
+					tx.TxOutScript = output.Script
 				}
 			}
 		}
@@ -662,12 +584,9 @@ func finalizeTransaction(tx *store.MultyTX, txVerbose *btcjson.TxRawResult) {
 			tx.TxOutAmount += tx.WalletsOutput[i].Address.Amount
 			tx.TxAddress = append(tx.TxAddress, tx.WalletsOutput[i].Address.Address)
 
-			for _, output := range txVerbose.Vout {
-				for _, outAddr := range output.ScriptPubKey.Addresses {
-					if tx.WalletsOutput[i].Address.Address == outAddr {
-						tx.WalletsOutput[i].Address.AddressOutIndex = int(output.N)
-						tx.TxOutScript = txVerbose.Vout[output.N].ScriptPubKey.Hex
-					}
+			for _, output := range btcTx.Out {
+				if tx.WalletsOutput[i].Address.AddressOutIndex == int(output.N) {
+					tx.TxOutScript = output.Script
 				}
 			}
 		}
@@ -675,46 +594,17 @@ func finalizeTransaction(tx *store.MultyTX, txVerbose *btcjson.TxRawResult) {
 	}
 }
 
-func (c *Client) CreateSpendableOutputs(tx *btcjson.TxRawResult, blockHeight int64) {
+func (c *Client) CreateSpendableOutputs(tx *Tx, blockHeight int64) {
 	log.Debugf("CreateSpendableOutputs")
-	for _, output := range tx.Vout {
-		if len(output.ScriptPubKey.Addresses) > 0 {
-			address := output.ScriptPubKey.Addresses[0]
-
-			ud := *c.UsersData
-			addressExt, ok := ud.Load(address)
-
-			if !ok {
-				continue
-			}
-
-			addressEx := addressExt.(store.AddressExtended)
-
-			txStatus := store.TxStatusAppearedInBlockIncoming
-			if blockHeight == -1 {
-				txStatus = store.TxStatusAppearedInMempoolIncoming
-			}
-
-			amount := int64(output.Value * SatoshiToBitcoin)
-			spendableOutput := store.SpendableOutputs{
-				TxID:         tx.Txid,
-				TxOutID:      int(output.N),
-				TxOutAmount:  amount,
-				TxOutScript:  output.ScriptPubKey.Hex,
-				Address:      address,
-				UserID:       addressEx.UserID,
-				TxStatus:     txStatus,
-				WalletIndex:  addressEx.WalletIndex,
-				AddressIndex: addressEx.AddressIndex,
-			}
-
-			spOut := spOutToGenerated(spendableOutput)
-			//send to channel of creation of spendable output
-			c.AddSpOut <- spOut
+	for _, spendableOutput := range c.spendableOutputs(tx, blockHeight) {
+		c.saveSpendableOutput(spendableOutput)
 
-		}
+		spOut := spOutToGenerated(spendableOutput)
+		//send to channel of creation of spendable output
+		c.emit(outbox.KindAddSpOut, &spOut)
 	}
 }
+
 func spOutToGenerated(spOut store.SpendableOutputs) pb.AddSpOut {
 	return pb.AddSpOut{
 		TxID:         spOut.TxID,
@@ -729,88 +619,69 @@ func spOutToGenerated(spOut store.SpendableOutputs) pb.AddSpOut {
 	}
 }
 
-func (c *Client) DeleteSpendableOutputs(tx *btcjson.TxRawResult, blockHeight int64) {
+func (c *Client) DeleteSpendableOutputs(tx *Tx, blockHeight int64) {
 	log.Debugf("DeleteSpendableOutputs")
-	for _, input := range tx.Vin {
-		previousTx, err := c.rawTxByTxid(input.Txid)
-		if err != nil {
-			log.Errorf("DeleteSpendableOutputs:rawTxByTxid: %s", err.Error())
+	for i, input := range tx.In {
+		if input.Prevout == nil {
+			continue
 		}
-
-		if previousTx == nil {
+		owners := c.spendableOwners(input.Prevout)
+		if len(owners) == 0 {
 			continue
 		}
 
-		if len(previousTx.Vout[input.Vout].ScriptPubKey.Addresses) > 0 {
-			address := previousTx.Vout[input.Vout].ScriptPubKey.Addresses[0]
-
-			ud := *c.UsersData
-			addressExt, ok := ud.Load(address)
-
-			if !ok {
-				continue
-			}
-
-			addressEx := addressExt.(store.AddressExtended)
-
-			reqDelete := store.DeleteSpendableOutput{
-				UserID:  addressEx.UserID,
-				TxID:    previousTx.Txid,
-				Address: address,
-			}
-
-			del := delSpOutToGenerated(reqDelete)
-			c.DelSpOut <- del
+		c.removeSpendableOutput(input.Txid, int(input.Vout))
 
+		for _, owner := range owners {
+			del := spentSpOut(owner, tx, i, blockHeight)
+			c.emit(outbox.KindDeleteSpOut, &del)
 		}
 	}
 }
 
-func delSpOutToGenerated(del store.DeleteSpendableOutput) pb.ReqDeleteSpOut {
-	return pb.ReqDeleteSpOut{
-		UserID:  del.UserID,
-		TxID:    del.TxID,
-		Address: del.Address,
+// saveSpendableOutput keeps emitted spendable output in the storage
+func (c *Client) saveSpendableOutput(spOut store.SpendableOutputs) {
+	if err := c.Storage.AddSpendableOutput(spOut); err != nil {
+		log.Errorf("saveSpendableOutput:Storage.AddSpendableOutput: %s", err.Error())
 	}
 }
 
-func newMempoolRecord(category int, hashTX string) store.MempoolRecord {
-	return store.MempoolRecord{
-		Category: int64(category),
-		HashTX:   hashTX,
+// removeSpendableOutput deletes spent or rolled back output from the storage
+func (c *Client) removeSpendableOutput(txid string, index int) {
+	if err := c.Storage.DeleteSpendableOutput(txid, index); err != nil {
+		log.Errorf("removeSpendableOutput:Storage.DeleteSpendableOutput: %s", err.Error())
 	}
 }
 
-func (c *Client) rawTxToMempoolRec(inTx *btcjson.TxRawResult) store.MempoolRecord {
-	var inputSum float64
-	var outputSum float64
-
-	// if inTx != nil {
-	for _, input := range inTx.Vin {
-		txCHash, err := chainhash.NewHashFromStr(input.Txid)
-		if err != nil {
-			log.Errorf("newTxToDB: chainhash.NewHashFromStr: %s", err.Error())
-		}
-
-		previousTx, err := c.RPCClient.GetRawTransactionVerbose(txCHash)
-
-		if err != nil {
-			log.Errorf("newTxToDB: rPCClient.GetTransaction: %s", err.Error())
-		}
-		inputSum += previousTx.Vout[input.Vout].Value
-	}
-
-	for _, output := range inTx.Vout {
-		outputSum += output.Value
+// spentSpOut is the deletion of the owner's output spent by the input of tx at blockHeight, -1 for mempool
+func spentSpOut(owner owner, tx *Tx, inputIndex int, blockHeight int64) pb.ReqDeleteSpOut {
+	input := tx.In[inputIndex]
+	return pb.ReqDeleteSpOut{
+		UserID:              owner.UserID,
+		TxID:                input.Txid,
+		Address:             owner.Address,
+		TxOutID:             int32(input.Vout),
+		SpendingTxID:        tx.Txid,
+		SpendingInputIndex:  int32(inputIndex),
+		SpendingBlockHeight: blockHeight,
+		SpentInMempool:      blockHeight == -1,
 	}
-	fee := inputSum - outputSum
-
-	floatFee := fee / float64(inTx.Size) * 100000000
+}
 
+// category is the package rate in sat/vB rounded to integer
+func category(rate float64) int32 {
 	//It's some kind of Round function to prefent 0 FeeRates while casting from float to int
-	intFee := int(math.Floor(floatFee + 0.5))
+	return int32(math.Floor(rate + 0.5))
+}
 
-	rec := newMempoolRecord(intFee, inTx.Hash)
-	// }
-	return rec
+// newMempoolRecord makes mempool record with the own and package fee rates in sat/vB
+func newMempoolRecord(rate, packageRate float64, hashTX string, vsize, weight int32) pb.MempoolRecord {
+	return pb.MempoolRecord{
+		Category:       category(packageRate),
+		HashTX:         hashTX,
+		Vsize:          vsize,
+		Weight:         weight,
+		FeeRate:        rate,
+		PackageFeeRate: packageRate,
+	}
 }
@@ -0,0 +1,90 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package btc
+
+import (
+	"strconv"
+
+	"github.com/Multy-io/Multy-back/store"
+)
+
+/*
+Ownership rules of outputs, history and spendable outputs both follow them:
+
+	- an output is watched by each of its addresses and by its script hash,
+	  so every key of a bare multisig output is checked, not only the first one
+	- every wallet watching one of the keys owns the output, a co-owned output
+	  is reported to each owning wallet with the full output value
+	- a wallet owns the output once, by its first watched key in the order of
+	  watchKeys, even if it watches several keys of the output
+	- a multisig output needing more than one signature is shared, it's in the
+	  history of its owners but no wallet gets it as a spendable output
+*/
+
+// owner is a wallet owning an output by one of its watched keys
+type owner struct {
+	// Address is the watched address or script hash
+	Address string
+	store.AddressExtended
+}
+
+// watchKeys are the addresses and the script hash the output can be watched by
+func (out *TxOut) watchKeys() []string {
+	keys := append([]string{}, out.Addresses...)
+	if out.ScriptHash != "" {
+		keys = append(keys, out.ScriptHash)
+	}
+	return keys
+}
+
+func walletKey(addressEx store.AddressExtended) string {
+	return addressEx.UserID + ":" + strconv.Itoa(addressEx.WalletIndex)
+}
+
+// owners returns wallets owning the output, it's empty if the output is not watched
+func (c *Client) owners(out *TxOut) []owner {
+	owners := []owner{}
+	wallets := map[string]bool{}
+	for _, key := range out.watchKeys() {
+		addressExt, ok := c.UsersData.Load(key)
+		if !ok {
+			continue
+		}
+		addressEx := addressExt.(store.AddressExtended)
+		if wallets[walletKey(addressEx)] {
+			continue
+		}
+		wallets[walletKey(addressEx)] = true
+		owners = append(owners, owner{
+			Address:         key,
+			AddressExtended: addressEx,
+		})
+	}
+	return owners
+}
+
+// spendableOwners returns wallets which can spend the output alone, shared multisig has none
+func (c *Client) spendableOwners(out *TxOut) []owner {
+	if out.ReqSigs > 1 {
+		return nil
+	}
+	return c.owners(out)
+}
+
+// watchedTx reports if some output of the transaction or spent by it is owned by a wallet
+func (c *Client) watchedTx(tx *Tx) bool {
+	for i := range tx.Out {
+		if len(c.owners(&tx.Out[i])) > 0 {
+			return true
+		}
+	}
+	for _, in := range tx.In {
+		if in.Prevout != nil && len(c.owners(in.Prevout)) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,304 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package btc
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// DefaultWatchdogInterval is how often delivered notifications are checked
+const DefaultWatchdogInterval = 60 * time.Second
+
+// Delivery modes
+const (
+	ModePush    = "push"
+	ModePolling = "polling"
+)
+
+// WatchdogConf configures the check of backend notifications
+type WatchdogConf struct {
+	Disabled bool
+	// Interval in seconds between checks, 60 by default.
+	// Missed blocks and transactions are also polled with it.
+	Interval int
+}
+
+func (conf WatchdogConf) interval() time.Duration {
+	if conf.Interval <= 0 {
+		return DefaultWatchdogInterval
+	}
+	return time.Duration(conf.Interval) * time.Second
+}
+
+// Health is the state of notifications delivery
+type Health struct {
+	Degraded bool
+	// Mode is push while backend notifications are trusted and polling after a gap
+	Mode   string
+	Reason string
+	// Since is the time of the last mode change
+	Since           time.Time
+	CheckedAt       time.Time
+	MissedBlocks    int
+	MissedTxs       int
+	DeliveredHeight int64
+	BestHeight      int64
+}
+
+// deliveredBlocks is a number of recent heights remembered to drop repeated blocks
+const deliveredBlocks = 100
+
+// delivery remembers what notification handlers delivered
+type delivery struct {
+	m          sync.Mutex
+	tipHash    *chainhash.Hash
+	tipHeight  int32
+	pushHeight int32
+	pushTxAt   time.Time
+	// txs are remembered for the watchdog only, it prunes them on checks
+	trackTxs bool
+	txs      map[string]time.Time
+	// blocks are hashes of recently connected blocks by height
+	blocks map[int32]chainhash.Hash
+}
+
+func newDelivery(trackTxs bool) *delivery {
+	return &delivery{
+		trackTxs: trackTxs,
+		txs:      map[string]time.Time{},
+		blocks:   map[int32]chainhash.Hash{},
+	}
+}
+
+// blockConnected remembers the block, false is returned if it's already delivered at the height.
+// Push notifications and the poller may both deliver it.
+func (d *delivery) blockConnected(hash *chainhash.Hash, height int32, push bool) bool {
+	d.m.Lock()
+	defer d.m.Unlock()
+	if push && height > d.pushHeight {
+		d.pushHeight = height
+	}
+	if delivered, ok := d.blocks[height]; ok && delivered.IsEqual(hash) {
+		return false
+	}
+	d.blocks[height] = *hash
+	for h := range d.blocks {
+		if h <= height-deliveredBlocks {
+			delete(d.blocks, h)
+		}
+	}
+	if d.tipHash == nil || height >= d.tipHeight {
+		d.tipHash, d.tipHeight = hash, height
+	}
+	return true
+}
+
+func (d *delivery) blockDisconnected(height int32, prev *chainhash.Hash) {
+	d.m.Lock()
+	defer d.m.Unlock()
+	// the block can be connected again after the reorg
+	delete(d.blocks, height)
+	if d.tipHash != nil && height == d.tipHeight {
+		d.tipHash, d.tipHeight = prev, height-1
+	}
+}
+
+// tx remembers the delivered transaction, false is returned for duplicates.
+// Without the watchdog backends deliver each transaction once, nothing is remembered.
+func (d *delivery) tx(txid string, push bool) bool {
+	d.m.Lock()
+	defer d.m.Unlock()
+	if push {
+		d.pushTxAt = time.Now()
+	}
+	if !d.trackTxs {
+		return true
+	}
+	if _, ok := d.txs[txid]; ok {
+		return false
+	}
+	d.txs[txid] = time.Now()
+	return true
+}
+
+// watchdog checks that notifications are delivered and polls the node if they aren't
+type watchdog struct {
+	conf WatchdogConf
+
+	m      sync.Mutex
+	health Health
+
+	prevBest  *chainhash.Hash
+	seeded    bool
+	poller    *poller
+	quit      chan struct{}
+	switchedH int32
+	switchedT time.Time
+}
+
+func newWatchdog(conf WatchdogConf) *watchdog {
+	return &watchdog{
+		conf: conf,
+		health: Health{
+			Mode:  ModePush,
+			Since: time.Now(),
+		},
+	}
+}
+
+// Health returns the state of notifications delivery
+func (c *Client) Health() Health {
+	c.watchdog.m.Lock()
+	defer c.watchdog.m.Unlock()
+	return c.watchdog.health
+}
+
+// watch checks delivered notifications until the backend is shut down
+func (c *Client) watch() {
+	if c.watchdog.conf.Disabled {
+		return
+	}
+	ticker := time.NewTicker(c.watchdog.conf.interval())
+	defer ticker.Stop()
+	for range ticker.C {
+		c.checkDelivery()
+	}
+}
+
+/*
+checkDelivery compares the node with delivered notifications.
+
+The best block is a gap if it's not delivered and it was the best
+on the previous check too. Mempool transactions are a gap if they
+are older than the check interval and were not delivered.
+On a gap the client switches to polling, the poller starts from the
+delivered tip so missed blocks and transactions are back-filled
+through the handlers. Polling stops when push notifications deliver
+new blocks and transactions again.
+*/
+func (c *Client) checkDelivery() {
+	w := c.watchdog
+	interval := w.conf.interval()
+
+	bestHash, bestHeight, err := c.RPCClient.GetBestBlock()
+	if err != nil {
+		log.Errorf("checkDelivery:GetBestBlock: %s", err.Error())
+		return
+	}
+	mempool, err := c.RPCClient.GetRawMempoolVerbose()
+	if err != nil {
+		log.Errorf("checkDelivery:GetRawMempoolVerbose: %s", err.Error())
+		return
+	}
+
+	d := c.delivery
+	d.m.Lock()
+	if !w.seeded {
+		// everything before the first check is treated as delivered
+		w.seeded = true
+		if d.tipHash == nil {
+			d.tipHash, d.tipHeight = bestHash, bestHeight
+		}
+		for txid := range mempool {
+			d.txs[txid] = time.Now()
+		}
+	}
+	tipHash, tipHeight := d.tipHash, d.tipHeight
+	pushHeight, pushTxAt := d.pushHeight, d.pushTxAt
+
+	missedTxs := 0
+	old := time.Now().Add(-interval)
+	for txid, entry := range mempool {
+		if _, ok := d.txs[txid]; !ok && time.Unix(entry.Time, 0).Before(old) {
+			missedTxs++
+		}
+	}
+	// delivered transactions are kept while in mempool or recent
+	for txid, at := range d.txs {
+		if _, ok := mempool[txid]; !ok && at.Before(old.Add(-interval)) {
+			delete(d.txs, txid)
+		}
+	}
+	d.m.Unlock()
+
+	missedBlocks := 0
+	if !bestHash.IsEqual(tipHash) && bestHash.IsEqual(w.prevBest) {
+		missedBlocks = int(bestHeight - tipHeight)
+		if missedBlocks <= 0 {
+			// the delivered tip is orphaned
+			missedBlocks = 1
+		}
+	}
+	w.prevBest = bestHash
+
+	w.m.Lock()
+	defer w.m.Unlock()
+	w.health.CheckedAt = time.Now()
+	w.health.DeliveredHeight = int64(tipHeight)
+	w.health.BestHeight = int64(bestHeight)
+
+	if w.poller == nil {
+		if missedBlocks == 0 && missedTxs == 0 {
+			return
+		}
+		reason := fmt.Sprintf("notifications stalled: %d blocks and %d transactions were not delivered", missedBlocks, missedTxs)
+		log.Warnf("checkDelivery: %s, switching to polling", reason)
+		w.health = Health{
+			Degraded:        true,
+			Mode:            ModePolling,
+			Reason:          reason,
+			Since:           time.Now(),
+			CheckedAt:       time.Now(),
+			MissedBlocks:    missedBlocks,
+			MissedTxs:       missedTxs,
+			DeliveredHeight: int64(tipHeight),
+			BestHeight:      int64(bestHeight),
+		}
+		c.startPolling(tipHash, tipHeight)
+		w.switchedH, w.switchedT = bestHeight, time.Now()
+		return
+	}
+
+	// push notifications are back when they deliver blocks and transactions
+	// which appeared after the switch
+	pushBlocks := pushHeight > w.switchedH
+	pushTxs := pushTxAt.After(w.switchedT) || len(mempool) == 0
+	if pushBlocks && pushTxs && missedBlocks == 0 {
+		log.Warnf("checkDelivery: push notifications resumed, polling is stopped")
+		close(w.quit)
+		w.poller = nil
+		w.health = Health{
+			Mode:            ModePush,
+			Reason:          "push notifications resumed",
+			Since:           time.Now(),
+			CheckedAt:       time.Now(),
+			DeliveredHeight: int64(tipHeight),
+			BestHeight:      int64(bestHeight),
+		}
+	}
+}
+
+// startPolling back-fills from the delivered tip and keeps polling, called under watchdog lock
+func (c *Client) startPolling(tipHash *chainhash.Hash, tipHeight int32) {
+	w := c.watchdog
+	p := newPoller(c.RPCClient, c.handlers(false))
+	p.tipHash, p.tipHeight = tipHash, tipHeight
+
+	c.delivery.m.Lock()
+	for txid := range c.delivery.txs {
+		p.seen[txid] = struct{}{}
+	}
+	c.delivery.m.Unlock()
+	p.seenInit = true
+
+	w.poller = p
+	w.quit = make(chan struct{})
+	go p.start(w.conf.interval(), w.quit, func() bool { return true })
+}
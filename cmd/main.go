@@ -30,7 +30,8 @@ var globalOpt = node.Configuration{
 
 func main() {
 	config.ReadGlobalConfig(&globalOpt, "multy configuration")
-	log.Infof("CONFIGURATION=%+v", globalOpt)
+	// secrets are masked by Configuration.String
+	log.Infof("CONFIGURATION=%s", globalOpt)
 	log.Infof("branch: %s", branch)
 	log.Infof("commit: %s", commit)
 	log.Infof("build time: %s", buildtime)
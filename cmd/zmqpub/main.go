@@ -0,0 +1,74 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+
+/*
+zmqpub is a stand-in for bitcoind ZMQ notifications to test the core backend
+without a node publishing them.
+
+It reads lines "rawblock <hex>" and "rawtx <hex>" from stdin and publishes
+them like bitcoind does: [topic, body, sequence]. A line "skip <topic>"
+increases the topic sequence to emulate lost messages.
+
+	zmqpub -listen tcp://127.0.0.1:28332
+*/
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"flag"
+	"os"
+	"strings"
+
+	"github.com/Multy-io/Multy-BTC-node-service/zmq"
+	"github.com/jekabolt/slf"
+	_ "github.com/jekabolt/slflog"
+)
+
+var log = slf.WithContext("zmqpub").WithCaller(slf.CallerShort)
+
+func main() {
+	listen := flag.String("listen", "tcp://127.0.0.1:28332", "publisher address")
+	flag.Parse()
+
+	pub, err := zmq.Listen(*listen)
+	if err != nil {
+		log.Fatalf("zmq.Listen: %s", err.Error())
+	}
+	defer pub.Close()
+	log.Infof("publishing at %s", pub.Addr())
+
+	sequences := map[string]uint32{}
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		topic, value := fields[0], fields[1]
+		if topic == "skip" {
+			sequences[value]++
+			continue
+		}
+
+		body, err := hex.DecodeString(value)
+		if err != nil {
+			log.Errorf("hex.DecodeString: %s", err.Error())
+			continue
+		}
+		seq := make([]byte, 4)
+		binary.LittleEndian.PutUint32(seq, sequences[topic])
+		sequences[topic]++
+
+		pub.Publish([]byte(topic), body, seq)
+		log.Infof("published %s %d bytes to %d subscribers", topic, len(body), pub.Subscribers())
+	}
+	if err := scanner.Err(); err != nil {
+		log.Errorf("scanner.Err: %s", err.Error())
+	}
+}
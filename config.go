@@ -5,20 +5,73 @@ See LICENSE for details
 */
 package node
 
-import "github.com/Multy-io/Multy-back/store"
+import (
+	"fmt"
+
+	"github.com/Multy-io/Multy-BTC-node-service/btc"
+	"github.com/Multy-io/Multy-BTC-node-service/history"
+	"github.com/Multy-io/Multy-BTC-node-service/storage"
+	"github.com/Multy-io/Multy-back/store"
+	"github.com/btcsuite/btcd/chaincfg"
+)
 
 // Configuration is a struct with all service options
 type Configuration struct {
-	Name                string
-	GrpcPort            string
-	BTCSertificate      string
-	BTCNodeAddress      string
+	Name     string
+	GrpcPort string
+	// Network is one of mainnet, testnet, regtest and signet.
+	// BTCAPI.Chain is used if it's empty.
+	Network        string
+	BTCSertificate string
+	BTCNodeAddress string
+	// RPC is node connection settings, secrets may be "env:NAME" or "file:PATH"
+	RPC                 btc.RPCConf
 	ContinuousResyncCap int
+	ConfirmationDepth   int
+	Index               btc.IndexConf
+	History             history.Conf
+	FeeEstimation       btc.FeeConf
+	Watchdog            btc.WatchdogConf
+	PrevoutCacheSize    int
+	OpReturn            btc.OpReturnConf
+	MempoolSync         btc.MempoolSyncConf
 	BTCAPI              BTCApiConf
 	ServiceInfo         store.ServiceInfo
+	Storage             storage.Conf
 }
 
 // BTCApiConf provide blockcypher api
 type BTCApiConf struct {
 	Token, Coin, Chain string
 }
+
+// blockcypherChains are blockcypher chain names of networks
+var blockcypherChains = map[string]string{
+	btc.NetworkMainnet: "main",
+	btc.NetworkTestnet: "test3",
+}
+
+// network returns parameters of the configured network and
+// checks that blockcypher api uses the same one
+func (conf *Configuration) network() (*chaincfg.Params, error) {
+	network := conf.Network
+	if network == "" {
+		network = conf.BTCAPI.Chain
+	}
+	if network == "" {
+		network = btc.NetworkMainnet
+	}
+	params, err := btc.NetworkParams(network)
+	if err != nil {
+		return nil, err
+	}
+
+	chain, ok := blockcypherChains[btc.NetworkName(params)]
+	if ok && conf.BTCAPI.Chain != "" && conf.BTCAPI.Chain != chain {
+		return nil, fmt.Errorf("BTCAPI chain %q is not %s", conf.BTCAPI.Chain, btc.NetworkName(params))
+	}
+	if ok {
+		conf.BTCAPI.Chain = chain
+	}
+	return params, nil
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package history
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/Multy-io/Multy-back/store"
+)
+
+const fakePageSize = 50
+
+// Fake is an in-memory provider for offline resync, cursor is an offset.
+// It serves the configured history, tests fill it with Add.
+type Fake struct {
+	m   sync.Mutex
+	txs map[string][]store.ResyncTx
+	// err is returned instead of history if it's set
+	err error
+	// pages is the number of served pages
+	pages int
+}
+
+func NewFake() *Fake {
+	return &Fake{txs: map[string][]store.ResyncTx{}}
+}
+
+// Add appends transactions to the address history
+func (f *Fake) Add(address string, txs ...store.ResyncTx) *Fake {
+	f.m.Lock()
+	defer f.m.Unlock()
+	f.txs[address] = append(f.txs[address], txs...)
+	return f
+}
+
+// Fail makes the provider return the error instead of history, nil error restores it
+func (f *Fake) Fail(err error) *Fake {
+	f.m.Lock()
+	defer f.m.Unlock()
+	f.err = err
+	return f
+}
+
+// Pages returns the number of served pages
+func (f *Fake) Pages() int {
+	f.m.Lock()
+	defer f.m.Unlock()
+	return f.pages
+}
+
+func (f *Fake) Name() string { return ProviderFake }
+
+func (f *Fake) History(address, cursor string) ([]store.ResyncTx, string, error) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	if f.err != nil {
+		return nil, "", f.err
+	}
+	f.pages++
+
+	from := 0
+	if cursor != "" {
+		var err error
+		if from, err = strconv.Atoi(cursor); err != nil {
+			return nil, "", fmt.Errorf("wrong cursor %q", cursor)
+		}
+	}
+	all := f.txs[address]
+	if from >= len(all) {
+		return []store.ResyncTx{}, "", nil
+	}
+	to := from + fakePageSize
+	next := strconv.Itoa(to)
+	if to >= len(all) {
+		to = len(all)
+		next = ""
+	}
+	return append([]store.ResyncTx{}, all[from:to]...), next, nil
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package history
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/Multy-io/Multy-back/store"
+	"github.com/blockcypher/gobcy"
+	"github.com/jekabolt/slf"
+	_ "github.com/jekabolt/slflog"
+)
+
+var log = slf.WithContext("history").WithCaller(slf.CallerShort)
+
+// Provider names used in configuration
+const (
+	ProviderNode        = "node"
+	ProviderBlockcypher = "blockcypher"
+	ProviderBtcCom      = "btccom"
+	ProviderEsplora     = "esplora"
+	ProviderFake        = "fake"
+)
+
+// maxPages protects from providers which never stop pagination
+const maxPages = 10000
+
+// HistoryProvider is a source of address transactions
+type HistoryProvider interface {
+	// Name of the provider for logs
+	Name() string
+	// History returns one page of address transactions.
+	// Empty cursor requests the first page, empty next cursor means there are no more pages.
+	History(address, cursor string) (txs []store.ResyncTx, next string, err error)
+}
+
+// Conf selects history providers
+type Conf struct {
+	// Providers in fallback order: node, blockcypher, btccom, esplora, fake.
+	// By default the node is used if the local index is enabled,
+	// then blockcypher on testnet or btc.com on mainnet.
+	Providers []string
+	// BtcComURL and EsploraURL are base URLs of REST APIs,
+	// Esplora default depends on the network
+	BtcComURL  string
+	EsploraURL string
+	// Fake is the address history served by the fake provider
+	Fake map[string][]store.ResyncTx
+}
+
+// networks supported by providers, empty means any network
+var networks = map[string][]string{
+	ProviderBlockcypher: {"mainnet", "testnet"},
+	ProviderBtcCom:      {"mainnet"},
+	ProviderEsplora:     {"mainnet", "testnet", "signet"},
+}
+
+func supports(provider, network string) bool {
+	supported, ok := networks[provider]
+	if !ok {
+		return true
+	}
+	for _, n := range supported {
+		if n == network {
+			return true
+		}
+	}
+	return false
+}
+
+// AddressIndex is the local node index of addresses
+type AddressIndex interface {
+	AddressHistory(address string) ([]store.ResyncTx, error)
+	IndexEnabled() bool
+}
+
+// New creates providers from configuration in fallback order.
+// Network is one of mainnet, testnet, regtest and signet,
+// providers which don't serve it are rejected.
+func New(conf Conf, api *gobcy.API, index AddressIndex, network string) ([]HistoryProvider, error) {
+	names := conf.Providers
+	if len(names) == 0 {
+		if index.IndexEnabled() {
+			names = append(names, ProviderNode)
+		}
+		switch network {
+		case "testnet":
+			names = append(names, ProviderBlockcypher)
+		case "mainnet":
+			names = append(names, ProviderBtcCom)
+		case "signet":
+			names = append(names, ProviderEsplora)
+		}
+	}
+
+	providers := []HistoryProvider{}
+	for _, name := range names {
+		if !supports(name, network) {
+			return nil, fmt.Errorf("history provider %q doesn't serve %s", name, network)
+		}
+		switch name {
+		case ProviderNode:
+			providers = append(providers, NewNode(index))
+		case ProviderBlockcypher:
+			providers = append(providers, NewBlockcypher(api))
+		case ProviderBtcCom:
+			providers = append(providers, NewBtcCom(conf.BtcComURL))
+		case ProviderEsplora:
+			providers = append(providers, NewEsplora(conf.EsploraURL, network))
+		case ProviderFake:
+			fake := NewFake()
+			for address, txs := range conf.Fake {
+				fake.Add(address, txs...)
+			}
+			providers = append(providers, fake)
+		default:
+			return nil, fmt.Errorf("unknown history provider %q", name)
+		}
+	}
+	if len(providers) == 0 {
+		log.Warnf("New: no history providers for %s, resync is disabled", network)
+	}
+	return providers, nil
+}
+
+// Fetch loads all pages of the address history from the provider.
+// Transactions are unique and ordered by height, unconfirmed ones are the last.
+func Fetch(provider HistoryProvider, address string) ([]store.ResyncTx, error) {
+	txs := []store.ResyncTx{}
+	seen := map[string]bool{}
+	cursor := ""
+	for page := 0; page < maxPages; page++ {
+		pageTxs, next, err := provider.History(address, cursor)
+		if err != nil {
+			return nil, err
+		}
+		for _, tx := range pageTxs {
+			if seen[tx.Hash] {
+				continue
+			}
+			seen[tx.Hash] = true
+			txs = append(txs, tx)
+		}
+		if next == "" || next == cursor {
+			break
+		}
+		cursor = next
+	}
+
+	sort.SliceStable(txs, func(i, j int) bool {
+		if txs[i].BlockHeight <= 0 || txs[j].BlockHeight <= 0 {
+			return txs[i].BlockHeight > 0 && txs[j].BlockHeight <= 0
+		}
+		return txs[i].BlockHeight < txs[j].BlockHeight
+	})
+	return txs, nil
+}
+
+// AddressHistory returns the address history from the first provider which succeeds
+func AddressHistory(providers []HistoryProvider, address string) ([]store.ResyncTx, error) {
+	if len(providers) == 0 {
+		return nil, errors.New("no history providers configured")
+	}
+	var lastErr error
+	for _, provider := range providers {
+		txs, err := Fetch(provider, address)
+		if err != nil {
+			log.Errorf("AddressHistory:%s: %s", provider.Name(), err.Error())
+			lastErr = err
+			continue
+		}
+		log.Debugf("AddressHistory:%s: %d txs of %s", provider.Name(), len(txs), address)
+		return txs, nil
+	}
+	return nil, lastErr
+}
@@ -0,0 +1,278 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package history
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/Multy-io/Multy-back/store"
+)
+
+const testAddress = "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"
+
+func tx(hash string, height int) store.ResyncTx {
+	return store.ResyncTx{Hash: hash, BlockHeight: height}
+}
+
+// txsAt returns n transactions at heights from 1
+func txsAt(n int) []store.ResyncTx {
+	txs := []store.ResyncTx{}
+	for i := 1; i <= n; i++ {
+		txs = append(txs, tx(fmt.Sprintf("tx%d", i), i))
+	}
+	return txs
+}
+
+func hashes(txs []store.ResyncTx) []string {
+	h := []string{}
+	for _, tx := range txs {
+		h = append(h, tx.Hash)
+	}
+	return h
+}
+
+func TestAddressHistory(t *testing.T) {
+	errDown := errors.New("provider is down")
+
+	tests := []struct {
+		name      string
+		providers []*Fake
+		want      []string
+		wantPages []int
+		wantErr   bool
+	}{
+		{
+			name:      "one page",
+			providers: []*Fake{NewFake().Add(testAddress, tx("tx1", 1), tx("tx2", 2))},
+			want:      []string{"tx1", "tx2"},
+			wantPages: []int{1},
+		},
+		{
+			name:      "all pages are fetched",
+			providers: []*Fake{NewFake().Add(testAddress, txsAt(2*fakePageSize+1)...)},
+			want:      hashes(txsAt(2*fakePageSize + 1)),
+			wantPages: []int{3},
+		},
+		{
+			name:      "empty history",
+			providers: []*Fake{NewFake()},
+			want:      []string{},
+			wantPages: []int{1},
+		},
+		{
+			name:      "duplicates are removed",
+			providers: []*Fake{NewFake().Add(testAddress, tx("tx1", 1), tx("tx2", 2), tx("tx1", 1))},
+			want:      []string{"tx1", "tx2"},
+			wantPages: []int{1},
+		},
+		{
+			name:      "ordered by height, unconfirmed are the last",
+			providers: []*Fake{NewFake().Add(testAddress, tx("mempool", -1), tx("tx3", 3), tx("tx1", 1), tx("tx2", 2))},
+			want:      []string{"tx1", "tx2", "tx3", "mempool"},
+			wantPages: []int{1},
+		},
+		{
+			name: "next provider is used on error",
+			providers: []*Fake{
+				NewFake().Fail(errDown),
+				NewFake().Add(testAddress, tx("tx1", 1)),
+			},
+			want:      []string{"tx1"},
+			wantPages: []int{0, 1},
+		},
+		{
+			name: "first successful provider answers",
+			providers: []*Fake{
+				NewFake().Add(testAddress, tx("tx1", 1)),
+				NewFake().Add(testAddress, tx("tx2", 2)),
+			},
+			want:      []string{"tx1"},
+			wantPages: []int{1, 0},
+		},
+		{
+			name: "all providers fail",
+			providers: []*Fake{
+				NewFake().Fail(errDown),
+				NewFake().Fail(errDown),
+			},
+			wantPages: []int{0, 0},
+			wantErr:   true,
+		},
+		{
+			name:    "no providers",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			providers := []HistoryProvider{}
+			for _, provider := range test.providers {
+				providers = append(providers, provider)
+			}
+
+			txs, err := AddressHistory(providers, testAddress)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("AddressHistory: error is expected, got %v", hashes(txs))
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("AddressHistory: %s", err.Error())
+				}
+				if got := hashes(txs); !reflect.DeepEqual(got, test.want) {
+					t.Errorf("AddressHistory: got %v, want %v", got, test.want)
+				}
+			}
+
+			for i, provider := range test.providers {
+				if pages := provider.Pages(); pages != test.wantPages[i] {
+					t.Errorf("provider %d served %d pages, want %d", i, pages, test.wantPages[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name      string
+		providers []string
+		index     bool
+		network   string
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name:    "mainnet default",
+			network: "mainnet",
+			want:    []string{ProviderBtcCom},
+		},
+		{
+			name:    "testnet default with the index",
+			index:   true,
+			network: "testnet",
+			want:    []string{ProviderNode, ProviderBlockcypher},
+		},
+		{
+			name:    "regtest default without the index",
+			network: "regtest",
+			want:    []string{},
+		},
+		{
+			name:      "configured order",
+			providers: []string{ProviderEsplora, ProviderNode},
+			network:   "signet",
+			want:      []string{ProviderEsplora, ProviderNode},
+		},
+		{
+			name:      "fake on any network",
+			providers: []string{ProviderFake},
+			network:   "regtest",
+			want:      []string{ProviderFake},
+		},
+		{
+			name:      "provider of another network",
+			providers: []string{ProviderBtcCom},
+			network:   "testnet",
+			wantErr:   true,
+		},
+		{
+			name:      "unknown provider",
+			providers: []string{"unknown"},
+			network:   "regtest",
+			wantErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			providers, err := New(Conf{Providers: test.providers}, nil, fakeIndex(test.index), test.network)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("New: error is expected")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New: %s", err.Error())
+			}
+			names := []string{}
+			for _, provider := range providers {
+				names = append(names, provider.Name())
+			}
+			if !reflect.DeepEqual(names, test.want) {
+				t.Errorf("New: got %v, want %v", names, test.want)
+			}
+		})
+	}
+}
+
+// fakeIndex is the local index which is never asked for history in tests
+type fakeIndex bool
+
+func (i fakeIndex) AddressHistory(address string) ([]store.ResyncTx, error) {
+	return nil, nil
+}
+
+func (i fakeIndex) IndexEnabled() bool { return bool(i) }
+
+func TestConfiguredFake(t *testing.T) {
+	conf := Conf{
+		Providers: []string{ProviderFake},
+		Fake:      map[string][]store.ResyncTx{testAddress: {tx("tx2", 2), tx("tx1", 1)}},
+	}
+	providers, err := New(conf, nil, fakeIndex(false), "regtest")
+	if err != nil {
+		t.Fatalf("New: %s", err.Error())
+	}
+	txs, err := AddressHistory(providers, testAddress)
+	if err != nil {
+		t.Fatalf("AddressHistory: %s", err.Error())
+	}
+	if got, want := hashes(txs), []string{"tx1", "tx2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AddressHistory: got %v, want %v", got, want)
+	}
+}
+
+func TestBlockcypherCursor(t *testing.T) {
+	tests := []struct {
+		name   string
+		txs    []store.ResyncTx
+		cursor string
+		want   string
+	}{
+		{
+			name: "next page repeats the last height",
+			txs:  []store.ResyncTx{tx("tx3", 30), tx("tx2", 20), tx("tx1", 10)},
+			want: "11",
+		},
+		{
+			name: "unconfirmed transactions are skipped",
+			txs:  []store.ResyncTx{tx("mempool", -1), tx("tx1", 10), tx("mempool2", -1)},
+			want: "11",
+		},
+		{
+			name: "only unconfirmed transactions",
+			txs:  []store.ResyncTx{tx("mempool", -1), tx("mempool2", -1)},
+			want: "",
+		},
+		{
+			name:   "height with more transactions than a page is skipped",
+			txs:    []store.ResyncTx{tx("tx2", 10), tx("tx1", 10)},
+			cursor: "11",
+			want:   "10",
+		},
+	}
+	for _, test := range tests {
+		if got := blockcypherCursor(test.txs, test.cursor); got != test.want {
+			t.Errorf("%s: cursor %q, want %q", test.name, got, test.want)
+		}
+	}
+}
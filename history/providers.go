@@ -0,0 +1,233 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Multy-io/Multy-back/store"
+	"github.com/blockcypher/gobcy"
+	"github.com/parnurzeal/gorequest"
+)
+
+const (
+	defaultBtcComURL  = "https://chain.api.btc.com/v3"
+	defaultEsploraURL = "https://blockstream.info/api"
+	testnetEsploraURL = "https://blockstream.info/testnet/api"
+	signetEsploraURL  = "https://mempool.space/signet/api"
+
+	blockcypherPageSize = 50
+	btcComPageSize      = 50
+)
+
+// Node serves history from the local address index
+type Node struct {
+	index AddressIndex
+}
+
+func NewNode(index AddressIndex) *Node {
+	return &Node{index: index}
+}
+
+func (n *Node) Name() string { return ProviderNode }
+
+func (n *Node) History(address, cursor string) ([]store.ResyncTx, string, error) {
+	if !n.index.IndexEnabled() {
+		return nil, "", fmt.Errorf("local index is disabled")
+	}
+	txs, err := n.index.AddressHistory(address)
+	return txs, "", err
+}
+
+// Blockcypher serves history from blockcypher api newest first,
+// cursor is the block height the page is below
+type Blockcypher struct {
+	api *gobcy.API
+}
+
+func NewBlockcypher(api *gobcy.API) *Blockcypher {
+	return &Blockcypher{api: api}
+}
+
+func (b *Blockcypher) Name() string { return ProviderBlockcypher }
+
+func (b *Blockcypher) History(address, cursor string) ([]store.ResyncTx, string, error) {
+	params := map[string]string{"limit": strconv.Itoa(blockcypherPageSize)}
+	if cursor != "" {
+		params["before"] = cursor
+	}
+	addrInfo, err := b.api.GetAddrFull(address, params)
+	if err != nil {
+		return nil, "", fmt.Errorf("GetAddrFull: %s", err.Error())
+	}
+
+	txs := []store.ResyncTx{}
+	for _, tx := range addrInfo.TXs {
+		txs = append(txs, store.ResyncTx{
+			Hash:        tx.Hash,
+			BlockHeight: tx.BlockHeight,
+		})
+	}
+
+	next := ""
+	if addrInfo.HasMore {
+		next = blockcypherCursor(txs, cursor)
+	}
+	return txs, next, nil
+}
+
+/*
+blockcypherCursor returns the cursor of the page after txs.
+The next page starts with the height of the last confirmed transaction, the page
+could end in the middle of its transactions and repeated ones are removed by Fetch.
+If the page has nothing below the cursor height the height is skipped to go on,
+and a page of unconfirmed transactions only has no cursor.
+*/
+func blockcypherCursor(txs []store.ResyncTx, cursor string) string {
+	for i := len(txs) - 1; i >= 0; i-- {
+		height := txs[i].BlockHeight
+		if height <= 0 {
+			continue
+		}
+		next := strconv.Itoa(height + 1)
+		if next == cursor {
+			next = strconv.Itoa(height)
+		}
+		return next
+	}
+	return ""
+}
+
+// BtcCom serves history from btc.com api, cursor is a page number
+type BtcCom struct {
+	url string
+}
+
+func NewBtcCom(url string) *BtcCom {
+	if url == "" {
+		url = defaultBtcComURL
+	}
+	return &BtcCom{url: strings.TrimSuffix(url, "/")}
+}
+
+func (b *BtcCom) Name() string { return ProviderBtcCom }
+
+func (b *BtcCom) History(address, cursor string) ([]store.ResyncTx, string, error) {
+	page := 1
+	if cursor != "" {
+		var err error
+		if page, err = strconv.Atoi(cursor); err != nil {
+			return nil, "", fmt.Errorf("wrong cursor %q", cursor)
+		}
+	}
+
+	url := b.url + "/address/" + address + "/tx?page=" + strconv.Itoa(page)
+	reTx := store.BtcComResp{}
+	if err := getJSON(url, &reTx); err != nil {
+		return nil, "", err
+	}
+
+	txs := []store.ResyncTx{}
+	for _, tx := range reTx.Data.List {
+		txs = append(txs, store.ResyncTx{
+			Hash:        tx.Hash,
+			BlockHeight: tx.BlockHeight,
+		})
+	}
+
+	next := ""
+	if len(txs) > 0 && page*btcComPageSize < reTx.Data.TotalCount {
+		next = strconv.Itoa(page + 1)
+	}
+	return txs, next, nil
+}
+
+// Esplora serves history from Esplora REST api, cursor is the last seen txid
+type Esplora struct {
+	url string
+}
+
+type esploraTx struct {
+	Txid   string `json:"txid"`
+	Status struct {
+		Confirmed   bool `json:"confirmed"`
+		BlockHeight int  `json:"block_height"`
+	} `json:"status"`
+}
+
+func NewEsplora(url, network string) *Esplora {
+	if url == "" {
+		switch network {
+		case "testnet":
+			url = testnetEsploraURL
+		case "signet":
+			url = signetEsploraURL
+		default:
+			url = defaultEsploraURL
+		}
+	}
+	return &Esplora{url: strings.TrimSuffix(url, "/")}
+}
+
+func (e *Esplora) Name() string { return ProviderEsplora }
+
+func (e *Esplora) History(address, cursor string) ([]store.ResyncTx, string, error) {
+	esploraTxs := []esploraTx{}
+	if cursor == "" {
+		// the first page contains mempool transactions too
+		if err := getJSON(e.url+"/address/"+address+"/txs", &esploraTxs); err != nil {
+			return nil, "", err
+		}
+	} else {
+		if err := getJSON(e.url+"/address/"+address+"/txs/chain/"+cursor, &esploraTxs); err != nil {
+			return nil, "", err
+		}
+	}
+
+	txs := []store.ResyncTx{}
+	next := ""
+	for _, tx := range esploraTxs {
+		height := -1
+		if tx.Status.Confirmed {
+			height = tx.Status.BlockHeight
+			next = tx.Txid
+		}
+		txs = append(txs, store.ResyncTx{
+			Hash:        tx.Txid,
+			BlockHeight: height,
+		})
+	}
+	if len(esploraTxs) == 0 {
+		next = ""
+	}
+	return txs, next, nil
+}
+
+func getJSON(url string, v interface{}) error {
+	request := gorequest.New()
+	resp, _, errs := request.Get(url).Retry(3, 2*time.Second, http.StatusForbidden, http.StatusTooManyRequests, http.StatusInternalServerError).End()
+	if len(errs) > 0 {
+		return fmt.Errorf("request.Get %s: %v", url, errs)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("request.Get %s: %s", url, resp.Status)
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("ioutil.ReadAll: %s", err.Error())
+	}
+	if err := json.Unmarshal(respBody, v); err != nil {
+		return fmt.Errorf("json.Unmarshal: %s", err.Error())
+	}
+	return nil
+}
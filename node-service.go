@@ -14,9 +14,11 @@ import (
 	"time"
 
 	"github.com/Multy-io/Multy-BTC-node-service/btc"
+	"github.com/Multy-io/Multy-BTC-node-service/history"
 	pb "github.com/Multy-io/Multy-BTC-node-service/node-streamer"
+	"github.com/Multy-io/Multy-BTC-node-service/outbox"
+	"github.com/Multy-io/Multy-BTC-node-service/storage"
 	"github.com/Multy-io/Multy-BTC-node-service/streamer"
-	"github.com/Multy-io/Multy-back/store"
 	"github.com/blockcypher/gobcy"
 	"github.com/jekabolt/slf"
 	_ "github.com/jekabolt/slflog"
@@ -36,6 +38,10 @@ type NodeClient struct {
 	GRPCserver *streamer.Server
 	Clients    *sync.Map // address to userid
 	BtcApi     *gobcy.API
+	Storage    storage.Storage
+	Events     *outbox.Outbox
+	History    []history.HistoryProvider
+	reload     chan struct{}
 }
 
 // Init initializes Multy instance
@@ -44,13 +50,41 @@ func (nc *NodeClient) Init(conf *Configuration) (*NodeClient, error) {
 		Config: conf,
 	}
 
-	usersData := sync.Map{}
+	if err := conf.resolveSecrets(); err != nil {
+		return nil, fmt.Errorf("Secrets configuration: %s", err.Error())
+	}
+
+	params, err := conf.network()
+	if err != nil {
+		return nil, fmt.Errorf("Network configuration: %s", err.Error())
+	}
+	network := btc.NetworkName(params)
+	log.Debugf("Network %s √", network)
+
+	// there are no public explorers for regtest, history comes from the local index
+	index := conf.Index
+	if network == btc.NetworkRegtest && !index.Enabled {
+		log.Warnf("Init: local index is enabled for regtest")
+		index.Enabled = true
+	}
 
-	usersData.Store("address", store.AddressExtended{
-		UserID:       "kek",
-		WalletIndex:  1,
-		AddressIndex: 2,
-	})
+	db, err := storage.New(conf.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("Storage initialization: %s", err.Error())
+	}
+	nc.Storage = db
+	nc.Events = outbox.New(db)
+	log.Debug("Storage initialization done √")
+
+	addresses, err := db.Addresses()
+	if err != nil {
+		return nil, fmt.Errorf("Storage addresses: %s", err.Error())
+	}
+	usersData := sync.Map{}
+	for address, ex := range addresses {
+		// addresses stored before they were normalized
+		usersData.Store(btc.NormalizeAddress(address, params), ex)
+	}
 
 	api := gobcy.API{
 		Token: conf.BTCAPI.Token,
@@ -62,47 +96,88 @@ func (nc *NodeClient) Init(conf *Configuration) (*NodeClient, error) {
 
 	// initail initialization of clients data
 	nc.Clients = &usersData
-	log.Debug("Users data initialization done √")
+	log.Debugf("Users data initialization done √ %d addresses", len(addresses))
 
-	// init gRPC server
-	lis, err := net.Listen("tcp", conf.GrpcPort)
-	if err != nil {
-		return nil, fmt.Errorf("failed to listen: %v", err.Error())
+	certificate := []byte{}
+	if !conf.RPC.DisableTLS {
+		certificate = getCertificate(conf.BTCSertificate)
 	}
-
-	btcClient, err := btc.NewClient(getCertificate(conf.BTCSertificate), conf.BTCNodeAddress, nc.Clients)
+	btcConf := btc.Conf{
+		Certificate:       certificate,
+		NodeAddress:       conf.BTCNodeAddress,
+		RPC:               conf.RPC,
+		Params:            params,
+		ConfirmationDepth: conf.ConfirmationDepth,
+		Index:             index,
+		Fee:               conf.FeeEstimation,
+		Watchdog:          conf.Watchdog,
+		PrevoutCacheSize:  conf.PrevoutCacheSize,
+		OpReturn:          conf.OpReturn,
+		MempoolSync:       conf.MempoolSync,
+	}
+	btcClient, err := btc.NewClient(btcConf, nc.Clients, nc.Storage, nc.Events)
 	if err != nil {
 		return nil, fmt.Errorf("Blockchain api initialization: %s", err.Error())
 	}
 	log.Debug("BTC client initialization done √")
 	nc.Instance = btcClient
 
+	providers, err := history.New(conf.History, nc.BtcApi, btcClient, network)
+	if err != nil {
+		return nil, fmt.Errorf("History providers initialization: %s", err.Error())
+	}
+	nc.History = providers
+	log.Debugf("History providers initialization done √ %d providers", len(providers))
+
+	nc.reload = make(chan struct{})
+	if err := nc.serve(); err != nil {
+		return nil, err
+	}
+
+	go WathReload(nc.reload, nc)
+
+	// go ContinuousResync(nc)
+
+	go log.Debug("NodeCommuunications Server initialization done √")
+
+	return nc, nil
+}
+
+// serve starts gRPC server on top of already initialized service state
+func (nc *NodeClient) serve() error {
+	lis, err := net.Listen("tcp", nc.Config.GrpcPort)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %v", err.Error())
+	}
+
 	// Creates a new gRPC server
 	s := grpc.NewServer()
 	srv := streamer.Server{
 		UsersData:  nc.Clients,
+		Storage:    nc.Storage,
+		Events:     nc.Events,
+		History:    nc.History,
 		BtcAPI:     nc.BtcApi,
 		M:          &sync.Mutex{},
-		BtcCli:     btcClient,
-		Info:       &conf.ServiceInfo,
+		BtcCli:     nc.Instance,
+		Info:       &nc.Config.ServiceInfo,
 		GRPCserver: s,
 		Listener:   lis,
-		ReloadChan: make(chan struct{}),
 	}
 
 	nc.GRPCserver = &srv
 
 	pb.RegisterNodeCommunicationsServer(s, &srv)
 
-	go s.Serve(lis)
-
-	go WathReload(srv.ReloadChan, nc)
-
-	// go ContinuousResync(nc)
-
-	go log.Debug("NodeCommuunications Server initialization done √")
+	go func() {
+		// Serve returns nil after Stop
+		if err := s.Serve(lis); err != nil {
+			log.Errorf("serve:Serve %v", err.Error())
+			nc.reload <- struct{}{}
+		}
+	}()
 
-	return nc, nil
+	return nil
 }
 
 func getCertificate(certFile string) []byte {
@@ -120,6 +195,8 @@ func getCertificate(certFile string) []byte {
 	return []byte{}
 }
 
+// WathReload restarts gRPC server when it fails.
+// Watch set, btc client and storage are kept between restarts.
 func WathReload(reload chan struct{}, cli *NodeClient) {
 	for {
 		select {
@@ -133,15 +210,16 @@ func WathReload(reload chan struct{}, cli *NodeClient) {
 			cli.GRPCserver.GRPCserver.Stop()
 			log.Warnf("WathReload:Successfully stopped")
 			for _ = range ticker.C {
-				_, err := cli.Init(cli.Config)
+				err := cli.serve()
 				if err != nil {
-					log.Errorf("WathReload:Init %v ", err)
+					log.Errorf("WathReload:serve %v ", err)
 					continue
 				}
 
 				log.Warnf("WathReload:Successfully reloaded")
-				return
+				break
 			}
+			ticker.Stop()
 		}
 	}
 }
@@ -9,17 +9,29 @@ It is generated from these files:
 
 It has these top-level messages:
 	TxsToCheck
+	CheckedTx
 	RejectedTxs
 	BTCTransaction
 	AddSpOut
 	Resync
+	BlockDisconnected
+	TxConflict
+	Health
+	FeeEstimateRequest
+	FeeEstimate
+	FeeEstimates
 	BlockHeight
 	ReqDeleteSpOut
 	MempoolToDelete
 	WatchAddress
 	MempoolRecord
 	Empty
+	Subscription
+	Event
+	Cursor
 	RawTx
+	SendTxProblem
+	SendRawTxReply
 	AddressToResync
 	UsersData
 	AddressExtended
@@ -48,6 +60,169 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
+// status of a checked transaction
+type TxCheckStatus int32
+
+const (
+	TxCheckStatus_CHECK_UNKNOWN   TxCheckStatus = 0
+	TxCheckStatus_CHECK_MEMPOOL   TxCheckStatus = 1
+	TxCheckStatus_CHECK_CONFIRMED TxCheckStatus = 2
+	// replaced in mempool or double spent by a mined transaction
+	TxCheckStatus_CHECK_CONFLICTED TxCheckStatus = 3
+)
+
+var TxCheckStatus_name = map[int32]string{
+	0: "CHECK_UNKNOWN",
+	1: "CHECK_MEMPOOL",
+	2: "CHECK_CONFIRMED",
+	3: "CHECK_CONFLICTED",
+}
+var TxCheckStatus_value = map[string]int32{
+	"CHECK_UNKNOWN":    0,
+	"CHECK_MEMPOOL":    1,
+	"CHECK_CONFIRMED":  2,
+	"CHECK_CONFLICTED": 3,
+}
+
+func (x TxCheckStatus) String() string {
+	return proto.EnumName(TxCheckStatus_name, int32(x))
+}
+func (TxCheckStatus) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+// why the transaction left mempool
+type MempoolDeleteReason int32
+
+const (
+	MempoolDeleteReason_REASON_UNKNOWN MempoolDeleteReason = 0
+	MempoolDeleteReason_MINED          MempoolDeleteReason = 1
+	// replaced by a mempool transaction spending the same outputs
+	MempoolDeleteReason_REPLACED MempoolDeleteReason = 2
+	// evicted for low fee, expired or lost on the node restart
+	MempoolDeleteReason_EVICTED MempoolDeleteReason = 3
+	// conflicted by a mined transaction spending the same outputs
+	MempoolDeleteReason_CONFLICTED MempoolDeleteReason = 4
+)
+
+var MempoolDeleteReason_name = map[int32]string{
+	0: "REASON_UNKNOWN",
+	1: "MINED",
+	2: "REPLACED",
+	3: "EVICTED",
+	4: "CONFLICTED",
+}
+var MempoolDeleteReason_value = map[string]int32{
+	"REASON_UNKNOWN": 0,
+	"MINED":          1,
+	"REPLACED":       2,
+	"EVICTED":        3,
+	"CONFLICTED":     4,
+}
+
+func (x MempoolDeleteReason) String() string {
+	return proto.EnumName(MempoolDeleteReason_name, int32(x))
+}
+func (MempoolDeleteReason) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+type EventKind int32
+
+const (
+	EventKind_ALL                EventKind = 0
+	EventKind_NEW_TX             EventKind = 1
+	EventKind_ADD_SPOUT          EventKind = 2
+	EventKind_DELETE_SPOUT       EventKind = 3
+	EventKind_ADD_MEMPOOL        EventKind = 4
+	EventKind_DELETE_MEMPOOL     EventKind = 5
+	EventKind_NEW_BLOCK          EventKind = 6
+	EventKind_BLOCK_DISCONNECTED EventKind = 7
+	EventKind_RESYNC             EventKind = 8
+	EventKind_TX_REPLACED        EventKind = 9
+	EventKind_TX_CONFLICTED      EventKind = 10
+	EventKind_TX_DROPPED         EventKind = 11
+	// transactions without watched addresses matched by OP_RETURN prefix,
+	// they are sent only if requested, ALL doesn't include them
+	EventKind_OP_RETURN_TX EventKind = 12
+)
+
+var EventKind_name = map[int32]string{
+	0:  "ALL",
+	1:  "NEW_TX",
+	2:  "ADD_SPOUT",
+	3:  "DELETE_SPOUT",
+	4:  "ADD_MEMPOOL",
+	5:  "DELETE_MEMPOOL",
+	6:  "NEW_BLOCK",
+	7:  "BLOCK_DISCONNECTED",
+	8:  "RESYNC",
+	9:  "TX_REPLACED",
+	10: "TX_CONFLICTED",
+	11: "TX_DROPPED",
+	12: "OP_RETURN_TX",
+}
+var EventKind_value = map[string]int32{
+	"ALL":                0,
+	"NEW_TX":             1,
+	"ADD_SPOUT":          2,
+	"DELETE_SPOUT":       3,
+	"ADD_MEMPOOL":        4,
+	"DELETE_MEMPOOL":     5,
+	"NEW_BLOCK":          6,
+	"BLOCK_DISCONNECTED": 7,
+	"RESYNC":             8,
+	"TX_REPLACED":        9,
+	"TX_CONFLICTED":      10,
+	"TX_DROPPED":         11,
+	"OP_RETURN_TX":       12,
+}
+
+func (x EventKind) String() string {
+	return proto.EnumName(EventKind_name, int32(x))
+}
+func (EventKind) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+// why the raw transaction can't be sent
+type SendTxReason int32
+
+const (
+	SendTxReason_SEND_UNKNOWN  SendTxReason = 0
+	SendTxReason_PARSE_ERROR   SendTxReason = 1
+	SendTxReason_MISSING_INPUT SendTxReason = 2
+	SendTxReason_SPENT_INPUT   SendTxReason = 3
+	SendTxReason_FEE_TOO_LOW   SendTxReason = 4
+	SendTxReason_ABSURD_FEE    SendTxReason = 5
+	SendTxReason_DUST_OUTPUT   SendTxReason = 6
+	SendTxReason_NON_FINAL     SendTxReason = 7
+	// rejected by the node on broadcast
+	SendTxReason_NODE_REJECTED SendTxReason = 8
+)
+
+var SendTxReason_name = map[int32]string{
+	0: "SEND_UNKNOWN",
+	1: "PARSE_ERROR",
+	2: "MISSING_INPUT",
+	3: "SPENT_INPUT",
+	4: "FEE_TOO_LOW",
+	5: "ABSURD_FEE",
+	6: "DUST_OUTPUT",
+	7: "NON_FINAL",
+	8: "NODE_REJECTED",
+}
+var SendTxReason_value = map[string]int32{
+	"SEND_UNKNOWN":  0,
+	"PARSE_ERROR":   1,
+	"MISSING_INPUT": 2,
+	"SPENT_INPUT":   3,
+	"FEE_TOO_LOW":   4,
+	"ABSURD_FEE":    5,
+	"DUST_OUTPUT":   6,
+	"NON_FINAL":     7,
+	"NODE_REJECTED": 8,
+}
+
+func (x SendTxReason) String() string {
+	return proto.EnumName(SendTxReason_name, int32(x))
+}
+func (SendTxReason) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
 // continious resync
 type TxsToCheck struct {
 	Hash []string `protobuf:"bytes,1,rep,name=Hash" json:"Hash,omitempty"`
@@ -65,14 +240,74 @@ func (m *TxsToCheck) GetHash() []string {
 	return nil
 }
 
+type CheckedTx struct {
+	Hash   string        `protobuf:"bytes,1,opt,name=hash" json:"hash,omitempty"`
+	Status TxCheckStatus `protobuf:"varint,2,opt,name=status,enum=btc.TxCheckStatus" json:"status,omitempty"`
+	// height, blockHash and confirmations are set for confirmed transactions
+	Height        int64  `protobuf:"varint,3,opt,name=height" json:"height,omitempty"`
+	BlockHash     string `protobuf:"bytes,4,opt,name=blockHash" json:"blockHash,omitempty"`
+	Confirmations int64  `protobuf:"varint,5,opt,name=confirmations" json:"confirmations,omitempty"`
+	// replacedBy spends the same outputs as the conflicted transaction
+	ReplacedBy string `protobuf:"bytes,6,opt,name=replacedBy" json:"replacedBy,omitempty"`
+}
+
+func (m *CheckedTx) Reset()                    { *m = CheckedTx{} }
+func (m *CheckedTx) String() string            { return proto.CompactTextString(m) }
+func (*CheckedTx) ProtoMessage()               {}
+func (*CheckedTx) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *CheckedTx) GetHash() string {
+	if m != nil {
+		return m.Hash
+	}
+	return ""
+}
+
+func (m *CheckedTx) GetStatus() TxCheckStatus {
+	if m != nil {
+		return m.Status
+	}
+	return TxCheckStatus_CHECK_UNKNOWN
+}
+
+func (m *CheckedTx) GetHeight() int64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *CheckedTx) GetBlockHash() string {
+	if m != nil {
+		return m.BlockHash
+	}
+	return ""
+}
+
+func (m *CheckedTx) GetConfirmations() int64 {
+	if m != nil {
+		return m.Confirmations
+	}
+	return 0
+}
+
+func (m *CheckedTx) GetReplacedBy() string {
+	if m != nil {
+		return m.ReplacedBy
+	}
+	return ""
+}
+
 type RejectedTxs struct {
-	RejectedTxs []string `protobuf:"bytes,1,rep,name=RejectedTxs" json:"RejectedTxs,omitempty"`
+	// RejectedTxs are conflicted transactions, unknown ones are reported in txs only
+	RejectedTxs []string     `protobuf:"bytes,1,rep,name=RejectedTxs" json:"RejectedTxs,omitempty"`
+	Txs         []*CheckedTx `protobuf:"bytes,2,rep,name=txs" json:"txs,omitempty"`
 }
 
 func (m *RejectedTxs) Reset()                    { *m = RejectedTxs{} }
 func (m *RejectedTxs) String() string            { return proto.CompactTextString(m) }
 func (*RejectedTxs) ProtoMessage()               {}
-func (*RejectedTxs) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+func (*RejectedTxs) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
 
 func (m *RejectedTxs) GetRejectedTxs() []string {
 	if m != nil {
@@ -81,6 +316,13 @@ func (m *RejectedTxs) GetRejectedTxs() []string {
 	return nil
 }
 
+func (m *RejectedTxs) GetTxs() []*CheckedTx {
+	if m != nil {
+		return m.Txs
+	}
+	return nil
+}
+
 type BTCTransaction struct {
 	UserID        string                         `protobuf:"bytes,1,opt,name=userID" json:"userID,omitempty"`
 	TxID          string                         `protobuf:"bytes,2,opt,name=txID" json:"txID,omitempty"`
@@ -99,12 +341,21 @@ type BTCTransaction struct {
 	WalletsInput  []*BTCTransaction_WalletForTx  `protobuf:"bytes,15,rep,name=WalletsInput" json:"WalletsInput,omitempty"`
 	WalletsOutput []*BTCTransaction_WalletForTx  `protobuf:"bytes,16,rep,name=WalletsOutput" json:"WalletsOutput,omitempty"`
 	Resync        bool                           `protobuf:"varint,17,opt,name=resync" json:"resync,omitempty"`
+	Seq           uint64                         `protobuf:"varint,18,opt,name=seq" json:"seq,omitempty"`
+	// virtual size in vbytes, weight in weight units, fee rate in sat/vB
+	Vsize     int32                      `protobuf:"varint,19,opt,name=vsize" json:"vsize,omitempty"`
+	Weight    int32                      `protobuf:"varint,20,opt,name=weight" json:"weight,omitempty"`
+	FeeRate   float64                    `protobuf:"fixed64,21,opt,name=feeRate" json:"feeRate,omitempty"`
+	OpReturns []*BTCTransaction_OpReturn `protobuf:"bytes,22,rep,name=opReturns" json:"opReturns,omitempty"`
+	// transaction is matched by the OP_RETURN prefix only,
+	// it has no userID and wallets and is sent as OP_RETURN_TX event only
+	OpReturnMatch bool `protobuf:"varint,23,opt,name=opReturnMatch" json:"opReturnMatch,omitempty"`
 }
 
 func (m *BTCTransaction) Reset()                    { *m = BTCTransaction{} }
 func (m *BTCTransaction) String() string            { return proto.CompactTextString(m) }
 func (*BTCTransaction) ProtoMessage()               {}
-func (*BTCTransaction) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+func (*BTCTransaction) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
 
 func (m *BTCTransaction) GetUserID() string {
 	if m != nil {
@@ -225,6 +476,48 @@ func (m *BTCTransaction) GetResync() bool {
 	return false
 }
 
+func (m *BTCTransaction) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *BTCTransaction) GetVsize() int32 {
+	if m != nil {
+		return m.Vsize
+	}
+	return 0
+}
+
+func (m *BTCTransaction) GetWeight() int32 {
+	if m != nil {
+		return m.Weight
+	}
+	return 0
+}
+
+func (m *BTCTransaction) GetFeeRate() float64 {
+	if m != nil {
+		return m.FeeRate
+	}
+	return 0
+}
+
+func (m *BTCTransaction) GetOpReturns() []*BTCTransaction_OpReturn {
+	if m != nil {
+		return m.OpReturns
+	}
+	return nil
+}
+
+func (m *BTCTransaction) GetOpReturnMatch() bool {
+	if m != nil {
+		return m.OpReturnMatch
+	}
+	return false
+}
+
 type BTCTransaction_AddresAmount struct {
 	Address string `protobuf:"bytes,1,opt,name=address" json:"address,omitempty"`
 	Amount  int64  `protobuf:"varint,2,opt,name=amount" json:"amount,omitempty"`
@@ -233,7 +526,7 @@ type BTCTransaction_AddresAmount struct {
 func (m *BTCTransaction_AddresAmount) Reset()                    { *m = BTCTransaction_AddresAmount{} }
 func (m *BTCTransaction_AddresAmount) String() string            { return proto.CompactTextString(m) }
 func (*BTCTransaction_AddresAmount) ProtoMessage()               {}
-func (*BTCTransaction_AddresAmount) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2, 0} }
+func (*BTCTransaction_AddresAmount) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3, 0} }
 
 func (m *BTCTransaction_AddresAmount) GetAddress() string {
 	if m != nil {
@@ -259,7 +552,7 @@ type BTCTransaction_WalletForTx struct {
 func (m *BTCTransaction_WalletForTx) Reset()                    { *m = BTCTransaction_WalletForTx{} }
 func (m *BTCTransaction_WalletForTx) String() string            { return proto.CompactTextString(m) }
 func (*BTCTransaction_WalletForTx) ProtoMessage()               {}
-func (*BTCTransaction_WalletForTx) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2, 1} }
+func (*BTCTransaction_WalletForTx) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3, 1} }
 
 func (m *BTCTransaction_WalletForTx) GetUserid() string {
 	if m != nil {
@@ -289,6 +582,39 @@ func (m *BTCTransaction_WalletForTx) GetAmount() int64 {
 	return 0
 }
 
+// data of OP_RETURN output, text is its UTF-8 rendering
+type BTCTransaction_OpReturn struct {
+	TxOutIndex int32  `protobuf:"varint,1,opt,name=txOutIndex" json:"txOutIndex,omitempty"`
+	Data       []byte `protobuf:"bytes,2,opt,name=data" json:"data,omitempty"`
+	Text       string `protobuf:"bytes,3,opt,name=text" json:"text,omitempty"`
+}
+
+func (m *BTCTransaction_OpReturn) Reset()                    { *m = BTCTransaction_OpReturn{} }
+func (m *BTCTransaction_OpReturn) String() string            { return proto.CompactTextString(m) }
+func (*BTCTransaction_OpReturn) ProtoMessage()               {}
+func (*BTCTransaction_OpReturn) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3, 2} }
+
+func (m *BTCTransaction_OpReturn) GetTxOutIndex() int32 {
+	if m != nil {
+		return m.TxOutIndex
+	}
+	return 0
+}
+
+func (m *BTCTransaction_OpReturn) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *BTCTransaction_OpReturn) GetText() string {
+	if m != nil {
+		return m.Text
+	}
+	return ""
+}
+
 type AddSpOut struct {
 	TxID         string `protobuf:"bytes,1,opt,name=txID" json:"txID,omitempty"`
 	TxOutID      int32  `protobuf:"varint,2,opt,name=txOutID" json:"txOutID,omitempty"`
@@ -299,12 +625,13 @@ type AddSpOut struct {
 	TxStatus     int32  `protobuf:"varint,7,opt,name=txStatus" json:"txStatus,omitempty"`
 	WalletIndex  int32  `protobuf:"varint,8,opt,name=walletIndex" json:"walletIndex,omitempty"`
 	AddressIndex int32  `protobuf:"varint,9,opt,name=addressIndex" json:"addressIndex,omitempty"`
+	Seq          uint64 `protobuf:"varint,10,opt,name=seq" json:"seq,omitempty"`
 }
 
 func (m *AddSpOut) Reset()                    { *m = AddSpOut{} }
 func (m *AddSpOut) String() string            { return proto.CompactTextString(m) }
 func (*AddSpOut) ProtoMessage()               {}
-func (*AddSpOut) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+func (*AddSpOut) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
 
 func (m *AddSpOut) GetTxID() string {
 	if m != nil {
@@ -369,17 +696,25 @@ func (m *AddSpOut) GetAddressIndex() int32 {
 	return 0
 }
 
+func (m *AddSpOut) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
 type Resync struct {
 	Txs             []*BTCTransaction `protobuf:"bytes,1,rep,name=Txs" json:"Txs,omitempty"`
 	SpOuts          []*AddSpOut       `protobuf:"bytes,2,rep,name=SpOuts" json:"SpOuts,omitempty"`
 	SpOutDelete     []*ReqDeleteSpOut `protobuf:"bytes,3,rep,name=SpOutDelete" json:"SpOutDelete,omitempty"`
 	DeleteFromQueue string            `protobuf:"bytes,4,opt,name=DeleteFromQueue" json:"DeleteFromQueue,omitempty"`
+	Seq             uint64            `protobuf:"varint,5,opt,name=seq" json:"seq,omitempty"`
 }
 
 func (m *Resync) Reset()                    { *m = Resync{} }
 func (m *Resync) String() string            { return proto.CompactTextString(m) }
 func (*Resync) ProtoMessage()               {}
-func (*Resync) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+func (*Resync) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
 
 func (m *Resync) GetTxs() []*BTCTransaction {
 	if m != nil {
@@ -409,156 +744,1210 @@ func (m *Resync) GetDeleteFromQueue() string {
 	return ""
 }
 
-type BlockHeight struct {
-	Height int64 `protobuf:"varint,1,opt,name=height" json:"height,omitempty"`
+func (m *Resync) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
 }
 
-func (m *BlockHeight) Reset()                    { *m = BlockHeight{} }
-func (m *BlockHeight) String() string            { return proto.CompactTextString(m) }
-func (*BlockHeight) ProtoMessage()               {}
-func (*BlockHeight) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+// orphaned block rollback
+type BlockDisconnected struct {
+	Height      int64             `protobuf:"varint,1,opt,name=height" json:"height,omitempty"`
+	Hash        string            `protobuf:"bytes,2,opt,name=hash" json:"hash,omitempty"`
+	Txs         []*BTCTransaction `protobuf:"bytes,3,rep,name=Txs" json:"Txs,omitempty"`
+	SpOuts      []*AddSpOut       `protobuf:"bytes,4,rep,name=SpOuts" json:"SpOuts,omitempty"`
+	SpOutDelete []*ReqDeleteSpOut `protobuf:"bytes,5,rep,name=SpOutDelete" json:"SpOutDelete,omitempty"`
+	Seq         uint64            `protobuf:"varint,6,opt,name=seq" json:"seq,omitempty"`
+}
 
-func (m *BlockHeight) GetHeight() int64 {
+func (m *BlockDisconnected) Reset()                    { *m = BlockDisconnected{} }
+func (m *BlockDisconnected) String() string            { return proto.CompactTextString(m) }
+func (*BlockDisconnected) ProtoMessage()               {}
+func (*BlockDisconnected) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *BlockDisconnected) GetHeight() int64 {
 	if m != nil {
 		return m.Height
 	}
 	return 0
 }
 
-type ReqDeleteSpOut struct {
-	UserID  string `protobuf:"bytes,1,opt,name=userID" json:"userID,omitempty"`
-	TxID    string `protobuf:"bytes,2,opt,name=txID" json:"txID,omitempty"`
-	Address string `protobuf:"bytes,3,opt,name=address" json:"address,omitempty"`
+func (m *BlockDisconnected) GetHash() string {
+	if m != nil {
+		return m.Hash
+	}
+	return ""
 }
 
-func (m *ReqDeleteSpOut) Reset()                    { *m = ReqDeleteSpOut{} }
-func (m *ReqDeleteSpOut) String() string            { return proto.CompactTextString(m) }
-func (*ReqDeleteSpOut) ProtoMessage()               {}
-func (*ReqDeleteSpOut) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+func (m *BlockDisconnected) GetTxs() []*BTCTransaction {
+	if m != nil {
+		return m.Txs
+	}
+	return nil
+}
 
-func (m *ReqDeleteSpOut) GetUserID() string {
+func (m *BlockDisconnected) GetSpOuts() []*AddSpOut {
 	if m != nil {
-		return m.UserID
+		return m.SpOuts
 	}
-	return ""
+	return nil
 }
 
-func (m *ReqDeleteSpOut) GetTxID() string {
+func (m *BlockDisconnected) GetSpOutDelete() []*ReqDeleteSpOut {
+	if m != nil {
+		return m.SpOutDelete
+	}
+	return nil
+}
+
+func (m *BlockDisconnected) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+// watched mempool transaction removed because another transaction spends
+// the same outputs: replaced in mempool or conflicted by a mined one,
+// or dropped from mempool without a known replacement
+type TxConflict struct {
+	// txID is the removed transaction, byTxID spends the same outputs,
+	// it's empty for dropped transactions
+	TxID   string `protobuf:"bytes,1,opt,name=txID" json:"txID,omitempty"`
+	ByTxID string `protobuf:"bytes,2,opt,name=byTxID" json:"byTxID,omitempty"`
+	// blockHeight of byTxID, -1 if it's in mempool
+	BlockHeight int64 `protobuf:"varint,3,opt,name=blockHeight" json:"blockHeight,omitempty"`
+	// removed transaction for each wallet with a negative status
+	Txs []*BTCTransaction `protobuf:"bytes,4,rep,name=Txs" json:"Txs,omitempty"`
+	// outputs spent by the removed transaction which are spendable again
+	SpOuts []*AddSpOut `protobuf:"bytes,5,rep,name=SpOuts" json:"SpOuts,omitempty"`
+	// outputs created by the removed transaction
+	SpOutDelete []*ReqDeleteSpOut   `protobuf:"bytes,6,rep,name=SpOutDelete" json:"SpOutDelete,omitempty"`
+	Seq         uint64              `protobuf:"varint,7,opt,name=seq" json:"seq,omitempty"`
+	Reason      MempoolDeleteReason `protobuf:"varint,8,opt,name=reason,enum=btc.MempoolDeleteReason" json:"reason,omitempty"`
+}
+
+func (m *TxConflict) Reset()                    { *m = TxConflict{} }
+func (m *TxConflict) String() string            { return proto.CompactTextString(m) }
+func (*TxConflict) ProtoMessage()               {}
+func (*TxConflict) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *TxConflict) GetTxID() string {
 	if m != nil {
 		return m.TxID
 	}
 	return ""
 }
 
-func (m *ReqDeleteSpOut) GetAddress() string {
+func (m *TxConflict) GetByTxID() string {
 	if m != nil {
-		return m.Address
+		return m.ByTxID
 	}
 	return ""
 }
 
-type MempoolToDelete struct {
-	Hash string `protobuf:"bytes,1,opt,name=hash" json:"hash,omitempty"`
+func (m *TxConflict) GetBlockHeight() int64 {
+	if m != nil {
+		return m.BlockHeight
+	}
+	return 0
 }
 
-func (m *MempoolToDelete) Reset()                    { *m = MempoolToDelete{} }
-func (m *MempoolToDelete) String() string            { return proto.CompactTextString(m) }
-func (*MempoolToDelete) ProtoMessage()               {}
-func (*MempoolToDelete) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+func (m *TxConflict) GetTxs() []*BTCTransaction {
+	if m != nil {
+		return m.Txs
+	}
+	return nil
+}
 
-func (m *MempoolToDelete) GetHash() string {
+func (m *TxConflict) GetSpOuts() []*AddSpOut {
 	if m != nil {
-		return m.Hash
+		return m.SpOuts
 	}
-	return ""
+	return nil
 }
 
-type WatchAddress struct {
-	Address      string `protobuf:"bytes,1,opt,name=address" json:"address,omitempty"`
-	UserID       string `protobuf:"bytes,2,opt,name=userID" json:"userID,omitempty"`
-	WalletIndex  int32  `protobuf:"varint,3,opt,name=WalletIndex" json:"WalletIndex,omitempty"`
-	AddressIndex int32  `protobuf:"varint,4,opt,name=AddressIndex" json:"AddressIndex,omitempty"`
+func (m *TxConflict) GetSpOutDelete() []*ReqDeleteSpOut {
+	if m != nil {
+		return m.SpOutDelete
+	}
+	return nil
 }
 
-func (m *WatchAddress) Reset()                    { *m = WatchAddress{} }
-func (m *WatchAddress) String() string            { return proto.CompactTextString(m) }
-func (*WatchAddress) ProtoMessage()               {}
-func (*WatchAddress) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+func (m *TxConflict) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
 
-func (m *WatchAddress) GetAddress() string {
+func (m *TxConflict) GetReason() MempoolDeleteReason {
 	if m != nil {
-		return m.Address
+		return m.Reason
+	}
+	return MempoolDeleteReason_REASON_UNKNOWN
+}
+
+// delivery of node notifications, mode is "push" or "polling"
+type Health struct {
+	Degraded        bool   `protobuf:"varint,1,opt,name=degraded" json:"degraded,omitempty"`
+	Mode            string `protobuf:"bytes,2,opt,name=mode" json:"mode,omitempty"`
+	Reason          string `protobuf:"bytes,3,opt,name=reason" json:"reason,omitempty"`
+	Since           int64  `protobuf:"varint,4,opt,name=since" json:"since,omitempty"`
+	CheckedAt       int64  `protobuf:"varint,5,opt,name=checkedAt" json:"checkedAt,omitempty"`
+	MissedBlocks    int64  `protobuf:"varint,6,opt,name=missedBlocks" json:"missedBlocks,omitempty"`
+	MissedTxs       int64  `protobuf:"varint,7,opt,name=missedTxs" json:"missedTxs,omitempty"`
+	DeliveredHeight int64  `protobuf:"varint,8,opt,name=deliveredHeight" json:"deliveredHeight,omitempty"`
+	BestHeight      int64  `protobuf:"varint,9,opt,name=bestHeight" json:"bestHeight,omitempty"`
+}
+
+func (m *Health) Reset()                    { *m = Health{} }
+func (m *Health) String() string            { return proto.CompactTextString(m) }
+func (*Health) ProtoMessage()               {}
+func (*Health) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *Health) GetDegraded() bool {
+	if m != nil {
+		return m.Degraded
+	}
+	return false
+}
+
+func (m *Health) GetMode() string {
+	if m != nil {
+		return m.Mode
 	}
 	return ""
 }
 
-func (m *WatchAddress) GetUserID() string {
+func (m *Health) GetReason() string {
 	if m != nil {
-		return m.UserID
+		return m.Reason
 	}
 	return ""
 }
 
-func (m *WatchAddress) GetWalletIndex() int32 {
+func (m *Health) GetSince() int64 {
 	if m != nil {
-		return m.WalletIndex
+		return m.Since
 	}
 	return 0
 }
 
-func (m *WatchAddress) GetAddressIndex() int32 {
+func (m *Health) GetCheckedAt() int64 {
 	if m != nil {
-		return m.AddressIndex
+		return m.CheckedAt
 	}
 	return 0
 }
 
-type MempoolRecord struct {
-	Category int32  `protobuf:"varint,1,opt,name=category" json:"category,omitempty"`
-	HashTX   string `protobuf:"bytes,2,opt,name=hashTX" json:"hashTX,omitempty"`
+func (m *Health) GetMissedBlocks() int64 {
+	if m != nil {
+		return m.MissedBlocks
+	}
+	return 0
 }
 
-func (m *MempoolRecord) Reset()                    { *m = MempoolRecord{} }
-func (m *MempoolRecord) String() string            { return proto.CompactTextString(m) }
-func (*MempoolRecord) ProtoMessage()               {}
-func (*MempoolRecord) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+func (m *Health) GetMissedTxs() int64 {
+	if m != nil {
+		return m.MissedTxs
+	}
+	return 0
+}
 
-func (m *MempoolRecord) GetCategory() int32 {
+func (m *Health) GetDeliveredHeight() int64 {
 	if m != nil {
-		return m.Category
+		return m.DeliveredHeight
 	}
 	return 0
 }
 
-func (m *MempoolRecord) GetHashTX() string {
+func (m *Health) GetBestHeight() int64 {
 	if m != nil {
-		return m.HashTX
+		return m.BestHeight
 	}
-	return ""
+	return 0
 }
 
-type Empty struct {
+// confirmation targets in blocks, service defaults are used if empty
+type FeeEstimateRequest struct {
+	Targets []int32 `protobuf:"varint,1,rep,packed,name=targets" json:"targets,omitempty"`
 }
 
-func (m *Empty) Reset()                    { *m = Empty{} }
-func (m *Empty) String() string            { return proto.CompactTextString(m) }
-func (*Empty) ProtoMessage()               {}
-func (*Empty) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+func (m *FeeEstimateRequest) Reset()                    { *m = FeeEstimateRequest{} }
+func (m *FeeEstimateRequest) String() string            { return proto.CompactTextString(m) }
+func (*FeeEstimateRequest) ProtoMessage()               {}
+func (*FeeEstimateRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
 
-type RawTx struct {
-	Transaction string `protobuf:"bytes,1,opt,name=transaction" json:"transaction,omitempty"`
+func (m *FeeEstimateRequest) GetTargets() []int32 {
+	if m != nil {
+		return m.Targets
+	}
+	return nil
 }
 
-func (m *RawTx) Reset()                    { *m = RawTx{} }
-func (m *RawTx) String() string            { return proto.CompactTextString(m) }
-func (*RawTx) ProtoMessage()               {}
-func (*RawTx) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+// fee rates are in satoshi per virtual byte
+type FeeEstimate struct {
+	Target         int32 `protobuf:"varint,1,opt,name=target" json:"target,omitempty"`
+	FeeRate        int64 `protobuf:"varint,2,opt,name=feeRate" json:"feeRate,omitempty"`
+	MempoolFeeRate int64 `protobuf:"varint,3,opt,name=mempoolFeeRate" json:"mempoolFeeRate,omitempty"`
+	BlocksFeeRate  int64 `protobuf:"varint,4,opt,name=blocksFeeRate" json:"blocksFeeRate,omitempty"`
+	NodeFeeRate    int64 `protobuf:"varint,5,opt,name=nodeFeeRate" json:"nodeFeeRate,omitempty"`
+}
 
-func (m *RawTx) GetTransaction() string {
+func (m *FeeEstimate) Reset()                    { *m = FeeEstimate{} }
+func (m *FeeEstimate) String() string            { return proto.CompactTextString(m) }
+func (*FeeEstimate) ProtoMessage()               {}
+func (*FeeEstimate) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *FeeEstimate) GetTarget() int32 {
 	if m != nil {
-		return m.Transaction
+		return m.Target
 	}
-	return ""
+	return 0
+}
+
+func (m *FeeEstimate) GetFeeRate() int64 {
+	if m != nil {
+		return m.FeeRate
+	}
+	return 0
+}
+
+func (m *FeeEstimate) GetMempoolFeeRate() int64 {
+	if m != nil {
+		return m.MempoolFeeRate
+	}
+	return 0
+}
+
+func (m *FeeEstimate) GetBlocksFeeRate() int64 {
+	if m != nil {
+		return m.BlocksFeeRate
+	}
+	return 0
+}
+
+func (m *FeeEstimate) GetNodeFeeRate() int64 {
+	if m != nil {
+		return m.NodeFeeRate
+	}
+	return 0
+}
+
+type FeeEstimates struct {
+	Estimates    []*FeeEstimate `protobuf:"bytes,1,rep,name=estimates" json:"estimates,omitempty"`
+	MempoolSize  int64          `protobuf:"varint,2,opt,name=mempoolSize" json:"mempoolSize,omitempty"`
+	MempoolBytes int64          `protobuf:"varint,3,opt,name=mempoolBytes" json:"mempoolBytes,omitempty"`
+}
+
+func (m *FeeEstimates) Reset()                    { *m = FeeEstimates{} }
+func (m *FeeEstimates) String() string            { return proto.CompactTextString(m) }
+func (*FeeEstimates) ProtoMessage()               {}
+func (*FeeEstimates) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+func (m *FeeEstimates) GetEstimates() []*FeeEstimate {
+	if m != nil {
+		return m.Estimates
+	}
+	return nil
+}
+
+func (m *FeeEstimates) GetMempoolSize() int64 {
+	if m != nil {
+		return m.MempoolSize
+	}
+	return 0
+}
+
+func (m *FeeEstimates) GetMempoolBytes() int64 {
+	if m != nil {
+		return m.MempoolBytes
+	}
+	return 0
+}
+
+type BlockHeight struct {
+	Height int64  `protobuf:"varint,1,opt,name=height" json:"height,omitempty"`
+	Seq    uint64 `protobuf:"varint,2,opt,name=seq" json:"seq,omitempty"`
+}
+
+func (m *BlockHeight) Reset()                    { *m = BlockHeight{} }
+func (m *BlockHeight) String() string            { return proto.CompactTextString(m) }
+func (*BlockHeight) ProtoMessage()               {}
+func (*BlockHeight) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *BlockHeight) GetHeight() int64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *BlockHeight) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+type ReqDeleteSpOut struct {
+	UserID  string `protobuf:"bytes,1,opt,name=userID" json:"userID,omitempty"`
+	TxID    string `protobuf:"bytes,2,opt,name=txID" json:"txID,omitempty"`
+	Address string `protobuf:"bytes,3,opt,name=address" json:"address,omitempty"`
+	Seq     uint64 `protobuf:"varint,4,opt,name=seq" json:"seq,omitempty"`
+	// txID and txOutID are the deleted output
+	TxOutID int32 `protobuf:"varint,5,opt,name=txOutID" json:"txOutID,omitempty"`
+	// spending input, empty when the output is rolled back with its transaction
+	SpendingTxID       string `protobuf:"bytes,6,opt,name=spendingTxID" json:"spendingTxID,omitempty"`
+	SpendingInputIndex int32  `protobuf:"varint,7,opt,name=spendingInputIndex" json:"spendingInputIndex,omitempty"`
+	// spendingBlockHeight is -1 when the spending transaction is in mempool
+	SpendingBlockHeight int64 `protobuf:"varint,8,opt,name=spendingBlockHeight" json:"spendingBlockHeight,omitempty"`
+	SpentInMempool      bool  `protobuf:"varint,9,opt,name=spentInMempool" json:"spentInMempool,omitempty"`
+}
+
+func (m *ReqDeleteSpOut) Reset()                    { *m = ReqDeleteSpOut{} }
+func (m *ReqDeleteSpOut) String() string            { return proto.CompactTextString(m) }
+func (*ReqDeleteSpOut) ProtoMessage()               {}
+func (*ReqDeleteSpOut) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+func (m *ReqDeleteSpOut) GetUserID() string {
+	if m != nil {
+		return m.UserID
+	}
+	return ""
+}
+
+func (m *ReqDeleteSpOut) GetTxID() string {
+	if m != nil {
+		return m.TxID
+	}
+	return ""
+}
+
+func (m *ReqDeleteSpOut) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *ReqDeleteSpOut) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *ReqDeleteSpOut) GetTxOutID() int32 {
+	if m != nil {
+		return m.TxOutID
+	}
+	return 0
+}
+
+func (m *ReqDeleteSpOut) GetSpendingTxID() string {
+	if m != nil {
+		return m.SpendingTxID
+	}
+	return ""
+}
+
+func (m *ReqDeleteSpOut) GetSpendingInputIndex() int32 {
+	if m != nil {
+		return m.SpendingInputIndex
+	}
+	return 0
+}
+
+func (m *ReqDeleteSpOut) GetSpendingBlockHeight() int64 {
+	if m != nil {
+		return m.SpendingBlockHeight
+	}
+	return 0
+}
+
+func (m *ReqDeleteSpOut) GetSpentInMempool() bool {
+	if m != nil {
+		return m.SpentInMempool
+	}
+	return false
+}
+
+type MempoolToDelete struct {
+	Hash   string              `protobuf:"bytes,1,opt,name=hash" json:"hash,omitempty"`
+	Seq    uint64              `protobuf:"varint,2,opt,name=seq" json:"seq,omitempty"`
+	Reason MempoolDeleteReason `protobuf:"varint,3,opt,name=reason,enum=btc.MempoolDeleteReason" json:"reason,omitempty"`
+}
+
+func (m *MempoolToDelete) Reset()                    { *m = MempoolToDelete{} }
+func (m *MempoolToDelete) String() string            { return proto.CompactTextString(m) }
+func (*MempoolToDelete) ProtoMessage()               {}
+func (*MempoolToDelete) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *MempoolToDelete) GetHash() string {
+	if m != nil {
+		return m.Hash
+	}
+	return ""
+}
+
+func (m *MempoolToDelete) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *MempoolToDelete) GetReason() MempoolDeleteReason {
+	if m != nil {
+		return m.Reason
+	}
+	return MempoolDeleteReason_REASON_UNKNOWN
+}
+
+type WatchAddress struct {
+	Address      string `protobuf:"bytes,1,opt,name=address" json:"address,omitempty"`
+	UserID       string `protobuf:"bytes,2,opt,name=userID" json:"userID,omitempty"`
+	WalletIndex  int32  `protobuf:"varint,3,opt,name=WalletIndex" json:"WalletIndex,omitempty"`
+	AddressIndex int32  `protobuf:"varint,4,opt,name=AddressIndex" json:"AddressIndex,omitempty"`
+}
+
+func (m *WatchAddress) Reset()                    { *m = WatchAddress{} }
+func (m *WatchAddress) String() string            { return proto.CompactTextString(m) }
+func (*WatchAddress) ProtoMessage()               {}
+func (*WatchAddress) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+func (m *WatchAddress) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *WatchAddress) GetUserID() string {
+	if m != nil {
+		return m.UserID
+	}
+	return ""
+}
+
+func (m *WatchAddress) GetWalletIndex() int32 {
+	if m != nil {
+		return m.WalletIndex
+	}
+	return 0
+}
+
+func (m *WatchAddress) GetAddressIndex() int32 {
+	if m != nil {
+		return m.AddressIndex
+	}
+	return 0
+}
+
+type MempoolRecord struct {
+	// category is the package fee rate in sat/vB rounded to integer,
+	// a record is sent again when a child raises it
+	Category int32  `protobuf:"varint,1,opt,name=category" json:"category,omitempty"`
+	HashTX   string `protobuf:"bytes,2,opt,name=hashTX" json:"hashTX,omitempty"`
+	Seq      uint64 `protobuf:"varint,3,opt,name=seq" json:"seq,omitempty"`
+	Vsize    int32  `protobuf:"varint,4,opt,name=vsize" json:"vsize,omitempty"`
+	Weight   int32  `protobuf:"varint,5,opt,name=weight" json:"weight,omitempty"`
+	// feeRate is the rate of the transaction alone
+	FeeRate float64 `protobuf:"fixed64,6,opt,name=feeRate" json:"feeRate,omitempty"`
+	// packageFeeRate is the effective rate with unconfirmed ancestors and descendants paying for it
+	PackageFeeRate float64 `protobuf:"fixed64,7,opt,name=packageFeeRate" json:"packageFeeRate,omitempty"`
+}
+
+func (m *MempoolRecord) Reset()                    { *m = MempoolRecord{} }
+func (m *MempoolRecord) String() string            { return proto.CompactTextString(m) }
+func (*MempoolRecord) ProtoMessage()               {}
+func (*MempoolRecord) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *MempoolRecord) GetCategory() int32 {
+	if m != nil {
+		return m.Category
+	}
+	return 0
+}
+
+func (m *MempoolRecord) GetHashTX() string {
+	if m != nil {
+		return m.HashTX
+	}
+	return ""
+}
+
+func (m *MempoolRecord) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *MempoolRecord) GetVsize() int32 {
+	if m != nil {
+		return m.Vsize
+	}
+	return 0
+}
+
+func (m *MempoolRecord) GetWeight() int32 {
+	if m != nil {
+		return m.Weight
+	}
+	return 0
+}
+
+func (m *MempoolRecord) GetFeeRate() float64 {
+	if m != nil {
+		return m.FeeRate
+	}
+	return 0
+}
+
+func (m *MempoolRecord) GetPackageFeeRate() float64 {
+	if m != nil {
+		return m.PackageFeeRate
+	}
+	return 0
+}
+
+type Empty struct {
+}
+
+func (m *Empty) Reset()                    { *m = Empty{} }
+func (m *Empty) String() string            { return proto.CompactTextString(m) }
+func (*Empty) ProtoMessage()               {}
+func (*Empty) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+// all events in one ordered stream
+// empty kinds or ALL means every kind
+// fromStart sends all kept events ignoring since
+type Subscription struct {
+	Since     uint64      `protobuf:"varint,1,opt,name=since" json:"since,omitempty"`
+	Kinds     []EventKind `protobuf:"varint,2,rep,packed,name=kinds,enum=btc.EventKind" json:"kinds,omitempty"`
+	FromStart bool        `protobuf:"varint,3,opt,name=fromStart" json:"fromStart,omitempty"`
+}
+
+func (m *Subscription) Reset()                    { *m = Subscription{} }
+func (m *Subscription) String() string            { return proto.CompactTextString(m) }
+func (*Subscription) ProtoMessage()               {}
+func (*Subscription) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+
+func (m *Subscription) GetSince() uint64 {
+	if m != nil {
+		return m.Since
+	}
+	return 0
+}
+
+func (m *Subscription) GetKinds() []EventKind {
+	if m != nil {
+		return m.Kinds
+	}
+	return nil
+}
+
+func (m *Subscription) GetFromStart() bool {
+	if m != nil {
+		return m.FromStart
+	}
+	return false
+}
+
+type Event struct {
+	Seq uint64 `protobuf:"varint,1,opt,name=seq" json:"seq,omitempty"`
+	// Types that are valid to be assigned to Payload:
+	//	*Event_Tx
+	//	*Event_AddSpOut
+	//	*Event_DeleteSpOut
+	//	*Event_AddMempool
+	//	*Event_DeleteMempool
+	//	*Event_Block
+	//	*Event_BlockDisconnected
+	//	*Event_Resync
+	//	*Event_TxReplaced
+	//	*Event_TxConflicted
+	//	*Event_TxDropped
+	//	*Event_OpReturnTx
+	Payload isEvent_Payload `protobuf_oneof:"payload"`
+}
+
+func (m *Event) Reset()                    { *m = Event{} }
+func (m *Event) String() string            { return proto.CompactTextString(m) }
+func (*Event) ProtoMessage()               {}
+func (*Event) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+
+type isEvent_Payload interface {
+	isEvent_Payload()
+}
+
+type Event_Tx struct {
+	Tx *BTCTransaction `protobuf:"bytes,2,opt,name=tx,oneof"`
+}
+type Event_AddSpOut struct {
+	AddSpOut *AddSpOut `protobuf:"bytes,3,opt,name=addSpOut,oneof"`
+}
+type Event_DeleteSpOut struct {
+	DeleteSpOut *ReqDeleteSpOut `protobuf:"bytes,4,opt,name=deleteSpOut,oneof"`
+}
+type Event_AddMempool struct {
+	AddMempool *MempoolRecord `protobuf:"bytes,5,opt,name=addMempool,oneof"`
+}
+type Event_DeleteMempool struct {
+	DeleteMempool *MempoolToDelete `protobuf:"bytes,6,opt,name=deleteMempool,oneof"`
+}
+type Event_Block struct {
+	Block *BlockHeight `protobuf:"bytes,7,opt,name=block,oneof"`
+}
+type Event_BlockDisconnected struct {
+	BlockDisconnected *BlockDisconnected `protobuf:"bytes,8,opt,name=blockDisconnected,oneof"`
+}
+type Event_Resync struct {
+	Resync *Resync `protobuf:"bytes,9,opt,name=resync,oneof"`
+}
+type Event_TxReplaced struct {
+	TxReplaced *TxConflict `protobuf:"bytes,10,opt,name=txReplaced,oneof"`
+}
+type Event_TxConflicted struct {
+	TxConflicted *TxConflict `protobuf:"bytes,11,opt,name=txConflicted,oneof"`
+}
+type Event_TxDropped struct {
+	TxDropped *TxConflict `protobuf:"bytes,12,opt,name=txDropped,oneof"`
+}
+type Event_OpReturnTx struct {
+	OpReturnTx *BTCTransaction `protobuf:"bytes,13,opt,name=opReturnTx,oneof"`
+}
+
+func (*Event_Tx) isEvent_Payload()                {}
+func (*Event_AddSpOut) isEvent_Payload()          {}
+func (*Event_DeleteSpOut) isEvent_Payload()       {}
+func (*Event_AddMempool) isEvent_Payload()        {}
+func (*Event_DeleteMempool) isEvent_Payload()     {}
+func (*Event_Block) isEvent_Payload()             {}
+func (*Event_BlockDisconnected) isEvent_Payload() {}
+func (*Event_Resync) isEvent_Payload()            {}
+func (*Event_TxReplaced) isEvent_Payload()        {}
+func (*Event_TxConflicted) isEvent_Payload()      {}
+func (*Event_TxDropped) isEvent_Payload()         {}
+func (*Event_OpReturnTx) isEvent_Payload()        {}
+
+func (m *Event) GetPayload() isEvent_Payload {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (m *Event) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *Event) GetTx() *BTCTransaction {
+	if x, ok := m.GetPayload().(*Event_Tx); ok {
+		return x.Tx
+	}
+	return nil
+}
+
+func (m *Event) GetAddSpOut() *AddSpOut {
+	if x, ok := m.GetPayload().(*Event_AddSpOut); ok {
+		return x.AddSpOut
+	}
+	return nil
+}
+
+func (m *Event) GetDeleteSpOut() *ReqDeleteSpOut {
+	if x, ok := m.GetPayload().(*Event_DeleteSpOut); ok {
+		return x.DeleteSpOut
+	}
+	return nil
+}
+
+func (m *Event) GetAddMempool() *MempoolRecord {
+	if x, ok := m.GetPayload().(*Event_AddMempool); ok {
+		return x.AddMempool
+	}
+	return nil
+}
+
+func (m *Event) GetDeleteMempool() *MempoolToDelete {
+	if x, ok := m.GetPayload().(*Event_DeleteMempool); ok {
+		return x.DeleteMempool
+	}
+	return nil
+}
+
+func (m *Event) GetBlock() *BlockHeight {
+	if x, ok := m.GetPayload().(*Event_Block); ok {
+		return x.Block
+	}
+	return nil
+}
+
+func (m *Event) GetBlockDisconnected() *BlockDisconnected {
+	if x, ok := m.GetPayload().(*Event_BlockDisconnected); ok {
+		return x.BlockDisconnected
+	}
+	return nil
+}
+
+func (m *Event) GetResync() *Resync {
+	if x, ok := m.GetPayload().(*Event_Resync); ok {
+		return x.Resync
+	}
+	return nil
+}
+
+func (m *Event) GetTxReplaced() *TxConflict {
+	if x, ok := m.GetPayload().(*Event_TxReplaced); ok {
+		return x.TxReplaced
+	}
+	return nil
+}
+
+func (m *Event) GetTxConflicted() *TxConflict {
+	if x, ok := m.GetPayload().(*Event_TxConflicted); ok {
+		return x.TxConflicted
+	}
+	return nil
+}
+
+func (m *Event) GetTxDropped() *TxConflict {
+	if x, ok := m.GetPayload().(*Event_TxDropped); ok {
+		return x.TxDropped
+	}
+	return nil
+}
+
+func (m *Event) GetOpReturnTx() *BTCTransaction {
+	if x, ok := m.GetPayload().(*Event_OpReturnTx); ok {
+		return x.OpReturnTx
+	}
+	return nil
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*Event) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _Event_OneofMarshaler, _Event_OneofUnmarshaler, _Event_OneofSizer, []interface{}{
+		(*Event_Tx)(nil),
+		(*Event_AddSpOut)(nil),
+		(*Event_DeleteSpOut)(nil),
+		(*Event_AddMempool)(nil),
+		(*Event_DeleteMempool)(nil),
+		(*Event_Block)(nil),
+		(*Event_BlockDisconnected)(nil),
+		(*Event_Resync)(nil),
+		(*Event_TxReplaced)(nil),
+		(*Event_TxConflicted)(nil),
+		(*Event_TxDropped)(nil),
+		(*Event_OpReturnTx)(nil),
+	}
+}
+
+func _Event_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*Event)
+	// payload
+	switch x := m.Payload.(type) {
+	case *Event_Tx:
+		b.EncodeVarint(2<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Tx); err != nil {
+			return err
+		}
+	case *Event_AddSpOut:
+		b.EncodeVarint(3<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.AddSpOut); err != nil {
+			return err
+		}
+	case *Event_DeleteSpOut:
+		b.EncodeVarint(4<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.DeleteSpOut); err != nil {
+			return err
+		}
+	case *Event_AddMempool:
+		b.EncodeVarint(5<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.AddMempool); err != nil {
+			return err
+		}
+	case *Event_DeleteMempool:
+		b.EncodeVarint(6<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.DeleteMempool); err != nil {
+			return err
+		}
+	case *Event_Block:
+		b.EncodeVarint(7<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Block); err != nil {
+			return err
+		}
+	case *Event_BlockDisconnected:
+		b.EncodeVarint(8<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.BlockDisconnected); err != nil {
+			return err
+		}
+	case *Event_Resync:
+		b.EncodeVarint(9<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Resync); err != nil {
+			return err
+		}
+	case *Event_TxReplaced:
+		b.EncodeVarint(10<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.TxReplaced); err != nil {
+			return err
+		}
+	case *Event_TxConflicted:
+		b.EncodeVarint(11<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.TxConflicted); err != nil {
+			return err
+		}
+	case *Event_TxDropped:
+		b.EncodeVarint(12<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.TxDropped); err != nil {
+			return err
+		}
+	case *Event_OpReturnTx:
+		b.EncodeVarint(13<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.OpReturnTx); err != nil {
+			return err
+		}
+	case nil:
+	default:
+		return fmt.Errorf("Event.Payload has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _Event_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*Event)
+	switch tag {
+	case 2: // payload.tx
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(BTCTransaction)
+		err := b.DecodeMessage(msg)
+		m.Payload = &Event_Tx{msg}
+		return true, err
+	case 3: // payload.addSpOut
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(AddSpOut)
+		err := b.DecodeMessage(msg)
+		m.Payload = &Event_AddSpOut{msg}
+		return true, err
+	case 4: // payload.deleteSpOut
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(ReqDeleteSpOut)
+		err := b.DecodeMessage(msg)
+		m.Payload = &Event_DeleteSpOut{msg}
+		return true, err
+	case 5: // payload.addMempool
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(MempoolRecord)
+		err := b.DecodeMessage(msg)
+		m.Payload = &Event_AddMempool{msg}
+		return true, err
+	case 6: // payload.deleteMempool
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(MempoolToDelete)
+		err := b.DecodeMessage(msg)
+		m.Payload = &Event_DeleteMempool{msg}
+		return true, err
+	case 7: // payload.block
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(BlockHeight)
+		err := b.DecodeMessage(msg)
+		m.Payload = &Event_Block{msg}
+		return true, err
+	case 8: // payload.blockDisconnected
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(BlockDisconnected)
+		err := b.DecodeMessage(msg)
+		m.Payload = &Event_BlockDisconnected{msg}
+		return true, err
+	case 9: // payload.resync
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Resync)
+		err := b.DecodeMessage(msg)
+		m.Payload = &Event_Resync{msg}
+		return true, err
+	case 10: // payload.txReplaced
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(TxConflict)
+		err := b.DecodeMessage(msg)
+		m.Payload = &Event_TxReplaced{msg}
+		return true, err
+	case 11: // payload.txConflicted
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(TxConflict)
+		err := b.DecodeMessage(msg)
+		m.Payload = &Event_TxConflicted{msg}
+		return true, err
+	case 12: // payload.txDropped
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(TxConflict)
+		err := b.DecodeMessage(msg)
+		m.Payload = &Event_TxDropped{msg}
+		return true, err
+	case 13: // payload.opReturnTx
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(BTCTransaction)
+		err := b.DecodeMessage(msg)
+		m.Payload = &Event_OpReturnTx{msg}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _Event_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*Event)
+	// payload
+	switch x := m.Payload.(type) {
+	case *Event_Tx:
+		s := proto.Size(x.Tx)
+		n += proto.SizeVarint(2<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Event_AddSpOut:
+		s := proto.Size(x.AddSpOut)
+		n += proto.SizeVarint(3<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Event_DeleteSpOut:
+		s := proto.Size(x.DeleteSpOut)
+		n += proto.SizeVarint(4<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Event_AddMempool:
+		s := proto.Size(x.AddMempool)
+		n += proto.SizeVarint(5<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Event_DeleteMempool:
+		s := proto.Size(x.DeleteMempool)
+		n += proto.SizeVarint(6<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Event_Block:
+		s := proto.Size(x.Block)
+		n += proto.SizeVarint(7<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Event_BlockDisconnected:
+		s := proto.Size(x.BlockDisconnected)
+		n += proto.SizeVarint(8<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Event_Resync:
+		s := proto.Size(x.Resync)
+		n += proto.SizeVarint(9<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Event_TxReplaced:
+		s := proto.Size(x.TxReplaced)
+		n += proto.SizeVarint(10<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Event_TxConflicted:
+		s := proto.Size(x.TxConflicted)
+		n += proto.SizeVarint(11<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Event_TxDropped:
+		s := proto.Size(x.TxDropped)
+		n += proto.SizeVarint(12<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Event_OpReturnTx:
+		s := proto.Size(x.OpReturnTx)
+		n += proto.SizeVarint(13<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+// stream position, since is the seq of the last event received by the client
+// zero since means only new events, fromStart sends all kept events
+// streams fail with OUT_OF_RANGE if events after since are not kept anymore
+type Cursor struct {
+	Since     uint64 `protobuf:"varint,1,opt,name=since" json:"since,omitempty"`
+	FromStart bool   `protobuf:"varint,2,opt,name=fromStart" json:"fromStart,omitempty"`
+}
+
+func (m *Cursor) Reset()                    { *m = Cursor{} }
+func (m *Cursor) String() string            { return proto.CompactTextString(m) }
+func (*Cursor) ProtoMessage()               {}
+func (*Cursor) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
+func (m *Cursor) GetSince() uint64 {
+	if m != nil {
+		return m.Since
+	}
+	return 0
+}
+
+func (m *Cursor) GetFromStart() bool {
+	if m != nil {
+		return m.FromStart
+	}
+	return false
+}
+
+type RawTx struct {
+	Transaction string `protobuf:"bytes,1,opt,name=transaction" json:"transaction,omitempty"`
+	// dryRun only validates the transaction
+	DryRun bool `protobuf:"varint,2,opt,name=dryRun" json:"dryRun,omitempty"`
+}
+
+func (m *RawTx) Reset()                    { *m = RawTx{} }
+func (m *RawTx) String() string            { return proto.CompactTextString(m) }
+func (*RawTx) ProtoMessage()               {}
+func (*RawTx) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+
+func (m *RawTx) GetTransaction() string {
+	if m != nil {
+		return m.Transaction
+	}
+	return ""
+}
+
+func (m *RawTx) GetDryRun() bool {
+	if m != nil {
+		return m.DryRun
+	}
+	return false
+}
+
+type SendTxProblem struct {
+	Reason SendTxReason `protobuf:"varint,1,opt,name=reason,enum=btc.SendTxReason" json:"reason,omitempty"`
+	// index of the input or output, -1 for the whole transaction
+	Index   int32  `protobuf:"varint,2,opt,name=index" json:"index,omitempty"`
+	Message string `protobuf:"bytes,3,opt,name=message" json:"message,omitempty"`
+}
+
+func (m *SendTxProblem) Reset()                    { *m = SendTxProblem{} }
+func (m *SendTxProblem) String() string            { return proto.CompactTextString(m) }
+func (*SendTxProblem) ProtoMessage()               {}
+func (*SendTxProblem) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+
+func (m *SendTxProblem) GetReason() SendTxReason {
+	if m != nil {
+		return m.Reason
+	}
+	return SendTxReason_SEND_UNKNOWN
+}
+
+func (m *SendTxProblem) GetIndex() int32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *SendTxProblem) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+// reply of EventSendRawTx, message is compatible with ReplyInfo
+type SendRawTxReply struct {
+	// message is the txid of the sent transaction or "err: " with the first problem,
+	// a rejected broadcast is an error with the reply in status details
+	Message string `protobuf:"bytes,1,opt,name=message" json:"message,omitempty"`
+	TxID    string `protobuf:"bytes,2,opt,name=txID" json:"txID,omitempty"`
+	// fee in satoshi and feeRate are zero if some inputs are not found
+	Fee      int64            `protobuf:"varint,3,opt,name=fee" json:"fee,omitempty"`
+	Vsize    int32            `protobuf:"varint,4,opt,name=vsize" json:"vsize,omitempty"`
+	FeeRate  float64          `protobuf:"fixed64,5,opt,name=feeRate" json:"feeRate,omitempty"`
+	Problems []*SendTxProblem `protobuf:"bytes,6,rep,name=problems" json:"problems,omitempty"`
+	Sent     bool             `protobuf:"varint,7,opt,name=sent" json:"sent,omitempty"`
+}
+
+func (m *SendRawTxReply) Reset()                    { *m = SendRawTxReply{} }
+func (m *SendRawTxReply) String() string            { return proto.CompactTextString(m) }
+func (*SendRawTxReply) ProtoMessage()               {}
+func (*SendRawTxReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+
+func (m *SendRawTxReply) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *SendRawTxReply) GetTxID() string {
+	if m != nil {
+		return m.TxID
+	}
+	return ""
+}
+
+func (m *SendRawTxReply) GetFee() int64 {
+	if m != nil {
+		return m.Fee
+	}
+	return 0
+}
+
+func (m *SendRawTxReply) GetVsize() int32 {
+	if m != nil {
+		return m.Vsize
+	}
+	return 0
+}
+
+func (m *SendRawTxReply) GetFeeRate() float64 {
+	if m != nil {
+		return m.FeeRate
+	}
+	return 0
+}
+
+func (m *SendRawTxReply) GetProblems() []*SendTxProblem {
+	if m != nil {
+		return m.Problems
+	}
+	return nil
+}
+
+func (m *SendRawTxReply) GetSent() bool {
+	if m != nil {
+		return m.Sent
+	}
+	return false
 }
 
 type AddressToResync struct {
@@ -571,7 +1960,7 @@ type AddressToResync struct {
 func (m *AddressToResync) Reset()                    { *m = AddressToResync{} }
 func (m *AddressToResync) String() string            { return proto.CompactTextString(m) }
 func (*AddressToResync) ProtoMessage()               {}
-func (*AddressToResync) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+func (*AddressToResync) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
 
 func (m *AddressToResync) GetAddress() string {
 	if m != nil {
@@ -608,7 +1997,7 @@ type UsersData struct {
 func (m *UsersData) Reset()                    { *m = UsersData{} }
 func (m *UsersData) String() string            { return proto.CompactTextString(m) }
 func (*UsersData) ProtoMessage()               {}
-func (*UsersData) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+func (*UsersData) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
 
 func (m *UsersData) GetMap() map[string]*AddressExtended {
 	if m != nil {
@@ -626,7 +2015,7 @@ type AddressExtended struct {
 func (m *AddressExtended) Reset()                    { *m = AddressExtended{} }
 func (m *AddressExtended) String() string            { return proto.CompactTextString(m) }
 func (*AddressExtended) ProtoMessage()               {}
-func (*AddressExtended) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+func (*AddressExtended) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
 
 func (m *AddressExtended) GetUserID() string {
 	if m != nil {
@@ -656,7 +2045,7 @@ type ReplyInfo struct {
 func (m *ReplyInfo) Reset()                    { *m = ReplyInfo{} }
 func (m *ReplyInfo) String() string            { return proto.CompactTextString(m) }
 func (*ReplyInfo) ProtoMessage()               {}
-func (*ReplyInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+func (*ReplyInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
 
 func (m *ReplyInfo) GetMessage() string {
 	if m != nil {
@@ -675,7 +2064,7 @@ type ServiceVersion struct {
 func (m *ServiceVersion) Reset()                    { *m = ServiceVersion{} }
 func (m *ServiceVersion) String() string            { return proto.CompactTextString(m) }
 func (*ServiceVersion) ProtoMessage()               {}
-func (*ServiceVersion) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+func (*ServiceVersion) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{28} }
 
 func (m *ServiceVersion) GetBranch() string {
 	if m != nil {
@@ -707,24 +2096,41 @@ func (m *ServiceVersion) GetLasttag() string {
 
 func init() {
 	proto.RegisterType((*TxsToCheck)(nil), "btc.TxsToCheck")
+	proto.RegisterType((*CheckedTx)(nil), "btc.CheckedTx")
 	proto.RegisterType((*RejectedTxs)(nil), "btc.RejectedTxs")
 	proto.RegisterType((*BTCTransaction)(nil), "btc.BTCTransaction")
 	proto.RegisterType((*BTCTransaction_AddresAmount)(nil), "btc.BTCTransaction.AddresAmount")
 	proto.RegisterType((*BTCTransaction_WalletForTx)(nil), "btc.BTCTransaction.WalletForTx")
+	proto.RegisterType((*BTCTransaction_OpReturn)(nil), "btc.BTCTransaction.OpReturn")
 	proto.RegisterType((*AddSpOut)(nil), "btc.AddSpOut")
 	proto.RegisterType((*Resync)(nil), "btc.Resync")
+	proto.RegisterType((*BlockDisconnected)(nil), "btc.BlockDisconnected")
+	proto.RegisterType((*TxConflict)(nil), "btc.TxConflict")
+	proto.RegisterType((*Health)(nil), "btc.Health")
+	proto.RegisterType((*FeeEstimateRequest)(nil), "btc.FeeEstimateRequest")
+	proto.RegisterType((*FeeEstimate)(nil), "btc.FeeEstimate")
+	proto.RegisterType((*FeeEstimates)(nil), "btc.FeeEstimates")
 	proto.RegisterType((*BlockHeight)(nil), "btc.BlockHeight")
 	proto.RegisterType((*ReqDeleteSpOut)(nil), "btc.ReqDeleteSpOut")
 	proto.RegisterType((*MempoolToDelete)(nil), "btc.MempoolToDelete")
 	proto.RegisterType((*WatchAddress)(nil), "btc.WatchAddress")
 	proto.RegisterType((*MempoolRecord)(nil), "btc.MempoolRecord")
 	proto.RegisterType((*Empty)(nil), "btc.Empty")
+	proto.RegisterType((*Subscription)(nil), "btc.Subscription")
+	proto.RegisterType((*Event)(nil), "btc.Event")
+	proto.RegisterType((*Cursor)(nil), "btc.Cursor")
 	proto.RegisterType((*RawTx)(nil), "btc.RawTx")
+	proto.RegisterType((*SendTxProblem)(nil), "btc.SendTxProblem")
+	proto.RegisterType((*SendRawTxReply)(nil), "btc.SendRawTxReply")
 	proto.RegisterType((*AddressToResync)(nil), "btc.AddressToResync")
 	proto.RegisterType((*UsersData)(nil), "btc.UsersData")
 	proto.RegisterType((*AddressExtended)(nil), "btc.AddressExtended")
 	proto.RegisterType((*ReplyInfo)(nil), "btc.ReplyInfo")
 	proto.RegisterType((*ServiceVersion)(nil), "btc.ServiceVersion")
+	proto.RegisterEnum("btc.TxCheckStatus", TxCheckStatus_name, TxCheckStatus_value)
+	proto.RegisterEnum("btc.MempoolDeleteReason", MempoolDeleteReason_name, MempoolDeleteReason_value)
+	proto.RegisterEnum("btc.EventKind", EventKind_name, EventKind_value)
+	proto.RegisterEnum("btc.SendTxReason", SendTxReason_name, SendTxReason_value)
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -744,16 +2150,23 @@ type NodeCommunicationsClient interface {
 	EventAddNewAddress(ctx context.Context, in *WatchAddress, opts ...grpc.CallOption) (*ReplyInfo, error)
 	EventGetBlockHeight(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*BlockHeight, error)
 	EventGetAllMempool(ctx context.Context, in *Empty, opts ...grpc.CallOption) (NodeCommunications_EventGetAllMempoolClient, error)
-	EventAddMempoolRecord(ctx context.Context, in *Empty, opts ...grpc.CallOption) (NodeCommunications_EventAddMempoolRecordClient, error)
-	EventDeleteMempool(ctx context.Context, in *Empty, opts ...grpc.CallOption) (NodeCommunications_EventDeleteMempoolClient, error)
+	EventAddMempoolRecord(ctx context.Context, in *Cursor, opts ...grpc.CallOption) (NodeCommunications_EventAddMempoolRecordClient, error)
+	EventDeleteMempool(ctx context.Context, in *Cursor, opts ...grpc.CallOption) (NodeCommunications_EventDeleteMempoolClient, error)
 	EventResyncAddress(ctx context.Context, in *AddressToResync, opts ...grpc.CallOption) (*ReplyInfo, error)
-	EventSendRawTx(ctx context.Context, in *RawTx, opts ...grpc.CallOption) (*ReplyInfo, error)
-	EventDeleteSpendableOut(ctx context.Context, in *Empty, opts ...grpc.CallOption) (NodeCommunications_EventDeleteSpendableOutClient, error)
-	EventNewBlock(ctx context.Context, in *Empty, opts ...grpc.CallOption) (NodeCommunications_EventNewBlockClient, error)
-	EventAddSpendableOut(ctx context.Context, in *Empty, opts ...grpc.CallOption) (NodeCommunications_EventAddSpendableOutClient, error)
-	NewTx(ctx context.Context, in *Empty, opts ...grpc.CallOption) (NodeCommunications_NewTxClient, error)
-	ResyncAddress(ctx context.Context, in *Empty, opts ...grpc.CallOption) (NodeCommunications_ResyncAddressClient, error)
+	EventSendRawTx(ctx context.Context, in *RawTx, opts ...grpc.CallOption) (*SendRawTxReply, error)
+	EventDeleteSpendableOut(ctx context.Context, in *Cursor, opts ...grpc.CallOption) (NodeCommunications_EventDeleteSpendableOutClient, error)
+	EventNewBlock(ctx context.Context, in *Cursor, opts ...grpc.CallOption) (NodeCommunications_EventNewBlockClient, error)
+	EventAddSpendableOut(ctx context.Context, in *Cursor, opts ...grpc.CallOption) (NodeCommunications_EventAddSpendableOutClient, error)
+	NewTx(ctx context.Context, in *Cursor, opts ...grpc.CallOption) (NodeCommunications_NewTxClient, error)
+	ResyncAddress(ctx context.Context, in *Cursor, opts ...grpc.CallOption) (NodeCommunications_ResyncAddressClient, error)
 	CheckRejectTxs(ctx context.Context, in *TxsToCheck, opts ...grpc.CallOption) (*RejectedTxs, error)
+	EventBlockDisconnected(ctx context.Context, in *Cursor, opts ...grpc.CallOption) (NodeCommunications_EventBlockDisconnectedClient, error)
+	Subscribe(ctx context.Context, in *Subscription, opts ...grpc.CallOption) (NodeCommunications_SubscribeClient, error)
+	EstimateFee(ctx context.Context, in *FeeEstimateRequest, opts ...grpc.CallOption) (*FeeEstimates, error)
+	NodeHealth(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Health, error)
+	EventTxReplaced(ctx context.Context, in *Cursor, opts ...grpc.CallOption) (NodeCommunications_EventTxReplacedClient, error)
+	EventTxConflicted(ctx context.Context, in *Cursor, opts ...grpc.CallOption) (NodeCommunications_EventTxConflictedClient, error)
+	EventTxDropped(ctx context.Context, in *Cursor, opts ...grpc.CallOption) (NodeCommunications_EventTxDroppedClient, error)
 }
 
 type nodeCommunicationsClient struct {
@@ -841,7 +2254,7 @@ func (x *nodeCommunicationsEventGetAllMempoolClient) Recv() (*MempoolRecord, err
 	return m, nil
 }
 
-func (c *nodeCommunicationsClient) EventAddMempoolRecord(ctx context.Context, in *Empty, opts ...grpc.CallOption) (NodeCommunications_EventAddMempoolRecordClient, error) {
+func (c *nodeCommunicationsClient) EventAddMempoolRecord(ctx context.Context, in *Cursor, opts ...grpc.CallOption) (NodeCommunications_EventAddMempoolRecordClient, error) {
 	stream, err := grpc.NewClientStream(ctx, &_NodeCommunications_serviceDesc.Streams[1], c.cc, "/btc.NodeCommunications/EventAddMempoolRecord", opts...)
 	if err != nil {
 		return nil, err
@@ -873,7 +2286,7 @@ func (x *nodeCommunicationsEventAddMempoolRecordClient) Recv() (*MempoolRecord,
 	return m, nil
 }
 
-func (c *nodeCommunicationsClient) EventDeleteMempool(ctx context.Context, in *Empty, opts ...grpc.CallOption) (NodeCommunications_EventDeleteMempoolClient, error) {
+func (c *nodeCommunicationsClient) EventDeleteMempool(ctx context.Context, in *Cursor, opts ...grpc.CallOption) (NodeCommunications_EventDeleteMempoolClient, error) {
 	stream, err := grpc.NewClientStream(ctx, &_NodeCommunications_serviceDesc.Streams[2], c.cc, "/btc.NodeCommunications/EventDeleteMempool", opts...)
 	if err != nil {
 		return nil, err
@@ -914,8 +2327,8 @@ func (c *nodeCommunicationsClient) EventResyncAddress(ctx context.Context, in *A
 	return out, nil
 }
 
-func (c *nodeCommunicationsClient) EventSendRawTx(ctx context.Context, in *RawTx, opts ...grpc.CallOption) (*ReplyInfo, error) {
-	out := new(ReplyInfo)
+func (c *nodeCommunicationsClient) EventSendRawTx(ctx context.Context, in *RawTx, opts ...grpc.CallOption) (*SendRawTxReply, error) {
+	out := new(SendRawTxReply)
 	err := grpc.Invoke(ctx, "/btc.NodeCommunications/EventSendRawTx", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
@@ -923,7 +2336,7 @@ func (c *nodeCommunicationsClient) EventSendRawTx(ctx context.Context, in *RawTx
 	return out, nil
 }
 
-func (c *nodeCommunicationsClient) EventDeleteSpendableOut(ctx context.Context, in *Empty, opts ...grpc.CallOption) (NodeCommunications_EventDeleteSpendableOutClient, error) {
+func (c *nodeCommunicationsClient) EventDeleteSpendableOut(ctx context.Context, in *Cursor, opts ...grpc.CallOption) (NodeCommunications_EventDeleteSpendableOutClient, error) {
 	stream, err := grpc.NewClientStream(ctx, &_NodeCommunications_serviceDesc.Streams[3], c.cc, "/btc.NodeCommunications/EventDeleteSpendableOut", opts...)
 	if err != nil {
 		return nil, err
@@ -955,7 +2368,7 @@ func (x *nodeCommunicationsEventDeleteSpendableOutClient) Recv() (*ReqDeleteSpOu
 	return m, nil
 }
 
-func (c *nodeCommunicationsClient) EventNewBlock(ctx context.Context, in *Empty, opts ...grpc.CallOption) (NodeCommunications_EventNewBlockClient, error) {
+func (c *nodeCommunicationsClient) EventNewBlock(ctx context.Context, in *Cursor, opts ...grpc.CallOption) (NodeCommunications_EventNewBlockClient, error) {
 	stream, err := grpc.NewClientStream(ctx, &_NodeCommunications_serviceDesc.Streams[4], c.cc, "/btc.NodeCommunications/EventNewBlock", opts...)
 	if err != nil {
 		return nil, err
@@ -987,7 +2400,7 @@ func (x *nodeCommunicationsEventNewBlockClient) Recv() (*BlockHeight, error) {
 	return m, nil
 }
 
-func (c *nodeCommunicationsClient) EventAddSpendableOut(ctx context.Context, in *Empty, opts ...grpc.CallOption) (NodeCommunications_EventAddSpendableOutClient, error) {
+func (c *nodeCommunicationsClient) EventAddSpendableOut(ctx context.Context, in *Cursor, opts ...grpc.CallOption) (NodeCommunications_EventAddSpendableOutClient, error) {
 	stream, err := grpc.NewClientStream(ctx, &_NodeCommunications_serviceDesc.Streams[5], c.cc, "/btc.NodeCommunications/EventAddSpendableOut", opts...)
 	if err != nil {
 		return nil, err
@@ -1019,7 +2432,7 @@ func (x *nodeCommunicationsEventAddSpendableOutClient) Recv() (*AddSpOut, error)
 	return m, nil
 }
 
-func (c *nodeCommunicationsClient) NewTx(ctx context.Context, in *Empty, opts ...grpc.CallOption) (NodeCommunications_NewTxClient, error) {
+func (c *nodeCommunicationsClient) NewTx(ctx context.Context, in *Cursor, opts ...grpc.CallOption) (NodeCommunications_NewTxClient, error) {
 	stream, err := grpc.NewClientStream(ctx, &_NodeCommunications_serviceDesc.Streams[6], c.cc, "/btc.NodeCommunications/NewTx", opts...)
 	if err != nil {
 		return nil, err
@@ -1051,7 +2464,7 @@ func (x *nodeCommunicationsNewTxClient) Recv() (*BTCTransaction, error) {
 	return m, nil
 }
 
-func (c *nodeCommunicationsClient) ResyncAddress(ctx context.Context, in *Empty, opts ...grpc.CallOption) (NodeCommunications_ResyncAddressClient, error) {
+func (c *nodeCommunicationsClient) ResyncAddress(ctx context.Context, in *Cursor, opts ...grpc.CallOption) (NodeCommunications_ResyncAddressClient, error) {
 	stream, err := grpc.NewClientStream(ctx, &_NodeCommunications_serviceDesc.Streams[7], c.cc, "/btc.NodeCommunications/ResyncAddress", opts...)
 	if err != nil {
 		return nil, err
@@ -1092,6 +2505,184 @@ func (c *nodeCommunicationsClient) CheckRejectTxs(ctx context.Context, in *TxsTo
 	return out, nil
 }
 
+func (c *nodeCommunicationsClient) EventBlockDisconnected(ctx context.Context, in *Cursor, opts ...grpc.CallOption) (NodeCommunications_EventBlockDisconnectedClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_NodeCommunications_serviceDesc.Streams[8], c.cc, "/btc.NodeCommunications/EventBlockDisconnected", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &nodeCommunicationsEventBlockDisconnectedClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type NodeCommunications_EventBlockDisconnectedClient interface {
+	Recv() (*BlockDisconnected, error)
+	grpc.ClientStream
+}
+
+type nodeCommunicationsEventBlockDisconnectedClient struct {
+	grpc.ClientStream
+}
+
+func (x *nodeCommunicationsEventBlockDisconnectedClient) Recv() (*BlockDisconnected, error) {
+	m := new(BlockDisconnected)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *nodeCommunicationsClient) Subscribe(ctx context.Context, in *Subscription, opts ...grpc.CallOption) (NodeCommunications_SubscribeClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_NodeCommunications_serviceDesc.Streams[9], c.cc, "/btc.NodeCommunications/Subscribe", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &nodeCommunicationsSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type NodeCommunications_SubscribeClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type nodeCommunicationsSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *nodeCommunicationsSubscribeClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *nodeCommunicationsClient) EstimateFee(ctx context.Context, in *FeeEstimateRequest, opts ...grpc.CallOption) (*FeeEstimates, error) {
+	out := new(FeeEstimates)
+	err := grpc.Invoke(ctx, "/btc.NodeCommunications/EstimateFee", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeCommunicationsClient) NodeHealth(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Health, error) {
+	out := new(Health)
+	err := grpc.Invoke(ctx, "/btc.NodeCommunications/NodeHealth", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeCommunicationsClient) EventTxReplaced(ctx context.Context, in *Cursor, opts ...grpc.CallOption) (NodeCommunications_EventTxReplacedClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_NodeCommunications_serviceDesc.Streams[10], c.cc, "/btc.NodeCommunications/EventTxReplaced", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &nodeCommunicationsEventTxReplacedClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type NodeCommunications_EventTxReplacedClient interface {
+	Recv() (*TxConflict, error)
+	grpc.ClientStream
+}
+
+type nodeCommunicationsEventTxReplacedClient struct {
+	grpc.ClientStream
+}
+
+func (x *nodeCommunicationsEventTxReplacedClient) Recv() (*TxConflict, error) {
+	m := new(TxConflict)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *nodeCommunicationsClient) EventTxConflicted(ctx context.Context, in *Cursor, opts ...grpc.CallOption) (NodeCommunications_EventTxConflictedClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_NodeCommunications_serviceDesc.Streams[11], c.cc, "/btc.NodeCommunications/EventTxConflicted", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &nodeCommunicationsEventTxConflictedClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type NodeCommunications_EventTxConflictedClient interface {
+	Recv() (*TxConflict, error)
+	grpc.ClientStream
+}
+
+type nodeCommunicationsEventTxConflictedClient struct {
+	grpc.ClientStream
+}
+
+func (x *nodeCommunicationsEventTxConflictedClient) Recv() (*TxConflict, error) {
+	m := new(TxConflict)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *nodeCommunicationsClient) EventTxDropped(ctx context.Context, in *Cursor, opts ...grpc.CallOption) (NodeCommunications_EventTxDroppedClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_NodeCommunications_serviceDesc.Streams[12], c.cc, "/btc.NodeCommunications/EventTxDropped", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &nodeCommunicationsEventTxDroppedClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type NodeCommunications_EventTxDroppedClient interface {
+	Recv() (*TxConflict, error)
+	grpc.ClientStream
+}
+
+type nodeCommunicationsEventTxDroppedClient struct {
+	grpc.ClientStream
+}
+
+func (x *nodeCommunicationsEventTxDroppedClient) Recv() (*TxConflict, error) {
+	m := new(TxConflict)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Server API for NodeCommunications service
 
 type NodeCommunicationsServer interface {
@@ -1101,16 +2692,23 @@ type NodeCommunicationsServer interface {
 	EventAddNewAddress(context.Context, *WatchAddress) (*ReplyInfo, error)
 	EventGetBlockHeight(context.Context, *Empty) (*BlockHeight, error)
 	EventGetAllMempool(*Empty, NodeCommunications_EventGetAllMempoolServer) error
-	EventAddMempoolRecord(*Empty, NodeCommunications_EventAddMempoolRecordServer) error
-	EventDeleteMempool(*Empty, NodeCommunications_EventDeleteMempoolServer) error
+	EventAddMempoolRecord(*Cursor, NodeCommunications_EventAddMempoolRecordServer) error
+	EventDeleteMempool(*Cursor, NodeCommunications_EventDeleteMempoolServer) error
 	EventResyncAddress(context.Context, *AddressToResync) (*ReplyInfo, error)
-	EventSendRawTx(context.Context, *RawTx) (*ReplyInfo, error)
-	EventDeleteSpendableOut(*Empty, NodeCommunications_EventDeleteSpendableOutServer) error
-	EventNewBlock(*Empty, NodeCommunications_EventNewBlockServer) error
-	EventAddSpendableOut(*Empty, NodeCommunications_EventAddSpendableOutServer) error
-	NewTx(*Empty, NodeCommunications_NewTxServer) error
-	ResyncAddress(*Empty, NodeCommunications_ResyncAddressServer) error
+	EventSendRawTx(context.Context, *RawTx) (*SendRawTxReply, error)
+	EventDeleteSpendableOut(*Cursor, NodeCommunications_EventDeleteSpendableOutServer) error
+	EventNewBlock(*Cursor, NodeCommunications_EventNewBlockServer) error
+	EventAddSpendableOut(*Cursor, NodeCommunications_EventAddSpendableOutServer) error
+	NewTx(*Cursor, NodeCommunications_NewTxServer) error
+	ResyncAddress(*Cursor, NodeCommunications_ResyncAddressServer) error
 	CheckRejectTxs(context.Context, *TxsToCheck) (*RejectedTxs, error)
+	EventBlockDisconnected(*Cursor, NodeCommunications_EventBlockDisconnectedServer) error
+	Subscribe(*Subscription, NodeCommunications_SubscribeServer) error
+	EstimateFee(context.Context, *FeeEstimateRequest) (*FeeEstimates, error)
+	NodeHealth(context.Context, *Empty) (*Health, error)
+	EventTxReplaced(*Cursor, NodeCommunications_EventTxReplacedServer) error
+	EventTxConflicted(*Cursor, NodeCommunications_EventTxConflictedServer) error
+	EventTxDropped(*Cursor, NodeCommunications_EventTxDroppedServer) error
 }
 
 func RegisterNodeCommunicationsServer(s *grpc.Server, srv NodeCommunicationsServer) {
@@ -1229,7 +2827,7 @@ func (x *nodeCommunicationsEventGetAllMempoolServer) Send(m *MempoolRecord) erro
 }
 
 func _NodeCommunications_EventAddMempoolRecord_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(Empty)
+	m := new(Cursor)
 	if err := stream.RecvMsg(m); err != nil {
 		return err
 	}
@@ -1250,7 +2848,7 @@ func (x *nodeCommunicationsEventAddMempoolRecordServer) Send(m *MempoolRecord) e
 }
 
 func _NodeCommunications_EventDeleteMempool_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(Empty)
+	m := new(Cursor)
 	if err := stream.RecvMsg(m); err != nil {
 		return err
 	}
@@ -1307,7 +2905,7 @@ func _NodeCommunications_EventSendRawTx_Handler(srv interface{}, ctx context.Con
 }
 
 func _NodeCommunications_EventDeleteSpendableOut_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(Empty)
+	m := new(Cursor)
 	if err := stream.RecvMsg(m); err != nil {
 		return err
 	}
@@ -1328,7 +2926,7 @@ func (x *nodeCommunicationsEventDeleteSpendableOutServer) Send(m *ReqDeleteSpOut
 }
 
 func _NodeCommunications_EventNewBlock_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(Empty)
+	m := new(Cursor)
 	if err := stream.RecvMsg(m); err != nil {
 		return err
 	}
@@ -1349,7 +2947,7 @@ func (x *nodeCommunicationsEventNewBlockServer) Send(m *BlockHeight) error {
 }
 
 func _NodeCommunications_EventAddSpendableOut_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(Empty)
+	m := new(Cursor)
 	if err := stream.RecvMsg(m); err != nil {
 		return err
 	}
@@ -1370,7 +2968,7 @@ func (x *nodeCommunicationsEventAddSpendableOutServer) Send(m *AddSpOut) error {
 }
 
 func _NodeCommunications_NewTx_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(Empty)
+	m := new(Cursor)
 	if err := stream.RecvMsg(m); err != nil {
 		return err
 	}
@@ -1391,7 +2989,7 @@ func (x *nodeCommunicationsNewTxServer) Send(m *BTCTransaction) error {
 }
 
 func _NodeCommunications_ResyncAddress_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(Empty)
+	m := new(Cursor)
 	if err := stream.RecvMsg(m); err != nil {
 		return err
 	}
@@ -1429,6 +3027,147 @@ func _NodeCommunications_CheckRejectTxs_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _NodeCommunications_EventBlockDisconnected_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Cursor)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NodeCommunicationsServer).EventBlockDisconnected(m, &nodeCommunicationsEventBlockDisconnectedServer{stream})
+}
+
+type NodeCommunications_EventBlockDisconnectedServer interface {
+	Send(*BlockDisconnected) error
+	grpc.ServerStream
+}
+
+type nodeCommunicationsEventBlockDisconnectedServer struct {
+	grpc.ServerStream
+}
+
+func (x *nodeCommunicationsEventBlockDisconnectedServer) Send(m *BlockDisconnected) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _NodeCommunications_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Subscription)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NodeCommunicationsServer).Subscribe(m, &nodeCommunicationsSubscribeServer{stream})
+}
+
+type NodeCommunications_SubscribeServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type nodeCommunicationsSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *nodeCommunicationsSubscribeServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _NodeCommunications_EstimateFee_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FeeEstimateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeCommunicationsServer).EstimateFee(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/btc.NodeCommunications/EstimateFee",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeCommunicationsServer).EstimateFee(ctx, req.(*FeeEstimateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NodeCommunications_NodeHealth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeCommunicationsServer).NodeHealth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/btc.NodeCommunications/NodeHealth",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeCommunicationsServer).NodeHealth(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NodeCommunications_EventTxReplaced_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Cursor)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NodeCommunicationsServer).EventTxReplaced(m, &nodeCommunicationsEventTxReplacedServer{stream})
+}
+
+type NodeCommunications_EventTxReplacedServer interface {
+	Send(*TxConflict) error
+	grpc.ServerStream
+}
+
+type nodeCommunicationsEventTxReplacedServer struct {
+	grpc.ServerStream
+}
+
+func (x *nodeCommunicationsEventTxReplacedServer) Send(m *TxConflict) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _NodeCommunications_EventTxConflicted_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Cursor)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NodeCommunicationsServer).EventTxConflicted(m, &nodeCommunicationsEventTxConflictedServer{stream})
+}
+
+type NodeCommunications_EventTxConflictedServer interface {
+	Send(*TxConflict) error
+	grpc.ServerStream
+}
+
+type nodeCommunicationsEventTxConflictedServer struct {
+	grpc.ServerStream
+}
+
+func (x *nodeCommunicationsEventTxConflictedServer) Send(m *TxConflict) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _NodeCommunications_EventTxDropped_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Cursor)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NodeCommunicationsServer).EventTxDropped(m, &nodeCommunicationsEventTxDroppedServer{stream})
+}
+
+type NodeCommunications_EventTxDroppedServer interface {
+	Send(*TxConflict) error
+	grpc.ServerStream
+}
+
+type nodeCommunicationsEventTxDroppedServer struct {
+	grpc.ServerStream
+}
+
+func (x *nodeCommunicationsEventTxDroppedServer) Send(m *TxConflict) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _NodeCommunications_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "btc.NodeCommunications",
 	HandlerType: (*NodeCommunicationsServer)(nil),
@@ -1465,6 +3204,14 @@ var _NodeCommunications_serviceDesc = grpc.ServiceDesc{
 			MethodName: "CheckRejectTxs",
 			Handler:    _NodeCommunications_CheckRejectTxs_Handler,
 		},
+		{
+			MethodName: "EstimateFee",
+			Handler:    _NodeCommunications_EstimateFee_Handler,
+		},
+		{
+			MethodName: "NodeHealth",
+			Handler:    _NodeCommunications_NodeHealth_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -1507,6 +3254,31 @@ var _NodeCommunications_serviceDesc = grpc.ServiceDesc{
 			Handler:       _NodeCommunications_ResyncAddress_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "EventBlockDisconnected",
+			Handler:       _NodeCommunications_EventBlockDisconnected_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Subscribe",
+			Handler:       _NodeCommunications_Subscribe_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "EventTxReplaced",
+			Handler:       _NodeCommunications_EventTxReplaced_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "EventTxConflicted",
+			Handler:       _NodeCommunications_EventTxConflicted_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "EventTxDropped",
+			Handler:       _NodeCommunications_EventTxDropped_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "streamer.proto",
 }
@@ -1514,82 +3286,176 @@ var _NodeCommunications_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("streamer.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 1228 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x57, 0x5b, 0x6f, 0x1b, 0x45,
-	0x14, 0xce, 0x7a, 0xe3, 0xdb, 0x71, 0x6c, 0xb7, 0xd3, 0xd0, 0xae, 0x2c, 0x04, 0xd6, 0x8a, 0x48,
-	0x2e, 0x42, 0x6e, 0x49, 0x55, 0xa9, 0x94, 0xaa, 0xc2, 0x4d, 0xd2, 0xd6, 0x12, 0x4d, 0xc5, 0xda,
-	0x2d, 0xbc, 0x8e, 0x77, 0xa7, 0xf1, 0xd2, 0xbd, 0x98, 0xdd, 0x71, 0xb2, 0x7e, 0x47, 0x48, 0xbc,
-	0xf1, 0x6f, 0xf8, 0x15, 0xfc, 0x23, 0x1e, 0xd0, 0x9c, 0x99, 0xb5, 0x67, 0x1a, 0xd3, 0x04, 0x89,
-	0xb7, 0x39, 0x67, 0xce, 0xf5, 0x3b, 0x97, 0xd9, 0x85, 0x4e, 0xce, 0x33, 0x46, 0x63, 0x96, 0x0d,
-	0x17, 0x59, 0xca, 0x53, 0x62, 0xcf, 0xb8, 0xef, 0xf6, 0x01, 0xa6, 0x45, 0x3e, 0x4d, 0x8f, 0xe6,
-	0xcc, 0x7f, 0x4f, 0x08, 0xec, 0xbe, 0xa4, 0xf9, 0xdc, 0xb1, 0xfa, 0xf6, 0xa0, 0xe9, 0xe1, 0xd9,
-	0xbd, 0x07, 0x2d, 0x8f, 0xfd, 0xcc, 0x7c, 0xce, 0x82, 0x69, 0x91, 0x93, 0xbe, 0x41, 0x2a, 0x49,
-	0x9d, 0xe5, 0xfe, 0x55, 0x83, 0xce, 0xb3, 0xe9, 0xd1, 0x34, 0xa3, 0x49, 0x4e, 0x7d, 0x1e, 0xa6,
-	0x09, 0xb9, 0x0d, 0xb5, 0x65, 0xce, 0xb2, 0xf1, 0xb1, 0x63, 0xf5, 0xad, 0x41, 0xd3, 0x53, 0x94,
-	0xf0, 0xc7, 0x8b, 0xf1, 0xb1, 0x53, 0x41, 0x2e, 0x9e, 0x85, 0x2c, 0x2f, 0x30, 0x0a, 0x5b, 0xca,
-	0x4a, 0x4a, 0x38, 0xe6, 0xc5, 0xeb, 0x25, 0x9f, 0xf8, 0x59, 0xb8, 0xe0, 0xce, 0x2e, 0x5e, 0xea,
-	0x2c, 0xf2, 0x29, 0x34, 0x79, 0x31, 0x0a, 0x82, 0x8c, 0xe5, 0xb9, 0x53, 0xc5, 0xc0, 0x36, 0x0c,
-	0xd2, 0x83, 0x06, 0x2f, 0x26, 0x9c, 0xf2, 0x65, 0xee, 0xd4, 0xfa, 0xd6, 0xa0, 0xea, 0xad, 0xe9,
-	0xb5, 0xed, 0x51, 0x9c, 0x2e, 0x13, 0xee, 0xd4, 0xfb, 0xd6, 0xc0, 0xf6, 0x74, 0x96, 0xb0, 0x3d,
-	0x8b, 0x52, 0xff, 0xfd, 0x34, 0x8c, 0x99, 0xd3, 0xc0, 0xfb, 0x0d, 0x43, 0xe8, 0x23, 0xf1, 0x92,
-	0x85, 0x67, 0x73, 0xee, 0x34, 0xa5, 0xbe, 0xc6, 0x22, 0x5f, 0x40, 0xdb, 0x4f, 0x93, 0x77, 0x61,
-	0x16, 0x53, 0x81, 0x48, 0xee, 0x00, 0x86, 0x60, 0x32, 0xc9, 0x3e, 0x54, 0x79, 0xf1, 0x9c, 0x31,
-	0xa7, 0x85, 0x16, 0x24, 0x21, 0xac, 0xc7, 0x2c, 0x5e, 0xa4, 0x69, 0x84, 0xde, 0xf7, 0xa4, 0x75,
-	0x8d, 0x45, 0x9e, 0x88, 0xdc, 0xc6, 0xc9, 0x62, 0xc9, 0x73, 0xa7, 0xdd, 0xb7, 0x07, 0xad, 0xc3,
-	0xfe, 0x70, 0xc6, 0xfd, 0xa1, 0x59, 0x86, 0xa1, 0x84, 0x42, 0x66, 0xe4, 0xad, 0x35, 0xc8, 0x53,
-	0x68, 0x4e, 0x45, 0xaa, 0xa8, 0xde, 0xb9, 0xa6, 0xfa, 0x46, 0x85, 0x1c, 0xc1, 0xde, 0x8f, 0x34,
-	0x8a, 0x18, 0xcf, 0xd1, 0xa0, 0xd3, 0x45, 0x13, 0x9f, 0x6f, 0x33, 0x21, 0xe5, 0x9e, 0xa7, 0xd9,
-	0xb4, 0xf0, 0x0c, 0x25, 0x72, 0x02, 0x6d, 0x45, 0x4b, 0xb3, 0xce, 0x8d, 0xeb, 0x59, 0x31, 0xb5,
-	0x44, 0xf7, 0x64, 0x2c, 0x5f, 0x25, 0xbe, 0x73, 0xb3, 0x6f, 0x0d, 0x1a, 0x9e, 0xa2, 0x7a, 0xdf,
-	0xc1, 0x9e, 0x1e, 0x3e, 0x71, 0xa0, 0x4e, 0x55, 0xa7, 0xc8, 0x96, 0x2c, 0x49, 0x61, 0x81, 0xca,
-	0x36, 0xa8, 0x20, 0xd0, 0x8a, 0xea, 0x5d, 0x40, 0x4b, 0xf3, 0x5b, 0xb6, 0x74, 0x18, 0xe8, 0x2d,
-	0x1d, 0x06, 0xba, 0xe1, 0x8a, 0x69, 0xf8, 0x33, 0x00, 0xec, 0xa8, 0x71, 0x12, 0xb0, 0x02, 0x9b,
-	0xbb, 0xea, 0x69, 0x1c, 0xcd, 0xf1, 0xae, 0xee, 0xd8, 0xfd, 0xa3, 0x02, 0x8d, 0x51, 0x10, 0x4c,
-	0x16, 0xaf, 0x97, 0x7c, 0x3d, 0x31, 0x96, 0x36, 0x31, 0x0e, 0xd4, 0xa5, 0x19, 0x39, 0x48, 0x55,
-	0xaf, 0x24, 0x3f, 0xec, 0x6b, 0xfb, 0x72, 0x5f, 0x5f, 0x3d, 0x55, 0x5a, 0x42, 0xd5, 0x4b, 0x48,
-	0xa9, 0xa9, 0xae, 0x19, 0x53, 0xad, 0x4f, 0x5a, 0xfd, 0xf2, 0xa4, 0x5d, 0x20, 0x8a, 0x12, 0x85,
-	0x06, 0x5e, 0xeb, 0x2c, 0xe2, 0xc2, 0x9e, 0x72, 0x20, 0x45, 0x9a, 0x28, 0x62, 0xf0, 0xdc, 0x3f,
-	0x2d, 0xa8, 0x79, 0x58, 0x58, 0x72, 0x00, 0x76, 0xb9, 0x87, 0x5a, 0x87, 0xb7, 0xb6, 0x74, 0x8b,
-	0x27, 0xee, 0xc9, 0x01, 0xd4, 0x10, 0x40, 0x51, 0x15, 0x21, 0xd9, 0x46, 0xc9, 0x12, 0x56, 0x4f,
-	0x5d, 0x92, 0x87, 0xd0, 0xc2, 0xd3, 0x31, 0x8b, 0x18, 0x67, 0x8e, 0xad, 0x59, 0xf5, 0xd8, 0x2f,
-	0x92, 0x2b, 0x35, 0x74, 0x39, 0x32, 0x80, 0xae, 0x3c, 0x3d, 0xcf, 0xd2, 0xf8, 0x87, 0x25, 0x5b,
-	0x32, 0x85, 0xe4, 0x87, 0x6c, 0xf7, 0x00, 0x5a, 0xcf, 0xb4, 0xb5, 0x70, 0x1b, 0x6a, 0x73, 0xb9,
-	0x33, 0x2c, 0x59, 0x73, 0x49, 0xb9, 0x6f, 0xa1, 0x63, 0xfa, 0xfb, 0x4f, 0x2b, 0x54, 0x2b, 0x99,
-	0x6d, 0x94, 0xcc, 0x3d, 0x80, 0xee, 0x2b, 0xb5, 0x37, 0x52, 0x15, 0x3b, 0x81, 0xdd, 0xb9, 0xdc,
-	0xf9, 0x68, 0x40, 0x9c, 0xdd, 0xdf, 0x2c, 0x31, 0xd2, 0xdc, 0x9f, 0x97, 0xcb, 0xf3, 0xa3, 0xe3,
-	0xa2, 0xe2, 0xaa, 0x18, 0x71, 0xf5, 0xcb, 0x71, 0xd1, 0xdb, 0x5d, 0x67, 0x89, 0x42, 0x8f, 0xf4,
-	0x42, 0xef, 0xca, 0x42, 0xeb, 0x3c, 0xf7, 0x08, 0xda, 0x2a, 0x5e, 0x8f, 0xf9, 0x69, 0x16, 0x88,
-	0xde, 0xf2, 0x29, 0x67, 0x67, 0x69, 0xb6, 0xc2, 0x48, 0xaa, 0xde, 0x9a, 0x46, 0x30, 0x69, 0x3e,
-	0x9f, 0xfe, 0x54, 0x86, 0x22, 0x29, 0xb7, 0x0e, 0xd5, 0x93, 0x78, 0xc1, 0x57, 0xee, 0x5d, 0xa8,
-	0x7a, 0xf4, 0x62, 0x5a, 0x60, 0xd7, 0x6f, 0x3a, 0x44, 0xa5, 0xa4, 0xb3, 0xdc, 0xdf, 0x2d, 0xe8,
-	0xaa, 0x48, 0xa6, 0xa9, 0x6a, 0x35, 0x07, 0xea, 0x23, 0x13, 0x84, 0xd1, 0x06, 0x84, 0x37, 0x06,
-	0x08, 0x6f, 0xfe, 0x4f, 0x10, 0x7e, 0xb5, 0xa0, 0x29, 0x0c, 0xe6, 0xc7, 0x94, 0x53, 0x72, 0x17,
-	0xec, 0x98, 0x2e, 0x54, 0xc3, 0xdf, 0xc1, 0xd6, 0x5c, 0x5f, 0x0e, 0x5f, 0xd1, 0xc5, 0x49, 0xc2,
-	0xb3, 0x95, 0x27, 0x64, 0x7a, 0xdf, 0x43, 0xa3, 0x64, 0x90, 0x1b, 0x60, 0xbf, 0x67, 0x2b, 0x15,
-	0xb8, 0x38, 0x92, 0x2f, 0xa1, 0x7a, 0x4e, 0xa3, 0x25, 0xc3, 0x98, 0x5b, 0x87, 0xfb, 0xe5, 0x44,
-	0x08, 0xc7, 0x27, 0x05, 0x67, 0x49, 0xc0, 0x02, 0x4f, 0x8a, 0x3c, 0xae, 0x3c, 0xb2, 0xdc, 0x74,
-	0x8d, 0x48, 0x79, 0xab, 0xe5, 0x6d, 0x7d, 0x2c, 0xef, 0xca, 0xd5, 0x79, 0xdb, 0x5b, 0xf2, 0x3e,
-	0x80, 0xa6, 0xc7, 0x16, 0xd1, 0x6a, 0x9c, 0xbc, 0x4b, 0x05, 0xf8, 0x31, 0xcb, 0x73, 0x7a, 0xc6,
-	0x4a, 0xf0, 0x15, 0xe9, 0x16, 0xd0, 0x99, 0xb0, 0xec, 0x3c, 0xf4, 0xd9, 0x5b, 0x96, 0xe5, 0xea,
-	0x73, 0x63, 0x96, 0xd1, 0xc4, 0x2f, 0x9b, 0x5a, 0x51, 0x82, 0xef, 0xa7, 0x71, 0x1c, 0xf2, 0xb2,
-	0x4c, 0x92, 0xc2, 0xc7, 0x7d, 0x19, 0x46, 0x01, 0x17, 0xcf, 0xab, 0x9c, 0x98, 0x0d, 0x43, 0x78,
-	0x8e, 0x68, 0xce, 0x39, 0x3d, 0x53, 0x43, 0x5d, 0x92, 0x87, 0x7f, 0xd7, 0x80, 0x9c, 0xa6, 0x01,
-	0x3b, 0x4a, 0xe3, 0x78, 0x99, 0x84, 0xbe, 0x7a, 0xc5, 0xef, 0x43, 0x4b, 0x05, 0x84, 0x91, 0x03,
-	0x02, 0x8b, 0x1d, 0xd8, 0x93, 0xab, 0xc4, 0x0c, 0xd7, 0xdd, 0x21, 0x0f, 0xa0, 0x7b, 0x72, 0xce,
-	0x12, 0x3e, 0x4e, 0x42, 0x1e, 0xd2, 0x68, 0x14, 0x04, 0xa4, 0x63, 0x56, 0xb6, 0xd7, 0x51, 0x4b,
-	0x48, 0xe1, 0xe1, 0xee, 0x90, 0x7b, 0xd0, 0x9c, 0xac, 0x12, 0x5f, 0x2c, 0x56, 0x46, 0x6e, 0xc8,
-	0xcd, 0xb7, 0x59, 0x2d, 0x5b, 0x14, 0xbe, 0x01, 0x82, 0x5e, 0x46, 0x41, 0x70, 0xca, 0x2e, 0xca,
-	0xde, 0xbd, 0x89, 0x72, 0xfa, 0xb4, 0x6f, 0x51, 0x7d, 0x08, 0xb7, 0x50, 0xf5, 0x05, 0xe3, 0xfa,
-	0xfa, 0xd2, 0x53, 0xbb, 0x14, 0x81, 0xbb, 0x43, 0x1e, 0x29, 0x8f, 0x2f, 0x18, 0x1f, 0x45, 0x91,
-	0x9a, 0x64, 0x43, 0x8b, 0xe0, 0xd9, 0x98, 0x71, 0x77, 0xe7, 0xbe, 0x45, 0xbe, 0x85, 0x4f, 0xca,
-	0x58, 0xcd, 0x05, 0x70, 0x1d, 0xe5, 0xc7, 0xca, 0xad, 0xdc, 0x70, 0xdb, 0xdc, 0xee, 0xeb, 0x9a,
-	0xe5, 0x2a, 0x44, 0xdd, 0x27, 0x4a, 0x57, 0xce, 0x7c, 0x09, 0x92, 0x31, 0x1c, 0xe5, 0x42, 0xd8,
-	0x82, 0xd3, 0x10, 0x3a, 0xa8, 0x3d, 0x61, 0x49, 0x20, 0x57, 0x8d, 0xf4, 0x8a, 0xe7, 0x2d, 0xf2,
-	0x4f, 0xe1, 0x8e, 0x16, 0xe9, 0x64, 0xc1, 0x92, 0x80, 0xce, 0x22, 0x26, 0x16, 0xfe, 0xe5, 0xb6,
-	0x31, 0x5f, 0x04, 0x8c, 0xf6, 0x6b, 0x68, 0xa3, 0xfe, 0x29, 0xbb, 0x40, 0xe4, 0xaf, 0xaa, 0xc8,
-	0x7d, 0x8b, 0x3c, 0x84, 0xfd, 0x12, 0xd9, 0x7f, 0xf5, 0x67, 0xbe, 0x8e, 0xa8, 0xf6, 0x15, 0x54,
-	0x4f, 0xd9, 0x26, 0x21, 0x3d, 0x2e, 0xf3, 0xbd, 0x55, 0xd2, 0x6d, 0x13, 0x40, 0x5d, 0xab, 0xa5,
-	0xb2, 0x11, 0xf7, 0x2a, 0xa4, 0x0e, 0xfe, 0x7f, 0xc8, 0xbf, 0x08, 0xf1, 0x5c, 0x77, 0x51, 0x64,
-	0xf3, 0x67, 0xa2, 0x72, 0xd1, 0x7f, 0x33, 0x76, 0x66, 0x35, 0xfc, 0x8f, 0x79, 0xf0, 0x4f, 0x00,
-	0x00, 0x00, 0xff, 0xff, 0x6e, 0xb9, 0x5a, 0xff, 0xd9, 0x0c, 0x00, 0x00,
+	// 2734 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x39, 0x5f, 0x6f, 0xe3, 0xc6,
+	0xf1, 0xa2, 0x28, 0xca, 0xd2, 0xe8, 0x8f, 0xe5, 0xf5, 0xe5, 0x8e, 0x30, 0x82, 0xfc, 0x0c, 0x22,
+	0x97, 0x9f, 0x73, 0x45, 0xdd, 0xab, 0xd3, 0x6b, 0xd2, 0xf4, 0xd0, 0x56, 0x96, 0xe8, 0x58, 0x3d,
+	0x5b, 0x72, 0x56, 0x74, 0xee, 0xfa, 0xa4, 0xd2, 0xe2, 0x9e, 0xcd, 0x9c, 0x44, 0x2a, 0xe4, 0xea,
+	0x4e, 0xce, 0x5b, 0x81, 0xa0, 0x40, 0xdf, 0xf2, 0x19, 0xda, 0xa7, 0x3e, 0xb5, 0x1f, 0xa2, 0x45,
+	0xd1, 0x2f, 0xd0, 0xb7, 0xa2, 0x5f, 0xa5, 0xd8, 0x7f, 0xe2, 0x52, 0x96, 0xef, 0x0f, 0xd0, 0x37,
+	0xce, 0xec, 0xcc, 0xec, 0xcc, 0xec, 0xfc, 0xdb, 0x25, 0x34, 0x53, 0x9a, 0x10, 0x7f, 0x4a, 0x92,
+	0xfd, 0x59, 0x12, 0xd3, 0x18, 0x99, 0x17, 0x74, 0xec, 0xec, 0x02, 0x78, 0x8b, 0xd4, 0x8b, 0x3b,
+	0x57, 0x64, 0xfc, 0x02, 0x21, 0x28, 0x1d, 0xfb, 0xe9, 0x95, 0x6d, 0xec, 0x9a, 0x7b, 0x55, 0xcc,
+	0xbf, 0x9d, 0x7f, 0x18, 0x50, 0xe5, 0xab, 0x24, 0xf0, 0x16, 0x8c, 0xe2, 0x4a, 0x50, 0x18, 0x8c,
+	0x82, 0x7d, 0xa3, 0x07, 0x50, 0x4e, 0xa9, 0x4f, 0xe7, 0xa9, 0x5d, 0xdc, 0x35, 0xf6, 0x9a, 0x07,
+	0x68, 0xff, 0x82, 0x8e, 0xf7, 0xbd, 0x05, 0xe7, 0x1a, 0xf2, 0x15, 0x2c, 0x29, 0xd0, 0x5d, 0x28,
+	0x5f, 0x91, 0xf0, 0xf2, 0x8a, 0xda, 0xe6, 0xae, 0xb1, 0x67, 0x62, 0x09, 0xa1, 0xf7, 0xa1, 0x7a,
+	0x31, 0x89, 0xc7, 0x2f, 0xf8, 0xf6, 0x25, 0x2e, 0x3c, 0x43, 0xa0, 0x0f, 0xa1, 0x31, 0x8e, 0xa3,
+	0xe7, 0x61, 0x32, 0xf5, 0x69, 0x18, 0x47, 0xa9, 0x6d, 0x71, 0xe6, 0x3c, 0x12, 0x7d, 0x00, 0x90,
+	0x90, 0xd9, 0xc4, 0x1f, 0x93, 0xe0, 0xf0, 0xda, 0x2e, 0x73, 0x21, 0x1a, 0xc6, 0xf9, 0x12, 0x6a,
+	0x98, 0x7c, 0x4d, 0xc6, 0x94, 0x59, 0x92, 0xa2, 0xdd, 0x1c, 0x28, 0x6d, 0x5e, 0xa1, 0x30, 0xe9,
+	0x82, 0x59, 0x65, 0xee, 0xd5, 0x0e, 0x9a, 0xdc, 0xaa, 0xa5, 0x27, 0x30, 0x5b, 0x72, 0xfe, 0x5d,
+	0x81, 0xe6, 0xa1, 0xd7, 0xf1, 0x12, 0x3f, 0x4a, 0xfd, 0x31, 0x53, 0x83, 0x59, 0x38, 0x4f, 0x49,
+	0xd2, 0xeb, 0x4a, 0x1f, 0x49, 0x88, 0x79, 0x8e, 0x2e, 0x7a, 0x5d, 0xee, 0xa3, 0x2a, 0xe6, 0xdf,
+	0x8c, 0x96, 0x2e, 0xb8, 0xc9, 0xa6, 0xa0, 0x15, 0x10, 0x53, 0x8d, 0x2e, 0x06, 0x73, 0x3a, 0x1c,
+	0x27, 0xe1, 0x8c, 0x4a, 0x7f, 0xe8, 0x28, 0xe6, 0x2f, 0xba, 0x68, 0x07, 0x41, 0x42, 0x52, 0xe6,
+	0x0d, 0xa6, 0x7a, 0x86, 0x40, 0x3b, 0x50, 0xa1, 0x0b, 0xe1, 0x79, 0xee, 0x07, 0x0b, 0x2f, 0xe1,
+	0xa5, 0xec, 0xf6, 0x34, 0x9e, 0x47, 0xd4, 0xde, 0xe0, 0x9e, 0xd4, 0x51, 0xcb, 0xb3, 0xf0, 0xc2,
+	0x29, 0xb1, 0x2b, 0x7c, 0x3d, 0x43, 0x30, 0x7e, 0x71, 0x30, 0xe2, 0x18, 0xab, 0x82, 0x5f, 0x43,
+	0xdd, 0x3c, 0x2d, 0xe0, 0x2a, 0xac, 0x9c, 0xd6, 0x1d, 0xb0, 0xe8, 0xe2, 0x88, 0x10, 0xbb, 0xc6,
+	0x25, 0x08, 0x80, 0x49, 0x9f, 0x92, 0xe9, 0x2c, 0x8e, 0x27, 0x7c, 0xf7, 0xba, 0x90, 0xae, 0xa1,
+	0xd0, 0x63, 0x66, 0x5b, 0x2f, 0x9a, 0xcd, 0x69, 0x6a, 0x37, 0xf8, 0xc9, 0xec, 0xf2, 0x93, 0xc9,
+	0x1f, 0xc3, 0xbe, 0x70, 0x85, 0xb0, 0x08, 0x2f, 0x39, 0xd0, 0x2f, 0xa0, 0xea, 0x31, 0x53, 0x39,
+	0x7b, 0xf3, 0x2d, 0xd9, 0x33, 0x16, 0xd4, 0x81, 0xfa, 0x53, 0x7f, 0x32, 0x21, 0x34, 0xe5, 0x02,
+	0xed, 0x4d, 0x2e, 0xe2, 0xff, 0xd6, 0x89, 0x10, 0x74, 0x47, 0x71, 0xe2, 0x2d, 0x70, 0x8e, 0x09,
+	0xb9, 0xd0, 0x90, 0xb0, 0x10, 0x6b, 0xb7, 0xde, 0x4e, 0x4a, 0x9e, 0x8b, 0x45, 0x4f, 0x42, 0xd2,
+	0xeb, 0x68, 0x6c, 0x6f, 0xed, 0x1a, 0x7b, 0x15, 0x2c, 0x21, 0xd4, 0x02, 0x33, 0x25, 0xdf, 0xd8,
+	0x68, 0xd7, 0xd8, 0x2b, 0x61, 0xf6, 0xc9, 0x7c, 0xfd, 0x32, 0x0d, 0xbf, 0x25, 0xf6, 0x36, 0x3f,
+	0x09, 0x01, 0x30, 0xfe, 0x57, 0xe2, 0x10, 0xef, 0x70, 0xb4, 0x84, 0x90, 0x0d, 0x1b, 0xcf, 0x09,
+	0xc1, 0x3e, 0x25, 0xf6, 0x7b, 0xbb, 0xc6, 0x9e, 0x81, 0x15, 0x88, 0x3e, 0x87, 0x6a, 0x3c, 0xc3,
+	0x84, 0xce, 0x93, 0x28, 0xb5, 0xef, 0x72, 0xa5, 0xdf, 0x5f, 0xa7, 0xf4, 0x40, 0x12, 0xe1, 0x8c,
+	0x9c, 0x45, 0x85, 0x02, 0x4e, 0x7d, 0x3a, 0xbe, 0xb2, 0xef, 0x71, 0xa5, 0xf3, 0xc8, 0x9d, 0x5f,
+	0x41, 0x5d, 0x77, 0x3d, 0xd3, 0xc5, 0x97, 0x51, 0x2e, 0xd2, 0x49, 0x81, 0x4c, 0x7b, 0x5f, 0x84,
+	0x70, 0x51, 0x54, 0x12, 0x01, 0xed, 0xbc, 0x82, 0x9a, 0xe6, 0x33, 0x95, 0x8e, 0x61, 0xa0, 0xa7,
+	0x63, 0x18, 0xe8, 0x82, 0x8b, 0x79, 0xc1, 0x1f, 0x00, 0xf0, 0x6c, 0xe8, 0x45, 0x01, 0x59, 0xf0,
+	0xc4, 0xb4, 0xb0, 0x86, 0xd1, 0x36, 0x2e, 0xe5, 0x36, 0xc6, 0x50, 0x51, 0x76, 0xaf, 0xc8, 0x30,
+	0x6e, 0xc8, 0x40, 0x50, 0x0a, 0x7c, 0xea, 0xf3, 0xad, 0xeb, 0x98, 0x7f, 0x33, 0x1c, 0x25, 0x0b,
+	0x2a, 0x4b, 0x01, 0xff, 0x76, 0xfe, 0x58, 0x84, 0x4a, 0x3b, 0x08, 0x86, 0xb3, 0xc1, 0x9c, 0x2e,
+	0x2b, 0x88, 0xa1, 0x55, 0x10, 0x1b, 0x36, 0x84, 0x58, 0x51, 0x58, 0x2c, 0xac, 0xc0, 0xd5, 0x3c,
+	0x37, 0x6f, 0xe6, 0xf9, 0x9b, 0xab, 0x8c, 0xe6, 0x24, 0xeb, 0x86, 0xf7, 0x65, 0x95, 0x2b, 0xe7,
+	0xaa, 0x9c, 0x5e, 0x79, 0x36, 0x6e, 0x56, 0x9e, 0x57, 0xfc, 0x64, 0x84, 0x57, 0x2a, 0x7c, 0x59,
+	0x47, 0x21, 0x07, 0xea, 0x72, 0x03, 0x41, 0x52, 0xe5, 0x24, 0x39, 0x9c, 0x8a, 0x6e, 0x58, 0x46,
+	0xb7, 0xf3, 0x4f, 0x03, 0xca, 0x58, 0x84, 0xfe, 0x7d, 0x30, 0x55, 0x2d, 0xaf, 0x1d, 0x6c, 0xaf,
+	0x09, 0x4d, 0xcc, 0xd6, 0xd1, 0x7d, 0x28, 0x73, 0x97, 0xaa, 0xda, 0xde, 0xe0, 0x94, 0xca, 0xd1,
+	0x58, 0x2e, 0xa2, 0x47, 0x50, 0xe3, 0x5f, 0x5d, 0x32, 0x21, 0x94, 0xd8, 0xa6, 0x26, 0x15, 0x93,
+	0x6f, 0x04, 0x56, 0x70, 0xe8, 0x74, 0x68, 0x0f, 0x36, 0xc5, 0xd7, 0x51, 0x12, 0x4f, 0xbf, 0x9c,
+	0x93, 0x39, 0x91, 0xbe, 0x5d, 0x45, 0x2b, 0x5b, 0xac, 0xcc, 0x96, 0x7f, 0x19, 0xb0, 0x75, 0xc8,
+	0x6a, 0x69, 0x37, 0x4c, 0xc7, 0x71, 0x14, 0xf1, 0x5e, 0xa4, 0x75, 0x4d, 0x23, 0xd7, 0x35, 0x55,
+	0x37, 0x2e, 0x6a, 0xdd, 0x58, 0xba, 0xc0, 0x7c, 0x6b, 0x17, 0x94, 0xde, 0xc1, 0x05, 0xd6, 0x5b,
+	0xba, 0x40, 0x1a, 0x56, 0xce, 0x0c, 0xfb, 0x53, 0x91, 0x4d, 0x1a, 0x9d, 0x38, 0x7a, 0x3e, 0x09,
+	0xc7, 0xeb, 0x63, 0xf9, 0x2e, 0x94, 0x2f, 0xae, 0xbd, 0xac, 0x47, 0x4a, 0x68, 0xb5, 0xe3, 0x98,
+	0x37, 0x3b, 0x8e, 0xb4, 0xb9, 0xf4, 0xd6, 0x36, 0x5b, 0xef, 0x60, 0x73, 0xf9, 0xdd, 0x6c, 0xde,
+	0xc8, 0xca, 0xee, 0x43, 0x56, 0xa0, 0xfd, 0x34, 0x8e, 0x78, 0xac, 0x37, 0x0f, 0x6c, 0x2e, 0xe3,
+	0x54, 0x34, 0x33, 0xc1, 0x85, 0xf9, 0x3a, 0x96, 0x74, 0xce, 0xf7, 0x45, 0x28, 0x1f, 0x13, 0x7f,
+	0x42, 0xaf, 0x58, 0x26, 0x05, 0xe4, 0x32, 0xf1, 0x03, 0x22, 0x4a, 0x57, 0x05, 0x2f, 0x61, 0xe6,
+	0xbd, 0x69, 0x1c, 0x10, 0x75, 0xee, 0xec, 0x5b, 0x74, 0x03, 0xbe, 0x99, 0x9c, 0x25, 0x04, 0xc4,
+	0x6a, 0x7f, 0x1a, 0x46, 0x63, 0x22, 0xab, 0x95, 0x00, 0x58, 0x8f, 0x1f, 0x8b, 0x51, 0xa6, 0x4d,
+	0xe5, 0x34, 0x95, 0x21, 0x58, 0x1e, 0x4e, 0xc3, 0x34, 0x25, 0x01, 0x0f, 0x45, 0x31, 0x43, 0x98,
+	0x38, 0x87, 0x63, 0x12, 0x04, 0xcc, 0x3c, 0x2f, 0xa6, 0x88, 0x0c, 0xc1, 0x72, 0x20, 0x20, 0x93,
+	0xf0, 0x25, 0x49, 0x48, 0x20, 0xcf, 0x4d, 0x4c, 0x12, 0xab, 0x68, 0x56, 0x2a, 0x2f, 0x48, 0x4a,
+	0x73, 0xe3, 0x84, 0x86, 0x71, 0xf6, 0x01, 0x1d, 0x11, 0xe2, 0xa6, 0x34, 0x9c, 0xfa, 0xcc, 0x5f,
+	0xdf, 0xcc, 0x49, 0xca, 0x2b, 0x13, 0xf5, 0x93, 0x4b, 0x42, 0x45, 0xb2, 0x5b, 0x58, 0x81, 0xce,
+	0x5f, 0x0c, 0xa8, 0x69, 0x0c, 0x7c, 0xc6, 0xe2, 0x4b, 0xb2, 0x0c, 0x4b, 0x48, 0xef, 0x72, 0xa2,
+	0x81, 0x28, 0x10, 0x7d, 0x04, 0x4d, 0x39, 0x70, 0x1c, 0x49, 0x02, 0x11, 0x72, 0x2b, 0x58, 0xd6,
+	0xd1, 0x78, 0x10, 0xa6, 0x8a, 0x4c, 0x78, 0x38, 0x8f, 0x64, 0xd1, 0x1b, 0xc5, 0x01, 0x51, 0x34,
+	0xc2, 0xd7, 0x3a, 0xca, 0xf9, 0xce, 0x80, 0xba, 0xa6, 0x71, 0x8a, 0xf6, 0xa1, 0x4a, 0x14, 0x20,
+	0x6b, 0x59, 0x8b, 0x87, 0x8e, 0xee, 0x88, 0x8c, 0x44, 0x1b, 0x9a, 0x86, 0xe1, 0xb7, 0xca, 0x1c,
+	0x1d, 0xc5, 0x0f, 0x54, 0x80, 0x87, 0xd7, 0x4c, 0xa8, 0x29, 0x0f, 0x54, 0xc3, 0x39, 0x9f, 0x42,
+	0xed, 0x50, 0xcb, 0xa9, 0xdb, 0x6a, 0x8e, 0x0c, 0xf3, 0x62, 0x96, 0xda, 0x7f, 0x2d, 0x42, 0x33,
+	0x9f, 0x18, 0xef, 0x34, 0x04, 0x6b, 0x4d, 0xc6, 0xcc, 0x37, 0x19, 0xb9, 0x55, 0x29, 0xcb, 0x28,
+	0xad, 0xdd, 0x59, 0xf9, 0x76, 0xe7, 0x40, 0x3d, 0x9d, 0x91, 0x28, 0x08, 0xa3, 0x4b, 0x5e, 0x42,
+	0x44, 0x5b, 0xca, 0xe1, 0xd0, 0x3e, 0x20, 0x05, 0xf3, 0x41, 0x4c, 0x34, 0x19, 0xd1, 0xa6, 0xd6,
+	0xac, 0xa0, 0x87, 0xb0, 0xad, 0xb0, 0x9a, 0x67, 0x64, 0x20, 0xaf, 0x5b, 0x62, 0xa1, 0xc3, 0xd0,
+	0xb4, 0x17, 0xc9, 0x2c, 0xe7, 0x01, 0x5d, 0xc1, 0x2b, 0x58, 0x27, 0x84, 0x4d, 0xf9, 0xe9, 0xc5,
+	0xb2, 0x7c, 0xac, 0xbb, 0x59, 0xdd, 0xf0, 0xb5, 0x56, 0x52, 0xcc, 0xb7, 0x2c, 0x29, 0xbf, 0x37,
+	0xd8, 0xc8, 0x4a, 0xc7, 0x57, 0xea, 0x72, 0xf0, 0xda, 0x91, 0x4a, 0x9e, 0x5a, 0x31, 0x77, 0x6a,
+	0xbb, 0x6a, 0xa4, 0xd2, 0x47, 0x22, 0x1d, 0xc5, 0xbc, 0xdf, 0xd6, 0x1b, 0x77, 0x49, 0x34, 0x6e,
+	0x1d, 0xe7, 0xfc, 0xdd, 0x80, 0x86, 0x54, 0x14, 0x93, 0x71, 0x9c, 0x04, 0xac, 0xc4, 0x8d, 0x7d,
+	0x4a, 0x2e, 0xe3, 0xe4, 0x5a, 0x26, 0xe7, 0x12, 0xe6, 0xe1, 0xe7, 0xa7, 0x57, 0xde, 0x33, 0xa5,
+	0x8b, 0x80, 0x94, 0x4b, 0xcc, 0x35, 0xc3, 0x6d, 0x69, 0xfd, 0x70, 0x6b, 0xdd, 0x36, 0xdc, 0x96,
+	0xf3, 0xc3, 0xed, 0x47, 0xd0, 0x9c, 0xf9, 0xe3, 0x17, 0xfe, 0xe5, 0x32, 0x57, 0x37, 0x38, 0xc1,
+	0x0a, 0xd6, 0xd9, 0x00, 0xcb, 0x9d, 0xce, 0xe8, 0xb5, 0x73, 0x05, 0xf5, 0xe1, 0xfc, 0x22, 0xe5,
+	0xa3, 0x52, 0xa8, 0x57, 0x5a, 0x83, 0x2b, 0x27, 0x00, 0xf4, 0x21, 0x58, 0x2f, 0xc2, 0x28, 0x10,
+	0xa3, 0x46, 0x53, 0x5e, 0x23, 0xdd, 0x97, 0x24, 0xa2, 0x4f, 0xc2, 0x28, 0xc0, 0x62, 0x91, 0x55,
+	0xd3, 0xe7, 0x49, 0x3c, 0x1d, 0x52, 0x3f, 0x11, 0x1d, 0xae, 0x82, 0x33, 0x84, 0xf3, 0xbd, 0x05,
+	0x16, 0x67, 0x51, 0xe6, 0x1b, 0x99, 0xf9, 0xf7, 0xa1, 0x48, 0x17, 0xdc, 0x49, 0xeb, 0x5b, 0xdf,
+	0x71, 0x01, 0x17, 0xe9, 0x02, 0xfd, 0x00, 0x2a, 0xbe, 0x6c, 0x74, 0x5c, 0xfe, 0x6a, 0xf7, 0x3b,
+	0x2e, 0xe0, 0x25, 0x01, 0xfa, 0x14, 0x6a, 0x41, 0x96, 0xcd, 0x76, 0x49, 0x13, 0x9e, 0x4f, 0xf4,
+	0xe3, 0x02, 0xd6, 0x29, 0xd1, 0x4f, 0x00, 0xfc, 0x20, 0x50, 0xb1, 0x6f, 0x71, 0x3e, 0xa4, 0x87,
+	0xa8, 0x38, 0xf9, 0xe3, 0x02, 0xd6, 0xe8, 0xd0, 0x63, 0x68, 0x08, 0x21, 0x8a, 0xb1, 0xcc, 0x19,
+	0xef, 0xe8, 0x8c, 0x2a, 0x4f, 0x8e, 0x0b, 0x38, 0x4f, 0x8c, 0xf6, 0xc0, 0xe2, 0x15, 0x97, 0x1f,
+	0x97, 0xaa, 0x94, 0x5a, 0x52, 0x1e, 0x17, 0xb0, 0x20, 0x40, 0x47, 0xb0, 0x75, 0xb1, 0x3a, 0x5b,
+	0xf1, 0x6c, 0xae, 0x1d, 0xdc, 0xcd, 0xb8, 0xf4, 0xd5, 0xe3, 0x02, 0xbe, 0xc9, 0xc2, 0xe6, 0x08,
+	0x79, 0xf1, 0xaa, 0x72, 0xe6, 0x9a, 0xf4, 0x0c, 0x43, 0x1d, 0x17, 0x96, 0xf7, 0xb0, 0x1f, 0xb3,
+	0x4b, 0x00, 0x96, 0xef, 0x0f, 0x7c, 0x60, 0xad, 0x1d, 0x6c, 0xaa, 0xb7, 0x11, 0x39, 0x08, 0x31,
+	0x4f, 0x64, 0x44, 0xe8, 0x11, 0xd4, 0xe9, 0x72, 0x8d, 0x04, 0x76, 0xed, 0x36, 0xa6, 0x1c, 0x19,
+	0xfa, 0x11, 0x7b, 0x0d, 0xe8, 0x26, 0xf1, 0x6c, 0x46, 0x02, 0xbb, 0x7e, 0x1b, 0x4f, 0x46, 0x83,
+	0x1e, 0x01, 0xa8, 0x7b, 0x97, 0xb7, 0xb0, 0x1b, 0xaf, 0x0b, 0x1e, 0x8d, 0xf0, 0xb0, 0x0a, 0x1b,
+	0x33, 0xff, 0x7a, 0x12, 0xfb, 0x81, 0xf3, 0x18, 0xca, 0x9d, 0x79, 0x92, 0xc6, 0xc9, 0x2d, 0x61,
+	0x9f, 0x0b, 0xe8, 0xe2, 0x6a, 0x40, 0xb7, 0xc1, 0xc2, 0xfe, 0x2b, 0x6f, 0xc1, 0xef, 0x20, 0xd9,
+	0x76, 0xb2, 0x20, 0xe9, 0x28, 0x96, 0xc8, 0x41, 0x72, 0x8d, 0xe7, 0x91, 0x94, 0x22, 0x21, 0xe7,
+	0x6b, 0x68, 0x0c, 0x49, 0x14, 0x78, 0x8b, 0xb3, 0x24, 0xbe, 0x98, 0x90, 0x29, 0xfa, 0x78, 0x59,
+	0x1a, 0x0d, 0x5e, 0x1a, 0xb7, 0xb8, 0x3d, 0x82, 0x26, 0x5f, 0x13, 0x99, 0xca, 0x21, 0xaf, 0x53,
+	0xe2, 0xce, 0x24, 0x00, 0x56, 0x1a, 0xa6, 0x24, 0x4d, 0xfd, 0x4b, 0xa2, 0x1a, 0x91, 0x04, 0x9d,
+	0xbf, 0x19, 0xd0, 0x64, 0x82, 0xb8, 0xce, 0xec, 0xb0, 0xae, 0x75, 0x62, 0x23, 0x47, 0xbc, 0xb6,
+	0xc7, 0xb5, 0xc0, 0x7c, 0x4e, 0xd4, 0x1c, 0xc1, 0x3e, 0x6f, 0xa9, 0x5a, 0x5a, 0x75, 0xb2, 0xf2,
+	0xd5, 0x69, 0x1f, 0x2a, 0x33, 0x61, 0x68, 0x2a, 0x27, 0x52, 0xa4, 0xd9, 0x27, 0x7d, 0x80, 0x97,
+	0x34, 0x4c, 0x8b, 0x94, 0xc8, 0xf7, 0x9d, 0x0a, 0xe6, 0xdf, 0xce, 0x1f, 0x0c, 0xd8, 0x94, 0x25,
+	0xd9, 0x8b, 0xe5, 0x8d, 0xc9, 0x86, 0x8d, 0x76, 0xbe, 0x1b, 0xb4, 0xb3, 0x6e, 0x70, 0x9e, 0xeb,
+	0x06, 0xe7, 0xff, 0xcb, 0x6e, 0xf0, 0x9d, 0x01, 0x55, 0x26, 0x30, 0xed, 0xb2, 0xbb, 0xef, 0xc7,
+	0x60, 0x4e, 0xfd, 0x99, 0x9c, 0x75, 0xee, 0x71, 0xc3, 0x96, 0x8b, 0xfb, 0xa7, 0xfe, 0xcc, 0x8d,
+	0x68, 0x72, 0x8d, 0x19, 0xcd, 0xce, 0x09, 0x54, 0x14, 0x82, 0xb9, 0xf5, 0x05, 0xb9, 0x96, 0x8a,
+	0xb3, 0x4f, 0xf4, 0x00, 0xac, 0x97, 0xfe, 0x64, 0x4e, 0xec, 0xa2, 0x56, 0x42, 0xe4, 0xc6, 0xee,
+	0x82, 0x92, 0x28, 0x20, 0x01, 0x16, 0x24, 0x9f, 0x17, 0x3f, 0x33, 0x9c, 0x18, 0x36, 0x57, 0x56,
+	0x35, 0xbb, 0x8d, 0xd7, 0xd9, 0x5d, 0x7c, 0xb3, 0xdd, 0xe6, 0x1a, 0xbb, 0xef, 0x43, 0x95, 0x07,
+	0x50, 0x2f, 0x7a, 0x1e, 0xdf, 0x1e, 0x44, 0xce, 0x82, 0x05, 0x5c, 0xf2, 0x32, 0x1c, 0x93, 0xaf,
+	0x48, 0x92, 0xca, 0x3c, 0xb8, 0x48, 0xfc, 0x68, 0xac, 0x26, 0x04, 0x09, 0x31, 0xfc, 0x38, 0x9e,
+	0x4e, 0x43, 0xaa, 0x8e, 0x49, 0x40, 0xfc, 0x15, 0x6f, 0x1e, 0x4e, 0x02, 0xca, 0xde, 0xd1, 0x4c,
+	0xf9, 0xa2, 0xaa, 0x10, 0x6c, 0xe7, 0x89, 0x9f, 0x52, 0xea, 0x5f, 0xca, 0xbb, 0xa9, 0x02, 0x1f,
+	0xfc, 0x16, 0x1a, 0xb9, 0xa7, 0x5b, 0xb4, 0x05, 0x8d, 0xce, 0xb1, 0xdb, 0x79, 0x32, 0x3a, 0xef,
+	0x3f, 0xe9, 0x0f, 0x9e, 0xf6, 0x5b, 0x85, 0x0c, 0x75, 0xea, 0x9e, 0x9e, 0x0d, 0x06, 0x27, 0x2d,
+	0x03, 0x6d, 0xc3, 0xa6, 0x40, 0x75, 0x06, 0xfd, 0xa3, 0x1e, 0x3e, 0x75, 0xbb, 0xad, 0x22, 0xba,
+	0x03, 0xad, 0x0c, 0x79, 0xd2, 0xeb, 0x78, 0x6e, 0xb7, 0x65, 0x3e, 0x18, 0xc1, 0xf6, 0x9a, 0x81,
+	0x05, 0x21, 0x68, 0x62, 0xb7, 0x3d, 0x1c, 0xf4, 0xb5, 0x8d, 0xaa, 0x60, 0x9d, 0xf6, 0xfa, 0x6e,
+	0xb7, 0x65, 0xa0, 0x3a, 0x54, 0xb0, 0x7b, 0x76, 0xd2, 0xee, 0x70, 0xc9, 0x35, 0xd8, 0x70, 0xbf,
+	0x92, 0x02, 0x51, 0x13, 0x40, 0xdb, 0xa0, 0xf4, 0xe0, 0x3f, 0x06, 0x54, 0x97, 0x1d, 0x16, 0x6d,
+	0x80, 0xd9, 0x3e, 0x39, 0x69, 0x15, 0x10, 0x40, 0xb9, 0xef, 0x3e, 0x1d, 0x79, 0xcf, 0x5a, 0x06,
+	0x6a, 0x40, 0xb5, 0xdd, 0xed, 0x8e, 0x86, 0x67, 0x83, 0x73, 0xaf, 0x55, 0x44, 0x2d, 0xa8, 0x77,
+	0xdd, 0x13, 0xd7, 0x73, 0x25, 0xc6, 0x44, 0x9b, 0x50, 0x63, 0x04, 0xca, 0xc0, 0x12, 0x53, 0x4f,
+	0x92, 0x28, 0x9c, 0xc5, 0xa4, 0x30, 0x89, 0x87, 0x27, 0x83, 0xce, 0x93, 0x56, 0x19, 0xdd, 0x05,
+	0xc4, 0x3f, 0x47, 0xdd, 0xde, 0xb0, 0x33, 0xe8, 0xf7, 0x5d, 0xae, 0xcf, 0x06, 0xdb, 0x18, 0xbb,
+	0xc3, 0xdf, 0xf4, 0x3b, 0xad, 0x0a, 0x93, 0xeb, 0x3d, 0x1b, 0x2d, 0x2d, 0xa9, 0x32, 0x5f, 0x7a,
+	0xcf, 0x74, 0x07, 0x01, 0xb3, 0xc7, 0x7b, 0x36, 0xea, 0xe2, 0xc1, 0xd9, 0x99, 0xdb, 0x6d, 0xd5,
+	0x98, 0x76, 0x83, 0xb3, 0x11, 0x76, 0xbd, 0x73, 0xdc, 0x67, 0xea, 0xd7, 0x1f, 0xfc, 0xd9, 0x80,
+	0xba, 0x5e, 0xd9, 0x18, 0xc9, 0xd0, 0xed, 0x77, 0x35, 0xd7, 0x6d, 0x42, 0xed, 0xac, 0x8d, 0x87,
+	0xee, 0xc8, 0xc5, 0x78, 0x80, 0x5b, 0x06, 0xdb, 0xe8, 0xb4, 0x37, 0x1c, 0xf6, 0xfa, 0x5f, 0x8c,
+	0x7a, 0xfd, 0x33, 0x6e, 0xf6, 0x26, 0xd4, 0x86, 0x67, 0x6e, 0xdf, 0x93, 0x08, 0x6e, 0xf5, 0x91,
+	0xeb, 0x8e, 0xbc, 0xc1, 0x60, 0x74, 0x32, 0x78, 0xda, 0x2a, 0x31, 0x55, 0xda, 0x87, 0xc3, 0x73,
+	0xdc, 0x1d, 0x1d, 0xb9, 0x6e, 0xcb, 0x62, 0x04, 0xdd, 0xf3, 0xa1, 0x37, 0x1a, 0x9c, 0x7b, 0x8c,
+	0xa3, 0xcc, 0x5d, 0x30, 0xe8, 0x8f, 0x8e, 0x7a, 0xfd, 0xf6, 0x49, 0x6b, 0x83, 0x6d, 0xd2, 0x1f,
+	0x74, 0xdd, 0x11, 0x76, 0x7f, 0x2d, 0xac, 0xaf, 0x1c, 0xfc, 0x0e, 0x00, 0xf5, 0xe3, 0x80, 0x74,
+	0xe2, 0xe9, 0x74, 0x1e, 0x85, 0x63, 0xf9, 0xfe, 0xfb, 0x10, 0x6a, 0x32, 0xc2, 0x79, 0x2a, 0x80,
+	0x98, 0x8b, 0xd8, 0x60, 0xb5, 0xb3, 0x2d, 0x2b, 0x9b, 0x1e, 0xff, 0x4e, 0x01, 0x7d, 0x02, 0x9b,
+	0xfc, 0x54, 0x7b, 0x51, 0x48, 0x43, 0x7f, 0xd2, 0x0e, 0x02, 0xd4, 0xcc, 0x97, 0x8a, 0x9d, 0xa6,
+	0xec, 0xc4, 0x32, 0xc1, 0x9c, 0x02, 0x6b, 0x8d, 0xc3, 0xeb, 0x68, 0xcc, 0x62, 0x99, 0xa0, 0x1b,
+	0xb3, 0xc1, 0x1a, 0x86, 0x9f, 0x01, 0xe2, 0xbb, 0xb4, 0x83, 0xa0, 0x4f, 0x5e, 0xa9, 0x62, 0x28,
+	0x9a, 0x89, 0x3e, 0x47, 0xaf, 0x61, 0x7d, 0x04, 0xdb, 0x9c, 0xf5, 0x0b, 0x42, 0xb5, 0x3d, 0x72,
+	0xa6, 0xdd, 0xd0, 0xc0, 0x29, 0xa0, 0xcf, 0xe4, 0x8e, 0x5f, 0x10, 0xda, 0x9e, 0x4c, 0xd4, 0x58,
+	0xa3, 0x73, 0xad, 0x19, 0xa1, 0x9c, 0xc2, 0x43, 0x03, 0x3d, 0x86, 0xf7, 0x94, 0xae, 0xb9, 0x45,
+	0x24, 0x26, 0x12, 0xd1, 0xa0, 0x6f, 0xe5, 0xfe, 0xb9, 0xdc, 0xb7, 0x9b, 0x1b, 0xa7, 0x72, 0xac,
+	0x6b, 0x47, 0x30, 0xb9, 0xb5, 0x60, 0x16, 0x6d, 0x44, 0xb9, 0x29, 0x57, 0x6f, 0x55, 0x8f, 0x59,
+	0xe3, 0xa9, 0x4f, 0xa0, 0xc9, 0xb9, 0x97, 0x4d, 0x55, 0x9a, 0xcb, 0xbf, 0x97, 0xe7, 0xaf, 0x37,
+	0x5c, 0xa7, 0x80, 0x7e, 0x09, 0xf7, 0x34, 0x7d, 0x87, 0xec, 0xfa, 0xe5, 0x5f, 0x4c, 0x08, 0x9b,
+	0x3b, 0x73, 0x4a, 0xaf, 0x1b, 0x54, 0xb9, 0xce, 0x07, 0xd0, 0xe0, 0x02, 0xfa, 0xe4, 0x15, 0x3f,
+	0x81, 0x3c, 0xdb, 0x9a, 0xa3, 0x79, 0x68, 0xa0, 0x9f, 0xc2, 0x1d, 0xe5, 0xe2, 0xdb, 0x77, 0xcc,
+	0x8f, 0xd2, 0x9c, 0xef, 0x87, 0x60, 0xf5, 0x09, 0x33, 0x6c, 0x8d, 0x6a, 0xf9, 0x19, 0x4b, 0x92,
+	0x37, 0xf2, 0x9e, 0xcc, 0xb1, 0xe9, 0x03, 0x26, 0x27, 0x7f, 0x04, 0x4d, 0x5e, 0xa2, 0xc5, 0xdf,
+	0x2a, 0xf6, 0xe0, 0xa2, 0xe6, 0x3d, 0xf5, 0x2f, 0x4f, 0x9a, 0xa3, 0xfd, 0xce, 0x72, 0x0a, 0xa8,
+	0x0d, 0x77, 0xb9, 0x31, 0x37, 0x5f, 0x18, 0x73, 0xdb, 0xdd, 0x32, 0x0c, 0xf3, 0x9d, 0xf7, 0xa1,
+	0x2a, 0x2f, 0x3d, 0x17, 0x44, 0x66, 0x85, 0x7e, 0x09, 0xda, 0x81, 0xec, 0x7e, 0x23, 0x83, 0xac,
+	0xa6, 0x9e, 0x2c, 0xd8, 0xff, 0x9d, 0x7b, 0x37, 0xde, 0x31, 0xc4, 0x83, 0xce, 0xce, 0xd6, 0xea,
+	0x02, 0xd3, 0xf7, 0xff, 0x01, 0x58, 0xe5, 0x90, 0x2f, 0x62, 0x7a, 0x46, 0x08, 0x7d, 0xc5, 0x82,
+	0x56, 0x1a, 0xbc, 0x6c, 0x94, 0xce, 0x59, 0xb4, 0x3a, 0x0d, 0x4b, 0x27, 0x6e, 0x49, 0x26, 0x6d,
+	0x94, 0x7e, 0x33, 0xdb, 0x81, 0x8c, 0x5d, 0x6f, 0x39, 0x4d, 0xbf, 0x91, 0xe7, 0xa2, 0xcc, 0x7f,
+	0xb9, 0x7e, 0xf2, 0xdf, 0x01, 0x00, 0x4e, 0x11, 0x85, 0xe0, 0x84, 0x1d, 0x00, 0x00,
 }
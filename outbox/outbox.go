@@ -0,0 +1,90 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package outbox
+
+import (
+	"sync"
+
+	"github.com/Multy-io/Multy-BTC-node-service/storage"
+	"github.com/golang/protobuf/proto"
+)
+
+// Kinds of events sent to the client
+const (
+	KindNewTx             = "tx"
+	KindAddSpOut          = "spout.add"
+	KindDeleteSpOut       = "spout.delete"
+	KindAddMempool        = "mempool.add"
+	KindDeleteMempool     = "mempool.delete"
+	KindBlock             = "block"
+	KindBlockDisconnected = "block.disconnected"
+	KindResync            = "resync"
+	KindTxReplaced        = "tx.replaced"
+	KindTxConflicted      = "tx.conflicted"
+	KindTxDropped         = "tx.dropped"
+	// KindOpReturnTx is a transaction without watched addresses matched by OP_RETURN prefix
+	KindOpReturnTx = "tx.opreturn"
+)
+
+// Outbox is a durable sequenced queue of events for the client.
+// Every event gets a monotonically increasing sequence number,
+// so a reconnected client can continue from the last event it received.
+type Outbox struct {
+	storage storage.Storage
+
+	m      sync.Mutex
+	notify chan struct{}
+}
+
+// New creates outbox on top of the storage
+func New(storage storage.Storage) *Outbox {
+	return &Outbox{
+		storage: storage,
+		notify:  make(chan struct{}),
+	}
+}
+
+// Append stores the event and wakes up waiting streams.
+// Concurrent appends are not serialized here, so the storage can sync them together.
+func (o *Outbox) Append(kind string, msg proto.Message) (uint64, error) {
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return 0, err
+	}
+
+	seq, err := o.storage.AppendEvent(kind, data)
+	if err != nil {
+		return 0, err
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+	close(o.notify)
+	o.notify = make(chan struct{})
+	return seq, nil
+}
+
+// Wait returns a channel which is closed when the next event is appended
+func (o *Outbox) Wait() <-chan struct{} {
+	o.m.Lock()
+	defer o.m.Unlock()
+	return o.notify
+}
+
+// Since returns up to limit events of kinds after since sequence number
+func (o *Outbox) Since(since uint64, kinds []string, limit int) ([]storage.Event, error) {
+	return o.storage.EventsSince(since, kinds, limit)
+}
+
+// LastSeq returns sequence number of the last appended event
+func (o *Outbox) LastSeq() (uint64, error) {
+	return o.storage.LastEventSeq()
+}
+
+// FirstSeq returns sequence number of the oldest event kept for replay
+func (o *Outbox) FirstSeq() (uint64, error) {
+	return o.storage.FirstEventSeq()
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package node
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Secret values in configuration may refer to the environment or a file
+// instead of keeping the value in the config itself
+const (
+	secretEnvPrefix  = "env:"
+	secretFilePrefix = "file:"
+	secretMask       = "xxxxx"
+)
+
+// resolveSecret returns the value of "env:NAME" and "file:PATH" references,
+// other values are returned as they are
+func resolveSecret(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, secretEnvPrefix):
+		name := strings.TrimPrefix(value, secretEnvPrefix)
+		secret, ok := os.LookupEnv(name)
+		if !ok || secret == "" {
+			return "", fmt.Errorf("environment variable %s is empty", name)
+		}
+		return secret, nil
+	case strings.HasPrefix(value, secretFilePrefix):
+		path := strings.TrimPrefix(value, secretFilePrefix)
+		secret, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("secret file: %s", err.Error())
+		}
+		secret = bytes.TrimSpace(secret)
+		if len(secret) == 0 {
+			return "", fmt.Errorf("secret file %s is empty", path)
+		}
+		return string(secret), nil
+	}
+	return value, nil
+}
+
+// resolveSecrets replaces secret references in configuration by their values
+func (conf *Configuration) resolveSecrets() error {
+	secrets := map[string]*string{
+		"RPC.User":         &conf.RPC.User,
+		"RPC.Pass":         &conf.RPC.Pass,
+		"RPC.ProxyUser":    &conf.RPC.ProxyUser,
+		"RPC.ProxyPass":    &conf.RPC.ProxyPass,
+		"BTCAPI.Token":     &conf.BTCAPI.Token,
+		"Storage.MongoURL": &conf.Storage.MongoURL,
+	}
+	for name, value := range secrets {
+		secret, err := resolveSecret(*value)
+		if err != nil {
+			return fmt.Errorf("%s: %s", name, err.Error())
+		}
+		*value = secret
+	}
+	return nil
+}
+
+// maskSecret hides literal secret values, references are safe to show
+func maskSecret(value string) string {
+	if value == "" || strings.HasPrefix(value, secretEnvPrefix) || strings.HasPrefix(value, secretFilePrefix) {
+		return value
+	}
+	return secretMask
+}
+
+// maskURL hides the password of the URL
+func maskURL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.User == nil {
+		return maskSecret(value)
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), secretMask)
+	}
+	return u.String()
+}
+
+// String prints configuration without secrets so it's safe to log
+func (conf Configuration) String() string {
+	conf.RPC.Pass = maskSecret(conf.RPC.Pass)
+	conf.RPC.ProxyPass = maskSecret(conf.RPC.ProxyPass)
+	conf.BTCAPI.Token = maskSecret(conf.BTCAPI.Token)
+	conf.Storage.MongoURL = maskURL(conf.Storage.MongoURL)
+
+	// the alias has no String method
+	type configuration Configuration
+	return fmt.Sprintf("%+v", configuration(conf))
+}
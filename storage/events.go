@@ -0,0 +1,262 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+/*
+eventLog is an append only file of events.
+Events are kept in memory for replay, older ones are trimmed by age and size.
+Events appended concurrently share one sync of the file, readers see only
+synced events, so a sequence number is never reused after a crash.
+*/
+type eventLog struct {
+	m         sync.RWMutex
+	path      string
+	file      *os.File
+	events    []loggedEvent
+	lastSeq   uint64
+	retention Retention
+	// size of kept events, trimmed is the number of events trimmed since the file was rewritten
+	size    int
+	trimmed int
+	// synced is the seq of the last event on disk, syncing is set while the file is synced without the lock
+	synced   uint64
+	syncing  bool
+	syncDone *sync.Cond
+}
+
+// loggedEvent is a kept event with the size of its line in the file
+type loggedEvent struct {
+	Event
+	size int
+}
+
+func openEventLog(path string, retention Retention) (*eventLog, error) {
+	l := &eventLog{
+		path:      path,
+		retention: retention,
+	}
+	l.syncDone = sync.NewCond(&l.m)
+
+	f, err := os.Open(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		now := time.Now().Unix()
+		reader := bufio.NewReader(f)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if err == io.EOF {
+				// last event could be partially written on crash
+				break
+			}
+			if err != nil {
+				f.Close()
+				return nil, err
+			}
+			event := Event{}
+			if err := json.Unmarshal(line, &event); err != nil {
+				log.Errorf("openEventLog:json.Unmarshal: %s: %s", path, err.Error())
+				continue
+			}
+			if event.Seq <= l.lastSeq {
+				continue
+			}
+			// events written before they had time are kept for the whole retention
+			if event.Time == 0 {
+				event.Time = now
+			}
+			l.events = append(l.events, loggedEvent{Event: event, size: len(line)})
+			l.size += len(line)
+			l.lastSeq = event.Seq
+		}
+		f.Close()
+	}
+
+	l.trim()
+	if err := l.rewrite(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// append writes the event and returns when it's synced
+func (l *eventLog) append(kind string, data []byte) (uint64, error) {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	event := Event{
+		Seq:  l.lastSeq + 1,
+		Kind: kind,
+		Data: data,
+		Time: time.Now().Unix(),
+	}
+	line, err := json.Marshal(event)
+	if err != nil {
+		return 0, err
+	}
+	line = append(line, '\n')
+	if _, err := l.file.Write(line); err != nil {
+		return 0, err
+	}
+
+	l.lastSeq = event.Seq
+	l.events = append(l.events, loggedEvent{Event: event, size: len(line)})
+	l.size += len(line)
+
+	for l.synced < event.Seq {
+		if l.syncing {
+			l.syncDone.Wait()
+			continue
+		}
+		// one sync covers all events written before it
+		l.syncing = true
+		target := l.lastSeq
+		file := l.file
+		l.m.Unlock()
+		err := file.Sync()
+		l.m.Lock()
+		l.syncing = false
+		l.syncDone.Broadcast()
+		if err != nil {
+			return 0, err
+		}
+		if target > l.synced {
+			l.synced = target
+		}
+	}
+
+	l.trim()
+	// the file is rewritten when most of it is trimmed
+	if l.trimmed > len(l.events) {
+		if err := l.rewrite(); err != nil {
+			log.Errorf("eventLog.append:rewrite: %s", err.Error())
+		}
+	}
+	return event.Seq, nil
+}
+
+// trim drops events older than retention or above its size, lock must be held
+func (l *eventLog) trim() {
+	oldest := time.Now().Add(-l.retention.MaxAge).Unix()
+	n := 0
+	for ; n < len(l.events); n++ {
+		event := l.events[n]
+		if event.Time >= oldest && l.size <= l.retention.MaxSize {
+			break
+		}
+		l.size -= event.size
+	}
+	if n == 0 {
+		return
+	}
+	l.events = append([]loggedEvent{}, l.events[n:]...)
+	l.trimmed += n
+}
+
+func (l *eventLog) since(since uint64, kinds []string, limit int) []Event {
+	l.m.RLock()
+	defer l.m.RUnlock()
+
+	events := []Event{}
+	i := sort.Search(len(l.events), func(i int) bool {
+		return l.events[i].Seq > since
+	})
+	for ; i < len(l.events) && len(events) < limit && l.events[i].Seq <= l.synced; i++ {
+		if hasKind(kinds, l.events[i].Kind) {
+			events = append(events, l.events[i].Event)
+		}
+	}
+	return events
+}
+
+func (l *eventLog) last() uint64 {
+	l.m.RLock()
+	defer l.m.RUnlock()
+	return l.synced
+}
+
+func (l *eventLog) first() uint64 {
+	l.m.RLock()
+	defer l.m.RUnlock()
+	if len(l.events) == 0 {
+		return l.lastSeq + 1
+	}
+	return l.events[0].Seq
+}
+
+// rewrite replaces the file with events kept in memory, lock must be held
+func (l *eventLog) rewrite() error {
+	for l.syncing {
+		l.syncDone.Wait()
+	}
+	if l.file != nil {
+		l.file.Close()
+		l.file = nil
+	}
+
+	tmpPath := l.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(tmp)
+	size := 0
+	for i, event := range l.events {
+		line, err := json.Marshal(event.Event)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		writer.Write(line)
+		writer.WriteByte('\n')
+		l.events[i].size = len(line) + 1
+		size += len(line) + 1
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	tmp.Close()
+
+	if err := os.Rename(tmpPath, l.path); err != nil {
+		return err
+	}
+	l.size = size
+	l.trimmed = 0
+	l.synced = l.lastSeq
+
+	l.file, err = os.OpenFile(l.path, os.O_APPEND|os.O_WRONLY, 0600)
+	return err
+}
+
+func (l *eventLog) close() error {
+	l.m.Lock()
+	defer l.m.Unlock()
+	for l.syncing {
+		l.syncDone.Wait()
+	}
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
@@ -0,0 +1,404 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/Multy-io/Multy-back/store"
+)
+
+const (
+	lastBlockKey  = "lastblock"
+	backfilledKey = "backfilled"
+
+	// indexBatchSize is a number of index records written with one sync
+	indexBatchSize = 1000
+)
+
+// FileStorage is an embedded on-disk storage.
+// Every collection is kept in memory and journaled to its own file.
+type FileStorage struct {
+	m         sync.RWMutex
+	addresses map[string]store.AddressExtended
+	// outpoint to journal key to output of an owner
+	spOuts    map[string]map[string]store.SpendableOutputs
+	lastBlock BlockState
+	confTxs   map[string]ConfirmingTx
+	// address to txid to height, indexTxs is txid to height
+	index       map[string]map[string]int64
+	indexHeight map[int64][]AddressTx
+	indexTxs    map[string]int64
+	backfilled  int64
+	// indexM orders index changes in the journal, fs.m is held only to change memory
+	indexM sync.Mutex
+
+	addressesLog *journal
+	spOutsLog    *journal
+	stateLog     *journal
+	confTxsLog   *journal
+	indexLog     *journal
+	events       *eventLog
+}
+
+// NewFileStorage opens or creates the storage in dir
+// keeping events within eventsRetention
+func NewFileStorage(dir string, eventsRetention Retention) (*FileStorage, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	fs := &FileStorage{
+		addresses: map[string]store.AddressExtended{},
+		spOuts:    map[string]map[string]store.SpendableOutputs{},
+		confTxs:   map[string]ConfirmingTx{},
+
+		index:       map[string]map[string]int64{},
+		indexHeight: map[int64][]AddressTx{},
+		indexTxs:    map[string]int64{},
+	}
+
+	var values map[string]json.RawMessage
+	var err error
+
+	fs.addressesLog, values, err = openJournal(filepath.Join(dir, "addresses.log"))
+	if err != nil {
+		return nil, err
+	}
+	for address, raw := range values {
+		ex := store.AddressExtended{}
+		if err := json.Unmarshal(raw, &ex); err != nil {
+			log.Errorf("NewFileStorage:json.Unmarshal: address %s: %s", address, err.Error())
+			continue
+		}
+		fs.addresses[address] = ex
+	}
+
+	fs.spOutsLog, values, err = openJournal(filepath.Join(dir, "spendable.log"))
+	if err != nil {
+		return nil, err
+	}
+	for key, raw := range values {
+		spOut := store.SpendableOutputs{}
+		if err := json.Unmarshal(raw, &spOut); err != nil {
+			log.Errorf("NewFileStorage:json.Unmarshal: spendable output %s: %s", key, err.Error())
+			continue
+		}
+		fs.addSpOut(key, spOut)
+	}
+
+	fs.stateLog, values, err = openJournal(filepath.Join(dir, "state.log"))
+	if err != nil {
+		return nil, err
+	}
+	if raw, ok := values[lastBlockKey]; ok {
+		if err := json.Unmarshal(raw, &fs.lastBlock); err != nil {
+			log.Errorf("NewFileStorage:json.Unmarshal: last block: %s", err.Error())
+		}
+	}
+	if raw, ok := values[backfilledKey]; ok {
+		if err := json.Unmarshal(raw, &fs.backfilled); err != nil {
+			log.Errorf("NewFileStorage:json.Unmarshal: backfilled height: %s", err.Error())
+		}
+	}
+
+	fs.confTxsLog, values, err = openJournal(filepath.Join(dir, "confirming.log"))
+	if err != nil {
+		return nil, err
+	}
+	for txid, raw := range values {
+		tx := ConfirmingTx{}
+		if err := json.Unmarshal(raw, &tx); err != nil {
+			log.Errorf("NewFileStorage:json.Unmarshal: confirming tx %s: %s", txid, err.Error())
+			continue
+		}
+		fs.confTxs[txid] = tx
+	}
+
+	fs.indexLog, values, err = openJournal(filepath.Join(dir, "index.log"))
+	if err != nil {
+		return nil, err
+	}
+	for key, raw := range values {
+		tx := AddressTx{}
+		if err := json.Unmarshal(raw, &tx); err != nil {
+			log.Errorf("NewFileStorage:json.Unmarshal: index %s: %s", key, err.Error())
+			continue
+		}
+		fs.addIndex(tx)
+	}
+
+	fs.events, err = openEventLog(filepath.Join(dir, "events.log"), eventsRetention)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("File storage %s: %d addresses, %d spendable outputs, last block %d, last event %d", dir, len(fs.addresses), len(fs.spOuts), fs.lastBlock.Height, fs.events.lastSeq)
+	return fs, nil
+}
+
+func (fs *FileStorage) Addresses() (map[string]store.AddressExtended, error) {
+	fs.m.RLock()
+	defer fs.m.RUnlock()
+	addresses := make(map[string]store.AddressExtended, len(fs.addresses))
+	for address, ex := range fs.addresses {
+		addresses[address] = ex
+	}
+	return addresses, nil
+}
+
+func (fs *FileStorage) SetAddresses(addresses map[string]store.AddressExtended) error {
+	fs.m.Lock()
+	defer fs.m.Unlock()
+	values := make(map[string]interface{}, len(addresses))
+	fs.addresses = make(map[string]store.AddressExtended, len(addresses))
+	for address, ex := range addresses {
+		fs.addresses[address] = ex
+		values[address] = ex
+	}
+	return fs.addressesLog.reset(values)
+}
+
+func (fs *FileStorage) AddAddress(address string, ex store.AddressExtended) error {
+	fs.m.Lock()
+	defer fs.m.Unlock()
+	fs.addresses[address] = ex
+	return fs.addressesLog.put(address, ex)
+}
+
+func (fs *FileStorage) LastBlock() (BlockState, error) {
+	fs.m.RLock()
+	defer fs.m.RUnlock()
+	return fs.lastBlock, nil
+}
+
+func (fs *FileStorage) SetLastBlock(block BlockState) error {
+	fs.m.Lock()
+	defer fs.m.Unlock()
+	fs.lastBlock = block
+	return fs.stateLog.put(lastBlockKey, block)
+}
+
+func (fs *FileStorage) SpendableOutputs() ([]store.SpendableOutputs, error) {
+	fs.m.RLock()
+	defer fs.m.RUnlock()
+	spOuts := make([]store.SpendableOutputs, 0, len(fs.spOuts))
+	for _, owners := range fs.spOuts {
+		for _, spOut := range owners {
+			spOuts = append(spOuts, spOut)
+		}
+	}
+	return spOuts, nil
+}
+
+// addSpOut puts the output to memory by its journal key, lock must be held
+func (fs *FileStorage) addSpOut(key string, spOut store.SpendableOutputs) {
+	outpoint := outpointKey(spOut.TxID, spOut.TxOutID)
+	if fs.spOuts[outpoint] == nil {
+		fs.spOuts[outpoint] = map[string]store.SpendableOutputs{}
+	}
+	fs.spOuts[outpoint][key] = spOut
+}
+
+func (fs *FileStorage) AddSpendableOutput(spOut store.SpendableOutputs) error {
+	key := spOutKey(spOut)
+	fs.m.Lock()
+	defer fs.m.Unlock()
+	fs.addSpOut(key, spOut)
+	return fs.spOutsLog.put(key, spOut)
+}
+
+func (fs *FileStorage) DeleteSpendableOutput(txid string, index int) error {
+	outpoint := outpointKey(txid, index)
+	fs.m.Lock()
+	defer fs.m.Unlock()
+	owners, ok := fs.spOuts[outpoint]
+	if !ok {
+		return nil
+	}
+	delete(fs.spOuts, outpoint)
+	batch := journalBatch{}
+	for key := range owners {
+		batch.delete(key)
+	}
+	return fs.spOutsLog.commit(batch)
+}
+
+func (fs *FileStorage) ConfirmingTxs() ([]ConfirmingTx, error) {
+	fs.m.RLock()
+	defer fs.m.RUnlock()
+	txs := make([]ConfirmingTx, 0, len(fs.confTxs))
+	for _, tx := range fs.confTxs {
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
+
+func (fs *FileStorage) AddConfirmingTx(tx ConfirmingTx) error {
+	fs.m.Lock()
+	defer fs.m.Unlock()
+	fs.confTxs[tx.TxID] = tx
+	return fs.confTxsLog.put(tx.TxID, tx)
+}
+
+func (fs *FileStorage) DeleteConfirmingTx(txid string) error {
+	fs.m.Lock()
+	defer fs.m.Unlock()
+	if _, ok := fs.confTxs[txid]; !ok {
+		return nil
+	}
+	delete(fs.confTxs, txid)
+	return fs.confTxsLog.delete(txid)
+}
+
+func (fs *FileStorage) AddressTxs(address string) ([]AddressTx, error) {
+	fs.m.RLock()
+	defer fs.m.RUnlock()
+	txs := []AddressTx{}
+	for txid, height := range fs.index[address] {
+		txs = append(txs, AddressTx{
+			Address: address,
+			TxID:    txid,
+			Height:  height,
+		})
+	}
+	sortAddressTxs(txs)
+	return txs, nil
+}
+
+func (fs *FileStorage) TxHeight(txid string) (int64, bool, error) {
+	fs.m.RLock()
+	defer fs.m.RUnlock()
+	height, ok := fs.indexTxs[txid]
+	return height, ok, nil
+}
+
+// IndexTxs writes records in batches, the storage is not locked while a batch is synced,
+// so a long backfill doesn't block block processing
+func (fs *FileStorage) IndexTxs(height int64, txs []AddressTx) error {
+	fs.indexM.Lock()
+	defer fs.indexM.Unlock()
+	for len(txs) > 0 {
+		n := indexBatchSize
+		if n > len(txs) {
+			n = len(txs)
+		}
+		batch, err := fs.indexBatch(height, txs[:n])
+		if err != nil {
+			return err
+		}
+		if err := fs.indexLog.commit(batch); err != nil {
+			return err
+		}
+		txs = txs[n:]
+	}
+	return nil
+}
+
+// indexBatch adds records to memory and returns their journal batch
+func (fs *FileStorage) indexBatch(height int64, txs []AddressTx) (journalBatch, error) {
+	fs.m.Lock()
+	defer fs.m.Unlock()
+	batch := journalBatch{}
+	for _, tx := range txs {
+		tx.Height = height
+		if h, ok := fs.index[tx.Address][tx.TxID]; ok && h == height {
+			continue
+		}
+		fs.addIndex(tx)
+		if err := batch.put(indexKey(tx), tx); err != nil {
+			return nil, err
+		}
+	}
+	return batch, nil
+}
+
+func (fs *FileStorage) UnindexHeight(height int64) error {
+	fs.indexM.Lock()
+	defer fs.indexM.Unlock()
+
+	fs.m.Lock()
+	batch := journalBatch{}
+	for _, tx := range fs.indexHeight[height] {
+		if fs.index[tx.Address][tx.TxID] != height {
+			continue
+		}
+		delete(fs.index[tx.Address], tx.TxID)
+		if len(fs.index[tx.Address]) == 0 {
+			delete(fs.index, tx.Address)
+		}
+		if fs.indexTxs[tx.TxID] == height {
+			delete(fs.indexTxs, tx.TxID)
+		}
+		batch.delete(indexKey(tx))
+	}
+	delete(fs.indexHeight, height)
+	fs.m.Unlock()
+
+	return fs.indexLog.commit(batch)
+}
+
+// addIndex adds the record to in-memory index, fs.m must be held
+func (fs *FileStorage) addIndex(tx AddressTx) {
+	txs, ok := fs.index[tx.Address]
+	if !ok {
+		txs = map[string]int64{}
+		fs.index[tx.Address] = txs
+	}
+	txs[tx.TxID] = tx.Height
+	fs.indexTxs[tx.TxID] = tx.Height
+	fs.indexHeight[tx.Height] = append(fs.indexHeight[tx.Height], tx)
+}
+
+func indexKey(tx AddressTx) string {
+	return tx.Address + ":" + tx.TxID
+}
+
+func (fs *FileStorage) BackfilledHeight() (int64, error) {
+	fs.m.RLock()
+	defer fs.m.RUnlock()
+	return fs.backfilled, nil
+}
+
+func (fs *FileStorage) SetBackfilledHeight(height int64) error {
+	fs.m.Lock()
+	defer fs.m.Unlock()
+	fs.backfilled = height
+	return fs.stateLog.put(backfilledKey, height)
+}
+
+func (fs *FileStorage) AppendEvent(kind string, data []byte) (uint64, error) {
+	return fs.events.append(kind, data)
+}
+
+func (fs *FileStorage) EventsSince(since uint64, kinds []string, limit int) ([]Event, error) {
+	return fs.events.since(since, kinds, limit), nil
+}
+
+func (fs *FileStorage) LastEventSeq() (uint64, error) {
+	return fs.events.last(), nil
+}
+
+func (fs *FileStorage) FirstEventSeq() (uint64, error) {
+	return fs.events.first(), nil
+}
+
+func (fs *FileStorage) Close() error {
+	fs.m.Lock()
+	defer fs.m.Unlock()
+	if err := fs.events.close(); err != nil {
+		return err
+	}
+	for _, j := range []*journal{fs.addressesLog, fs.spOutsLog, fs.stateLog, fs.confTxsLog, fs.indexLog} {
+		if err := j.close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+/*
+journal is an append only log of key-value changes.
+It is replayed and compacted on open.
+Changes written concurrently share one sync of the file, a batch of changes
+is written with a single sync.
+*/
+type journal struct {
+	m    sync.Mutex
+	path string
+	file *os.File
+	// written and synced count batches, syncing is set while the file is synced without the lock
+	written  uint64
+	synced   uint64
+	syncing  bool
+	syncDone *sync.Cond
+}
+
+type journalRecord struct {
+	Key    string          `json:"k"`
+	Value  json.RawMessage `json:"v,omitempty"`
+	Delete bool            `json:"d,omitempty"`
+}
+
+// journalBatch is a set of changes committed together
+type journalBatch []journalRecord
+
+func (b *journalBatch) put(key string, value interface{}) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	*b = append(*b, journalRecord{Key: key, Value: raw})
+	return nil
+}
+
+func (b *journalBatch) delete(key string) {
+	*b = append(*b, journalRecord{Key: key, Delete: true})
+}
+
+// openJournal replays the journal file and returns its current values
+func openJournal(path string) (*journal, map[string]json.RawMessage, error) {
+	values := map[string]json.RawMessage{}
+
+	f, err := os.Open(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, err
+	}
+	if err == nil {
+		reader := bufio.NewReader(f)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if err == io.EOF {
+				// last record could be partially written on crash
+				break
+			}
+			if err != nil {
+				f.Close()
+				return nil, nil, err
+			}
+			rec := journalRecord{}
+			if err := json.Unmarshal(line, &rec); err != nil {
+				log.Errorf("openJournal:json.Unmarshal: %s: %s", path, err.Error())
+				continue
+			}
+			if rec.Delete {
+				delete(values, rec.Key)
+				continue
+			}
+			values[rec.Key] = rec.Value
+		}
+		f.Close()
+	}
+
+	j := &journal{path: path}
+	j.syncDone = sync.NewCond(&j.m)
+	if err := j.rewrite(values); err != nil {
+		return nil, nil, err
+	}
+	return j, values, nil
+}
+
+func (j *journal) put(key string, value interface{}) error {
+	batch := journalBatch{}
+	if err := batch.put(key, value); err != nil {
+		return err
+	}
+	return j.commit(batch)
+}
+
+func (j *journal) delete(key string) error {
+	batch := journalBatch{}
+	batch.delete(key)
+	return j.commit(batch)
+}
+
+// commit writes the batch and returns when it's synced
+func (j *journal) commit(batch journalBatch) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	lines := []byte{}
+	for _, rec := range batch {
+		line, err := json.Marshal(rec)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, line...)
+		lines = append(lines, '\n')
+	}
+
+	j.m.Lock()
+	defer j.m.Unlock()
+	if _, err := j.file.Write(lines); err != nil {
+		return err
+	}
+	j.written++
+	seq := j.written
+
+	for j.synced < seq {
+		if j.syncing {
+			j.syncDone.Wait()
+			continue
+		}
+		// one sync covers all batches written before it
+		j.syncing = true
+		target := j.written
+		file := j.file
+		j.m.Unlock()
+		err := file.Sync()
+		j.m.Lock()
+		j.syncing = false
+		j.syncDone.Broadcast()
+		if err != nil {
+			return err
+		}
+		if target > j.synced {
+			j.synced = target
+		}
+	}
+	return nil
+}
+
+// reset replaces all journal values
+func (j *journal) reset(values map[string]interface{}) error {
+	raw := make(map[string]json.RawMessage, len(values))
+	for key, value := range values {
+		r, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		raw[key] = r
+	}
+	return j.rewrite(raw)
+}
+
+// rewrite atomically replaces the journal file with a snapshot of values
+func (j *journal) rewrite(values map[string]json.RawMessage) error {
+	j.m.Lock()
+	defer j.m.Unlock()
+	for j.syncing {
+		j.syncDone.Wait()
+	}
+
+	if j.file != nil {
+		j.file.Close()
+		j.file = nil
+	}
+
+	tmpPath := j.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(tmp)
+	for key, value := range values {
+		line, err := json.Marshal(journalRecord{Key: key, Value: value})
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		writer.Write(line)
+		writer.WriteByte('\n')
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	tmp.Close()
+
+	if err := os.Rename(tmpPath, j.path); err != nil {
+		return err
+	}
+	j.synced = j.written
+
+	j.file, err = os.OpenFile(j.path, os.O_APPEND|os.O_WRONLY, 0600)
+	return err
+}
+
+func (j *journal) close() error {
+	j.m.Lock()
+	defer j.m.Unlock()
+	for j.syncing {
+		j.syncDone.Wait()
+	}
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
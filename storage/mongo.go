@@ -0,0 +1,327 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package storage
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Multy-io/Multy-back/store"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	collectionAddresses = "addresses"
+	collectionSpOuts    = "spendableOutputs"
+	collectionState     = "state"
+	collectionConfTxs   = "confirmingTxs"
+	collectionEvents    = "events"
+	collectionIndex     = "addressIndex"
+
+	eventSeqKey = "eventseq"
+	// trimEvery is how often old events are removed
+	trimEvery = 1000
+)
+
+// MongoStorage keeps the service state in mongodb
+type MongoStorage struct {
+	session *mgo.Session
+	db      *mgo.Database
+
+	// appends are serialized so readers never see a gap in sequence numbers
+	eventsM         sync.Mutex
+	eventsRetention Retention
+}
+
+type addressRecord struct {
+	Address      string `bson:"_id"`
+	UserID       string `bson:"userid"`
+	WalletIndex  int    `bson:"walletindex"`
+	AddressIndex int    `bson:"addressindex"`
+}
+
+type spOutRecord struct {
+	ID string `bson:"_id"`
+	// Outpoint is shared by records of owners of the same output
+	Outpoint               string `bson:"outpoint"`
+	store.SpendableOutputs `bson:",inline"`
+}
+
+// NewMongoStorage connects to mongodb by url and uses dbName database
+// keeping events for eventsRetention.MaxAge
+func NewMongoStorage(url, dbName string, eventsRetention Retention) (*MongoStorage, error) {
+	session, err := mgo.Dial(url)
+	if err != nil {
+		return nil, err
+	}
+	session.SetMode(mgo.Monotonic, true)
+	db := session.DB(dbName)
+	if err := db.C(collectionIndex).EnsureIndexKey("address", "height"); err != nil {
+		session.Close()
+		return nil, err
+	}
+	if err := db.C(collectionIndex).EnsureIndexKey("height"); err != nil {
+		session.Close()
+		return nil, err
+	}
+	if err := db.C(collectionIndex).EnsureIndexKey("txid"); err != nil {
+		session.Close()
+		return nil, err
+	}
+	if err := db.C(collectionSpOuts).EnsureIndexKey("outpoint"); err != nil {
+		session.Close()
+		return nil, err
+	}
+	if err := db.C(collectionEvents).EnsureIndexKey("time"); err != nil {
+		session.Close()
+		return nil, err
+	}
+	log.Infof("Mongo storage %s/%s", url, dbName)
+	return &MongoStorage{
+		session:         session,
+		db:              db,
+		eventsRetention: eventsRetention,
+	}, nil
+}
+
+func (ms *MongoStorage) Addresses() (map[string]store.AddressExtended, error) {
+	addresses := map[string]store.AddressExtended{}
+	iter := ms.db.C(collectionAddresses).Find(nil).Iter()
+	rec := addressRecord{}
+	for iter.Next(&rec) {
+		addresses[rec.Address] = store.AddressExtended{
+			UserID:       rec.UserID,
+			WalletIndex:  rec.WalletIndex,
+			AddressIndex: rec.AddressIndex,
+		}
+	}
+	return addresses, iter.Close()
+}
+
+func (ms *MongoStorage) SetAddresses(addresses map[string]store.AddressExtended) error {
+	collection := ms.db.C(collectionAddresses)
+	if _, err := collection.RemoveAll(nil); err != nil {
+		return err
+	}
+	bulk := collection.Bulk()
+	bulk.Unordered()
+	for address, ex := range addresses {
+		bulk.Insert(newAddressRecord(address, ex))
+	}
+	_, err := bulk.Run()
+	return err
+}
+
+func (ms *MongoStorage) AddAddress(address string, ex store.AddressExtended) error {
+	_, err := ms.db.C(collectionAddresses).UpsertId(address, newAddressRecord(address, ex))
+	return err
+}
+
+func newAddressRecord(address string, ex store.AddressExtended) addressRecord {
+	return addressRecord{
+		Address:      address,
+		UserID:       ex.UserID,
+		WalletIndex:  ex.WalletIndex,
+		AddressIndex: ex.AddressIndex,
+	}
+}
+
+func (ms *MongoStorage) LastBlock() (BlockState, error) {
+	block := BlockState{}
+	err := ms.db.C(collectionState).FindId(lastBlockKey).One(&block)
+	if err == mgo.ErrNotFound {
+		return block, nil
+	}
+	return block, err
+}
+
+func (ms *MongoStorage) SetLastBlock(block BlockState) error {
+	_, err := ms.db.C(collectionState).UpsertId(lastBlockKey, bson.M{
+		"hash":   block.Hash,
+		"height": block.Height,
+	})
+	return err
+}
+
+func (ms *MongoStorage) SpendableOutputs() ([]store.SpendableOutputs, error) {
+	spOuts := []store.SpendableOutputs{}
+	iter := ms.db.C(collectionSpOuts).Find(nil).Iter()
+	rec := spOutRecord{}
+	for iter.Next(&rec) {
+		spOuts = append(spOuts, rec.SpendableOutputs)
+	}
+	return spOuts, iter.Close()
+}
+
+func (ms *MongoStorage) AddSpendableOutput(spOut store.SpendableOutputs) error {
+	key := spOutKey(spOut)
+	_, err := ms.db.C(collectionSpOuts).UpsertId(key, spOutRecord{
+		ID:               key,
+		Outpoint:         outpointKey(spOut.TxID, spOut.TxOutID),
+		SpendableOutputs: spOut,
+	})
+	return err
+}
+
+func (ms *MongoStorage) DeleteSpendableOutput(txid string, index int) error {
+	_, err := ms.db.C(collectionSpOuts).RemoveAll(bson.M{"outpoint": outpointKey(txid, index)})
+	return err
+}
+
+func (ms *MongoStorage) ConfirmingTxs() ([]ConfirmingTx, error) {
+	txs := []ConfirmingTx{}
+	err := ms.db.C(collectionConfTxs).Find(nil).All(&txs)
+	return txs, err
+}
+
+func (ms *MongoStorage) AddConfirmingTx(tx ConfirmingTx) error {
+	_, err := ms.db.C(collectionConfTxs).UpsertId(tx.TxID, tx)
+	return err
+}
+
+func (ms *MongoStorage) DeleteConfirmingTx(txid string) error {
+	err := ms.db.C(collectionConfTxs).RemoveId(txid)
+	if err == mgo.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+type indexRecord struct {
+	ID        string `bson:"_id"`
+	AddressTx `bson:",inline"`
+}
+
+func (ms *MongoStorage) AddressTxs(address string) ([]AddressTx, error) {
+	records := []indexRecord{}
+	err := ms.db.C(collectionIndex).Find(bson.M{"address": address}).Sort("height").All(&records)
+	txs := make([]AddressTx, 0, len(records))
+	for _, record := range records {
+		txs = append(txs, record.AddressTx)
+	}
+	return txs, err
+}
+
+func (ms *MongoStorage) TxHeight(txid string) (int64, bool, error) {
+	record := indexRecord{}
+	err := ms.db.C(collectionIndex).Find(bson.M{"txid": txid}).One(&record)
+	if err == mgo.ErrNotFound {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return record.Height, true, nil
+}
+
+func (ms *MongoStorage) IndexTxs(height int64, txs []AddressTx) error {
+	if len(txs) == 0 {
+		return nil
+	}
+	bulk := ms.db.C(collectionIndex).Bulk()
+	bulk.Unordered()
+	for _, tx := range txs {
+		tx.Height = height
+		id := tx.Address + ":" + tx.TxID
+		bulk.Upsert(bson.M{"_id": id}, indexRecord{ID: id, AddressTx: tx})
+	}
+	_, err := bulk.Run()
+	return err
+}
+
+func (ms *MongoStorage) UnindexHeight(height int64) error {
+	_, err := ms.db.C(collectionIndex).RemoveAll(bson.M{"height": height})
+	return err
+}
+
+func (ms *MongoStorage) BackfilledHeight() (int64, error) {
+	state := struct {
+		Height int64 `bson:"height"`
+	}{}
+	err := ms.db.C(collectionState).FindId(backfilledKey).One(&state)
+	if err == mgo.ErrNotFound {
+		return 0, nil
+	}
+	return state.Height, err
+}
+
+func (ms *MongoStorage) SetBackfilledHeight(height int64) error {
+	_, err := ms.db.C(collectionState).UpsertId(backfilledKey, bson.M{"height": height})
+	return err
+}
+
+func (ms *MongoStorage) AppendEvent(kind string, data []byte) (uint64, error) {
+	ms.eventsM.Lock()
+	defer ms.eventsM.Unlock()
+
+	counter := struct {
+		Seq uint64 `bson:"seq"`
+	}{}
+	_, err := ms.db.C(collectionState).FindId(eventSeqKey).Apply(mgo.Change{
+		Update:    bson.M{"$inc": bson.M{"seq": 1}},
+		Upsert:    true,
+		ReturnNew: true,
+	}, &counter)
+	if err != nil {
+		return 0, err
+	}
+
+	err = ms.db.C(collectionEvents).Insert(Event{
+		Seq:  counter.Seq,
+		Kind: kind,
+		Data: data,
+		Time: time.Now().Unix(),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if counter.Seq%trimEvery == 0 {
+		oldest := time.Now().Add(-ms.eventsRetention.MaxAge).Unix()
+		_, err := ms.db.C(collectionEvents).RemoveAll(bson.M{"time": bson.M{"$lt": oldest}})
+		if err != nil {
+			log.Errorf("AppendEvent:RemoveAll: %s", err.Error())
+		}
+	}
+	return counter.Seq, nil
+}
+
+func (ms *MongoStorage) EventsSince(since uint64, kinds []string, limit int) ([]Event, error) {
+	query := bson.M{"_id": bson.M{"$gt": since}}
+	if len(kinds) > 0 {
+		query["kind"] = bson.M{"$in": kinds}
+	}
+	events := []Event{}
+	err := ms.db.C(collectionEvents).Find(query).Sort("_id").Limit(limit).All(&events)
+	return events, err
+}
+
+func (ms *MongoStorage) LastEventSeq() (uint64, error) {
+	counter := struct {
+		Seq uint64 `bson:"seq"`
+	}{}
+	err := ms.db.C(collectionState).FindId(eventSeqKey).One(&counter)
+	if err == mgo.ErrNotFound {
+		return 0, nil
+	}
+	return counter.Seq, err
+}
+
+func (ms *MongoStorage) FirstEventSeq() (uint64, error) {
+	event := Event{}
+	err := ms.db.C(collectionEvents).Find(nil).Sort("_id").Select(bson.M{"_id": 1}).One(&event)
+	if err == mgo.ErrNotFound {
+		last, err := ms.LastEventSeq()
+		return last + 1, err
+	}
+	return event.Seq, err
+}
+
+func (ms *MongoStorage) Close() error {
+	ms.session.Close()
+	return nil
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Multy-io/Multy-back/store"
+	"github.com/jekabolt/slf"
+	_ "github.com/jekabolt/slflog"
+)
+
+var log = slf.WithContext("storage").WithCaller(slf.CallerShort)
+
+const (
+	TypeFile  = "file"
+	TypeMongo = "mongo"
+
+	defaultPath = "data"
+
+	defaultEventsRetentionHours = 72
+	defaultEventsRetentionMB    = 512
+)
+
+// Conf is a configuration of the service persistent storage
+type Conf struct {
+	// Type is "file" (default) or "mongo"
+	Type string
+	// Path is a directory of the file storage
+	Path string
+	// MongoURL and MongoDB are used by the mongo storage
+	MongoURL string
+	MongoDB  string
+	// EventsRetentionHours is how long events are kept for replay, 72 by default
+	EventsRetentionHours int
+	// EventsRetentionMB limits the size of kept events in megabytes, 512 by default.
+	// It's used by the file storage only.
+	EventsRetentionMB int
+}
+
+// Retention limits events kept for replay
+type Retention struct {
+	MaxAge time.Duration
+	// MaxSize in bytes
+	MaxSize int
+}
+
+func (conf Conf) retention() Retention {
+	hours := conf.EventsRetentionHours
+	if hours <= 0 {
+		hours = defaultEventsRetentionHours
+	}
+	mb := conf.EventsRetentionMB
+	if mb <= 0 {
+		mb = defaultEventsRetentionMB
+	}
+	return Retention{
+		MaxAge:  time.Duration(hours) * time.Hour,
+		MaxSize: mb << 20,
+	}
+}
+
+// BlockState is the last block fully processed by the service
+type BlockState struct {
+	Hash   string `json:"hash" bson:"hash"`
+	Height int64  `json:"height" bson:"height"`
+}
+
+// ConfirmingTx is a watched transaction included in a block
+// which has not reached the confirmation depth yet
+type ConfirmingTx struct {
+	TxID        string `json:"txid" bson:"_id"`
+	BlockHash   string `json:"blockhash" bson:"blockhash"`
+	BlockHeight int64  `json:"blockheight" bson:"blockheight"`
+}
+
+// AddressTx is a transaction of the address in the local index
+type AddressTx struct {
+	Address string `json:"address" bson:"address"`
+	TxID    string `json:"txid" bson:"txid"`
+	Height  int64  `json:"height" bson:"height"`
+}
+
+// Event is a serialized message sent to the client
+type Event struct {
+	Seq  uint64 `json:"s" bson:"_id"`
+	Kind string `json:"k" bson:"kind"`
+	Data []byte `json:"d" bson:"data"`
+	// Time is unix time of the append, events are trimmed by it
+	Time int64 `json:"t" bson:"time"`
+}
+
+// Storage keeps the service state between restarts
+type Storage interface {
+	// Addresses returns the watch set: address to user
+	Addresses() (map[string]store.AddressExtended, error)
+	// SetAddresses replaces the whole watch set
+	SetAddresses(addresses map[string]store.AddressExtended) error
+	AddAddress(address string, ex store.AddressExtended) error
+
+	// LastBlock returns zero BlockState if no block was processed yet
+	LastBlock() (BlockState, error)
+	SetLastBlock(block BlockState) error
+
+	// SpendableOutputs returns all emitted and not yet spent outputs
+	SpendableOutputs() ([]store.SpendableOutputs, error)
+	// AddSpendableOutput keeps the output of one owner, an output may have several owners
+	AddSpendableOutput(spOut store.SpendableOutputs) error
+	// DeleteSpendableOutput removes the output of all its owners
+	DeleteSpendableOutput(txid string, index int) error
+
+	// ConfirmingTxs returns transactions waiting for confirmations
+	ConfirmingTxs() ([]ConfirmingTx, error)
+	AddConfirmingTx(tx ConfirmingTx) error
+	DeleteConfirmingTx(txid string) error
+
+	// AddressTxs returns transactions of the address from the local index ordered by height
+	AddressTxs(address string) ([]AddressTx, error)
+	// TxHeight returns the height of the transaction in the local index, false if it's not indexed
+	TxHeight(txid string) (int64, bool, error)
+	// IndexTxs adds address transactions of the block at height to the index
+	IndexTxs(height int64, txs []AddressTx) error
+	// UnindexHeight removes index records of the block at height
+	UnindexHeight(height int64) error
+	// BackfilledHeight returns the last height indexed by backfill
+	BackfilledHeight() (int64, error)
+	SetBackfilledHeight(height int64) error
+
+	// AppendEvent stores the event with the next sequence number
+	AppendEvent(kind string, data []byte) (uint64, error)
+	// EventsSince returns up to limit events of kinds with sequence number greater than since.
+	// All kinds are returned if kinds is empty.
+	EventsSince(since uint64, kinds []string, limit int) ([]Event, error)
+	// LastEventSeq returns sequence number of the last stored event
+	LastEventSeq() (uint64, error)
+	// FirstEventSeq returns sequence number of the oldest kept event,
+	// it's the next sequence number if no events are kept
+	FirstEventSeq() (uint64, error)
+
+	Close() error
+}
+
+// New opens the storage from config
+func New(conf Conf) (Storage, error) {
+	retention := conf.retention()
+	switch conf.Type {
+	case "", TypeFile:
+		path := conf.Path
+		if path == "" {
+			path = defaultPath
+		}
+		return NewFileStorage(path, retention)
+	case TypeMongo:
+		return NewMongoStorage(conf.MongoURL, conf.MongoDB, retention)
+	}
+	return nil, fmt.Errorf("unknown storage type %q", conf.Type)
+}
+
+func hasKind(kinds []string, kind string) bool {
+	if len(kinds) == 0 {
+		return true
+	}
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func sortAddressTxs(txs []AddressTx) {
+	sort.SliceStable(txs, func(i, j int) bool {
+		return txs[i].Height < txs[j].Height
+	})
+}
+
+func outpointKey(txid string, index int) string {
+	return fmt.Sprintf("%s:%d", txid, index)
+}
+
+// spOutKey identifies the spendable output of an owner, co-owned outputs are kept for each owner
+func spOutKey(spOut store.SpendableOutputs) string {
+	return outpointKey(spOut.TxID, spOut.TxOutID) + ":" + spOut.Address
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package streamer
+
+import (
+	"context"
+	"sort"
+
+	pb "github.com/Multy-io/Multy-BTC-node-service/node-streamer"
+	"github.com/Multy-io/Multy-BTC-node-service/outbox"
+	"github.com/Multy-io/Multy-BTC-node-service/storage"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// replayBatch is a number of events read from the outbox at once
+const replayBatch = 500
+
+/*
+replay sends outbox events of kinds after the cursor and then waits for new ones
+until the stream is closed.
+
+Zero cursor means the client has no position yet, so only new events are sent,
+fromStart sends all kept events. The stream fails with OutOfRange if events
+after the cursor are not kept anymore, the client has to resync then.
+Stream is finished on the first send error, the client is expected to reconnect
+with the seq of the last received event.
+*/
+func (s *Server) replay(ctx context.Context, since uint64, fromStart bool, kinds []string, send func(event storage.Event) error) error {
+	last, err := s.Events.LastSeq()
+	if err != nil {
+		log.Errorf("replay:Events.LastSeq: %s", err.Error())
+		return err
+	}
+	switch {
+	case fromStart:
+		first, err := s.Events.FirstSeq()
+		if err != nil {
+			log.Errorf("replay:Events.FirstSeq: %s", err.Error())
+			return err
+		}
+		since = first - 1
+	case since == 0:
+		since = last
+	case since > last:
+		return status.Errorf(codes.OutOfRange, "cursor %d is ahead of the last event %d", since, last)
+	}
+
+	for {
+		wait := s.Events.Wait()
+
+		// events could be trimmed while the client was reading
+		first, err := s.Events.FirstSeq()
+		if err != nil {
+			log.Errorf("replay:Events.FirstSeq: %s", err.Error())
+			return err
+		}
+		if since+1 < first {
+			log.Warnf("replay: events after %d are not kept, the oldest one is %d", since, first)
+			return status.Errorf(codes.OutOfRange, "events after %d are not kept, the oldest kept event is %d", since, first)
+		}
+
+		events, err := s.Events.Since(since, kinds, replayBatch)
+		if err != nil {
+			log.Errorf("replay:Events.Since: %s", err.Error())
+			return err
+		}
+		for _, event := range events {
+			if err := send(event); err != nil {
+				log.Warnf("replay:send %v kinds %v seq %d", err.Error(), kinds, event.Seq)
+				return err
+			}
+			since = event.Seq
+		}
+		if len(events) == replayBatch {
+			continue
+		}
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// unmarshalEvent decodes event payload, broken events are logged and skipped
+func unmarshalEvent(event storage.Event, msg proto.Message) bool {
+	if err := proto.Unmarshal(event.Data, msg); err != nil {
+		log.Errorf("unmarshalEvent:proto.Unmarshal: %s seq %d: %s", event.Kind, event.Seq, err.Error())
+		return false
+	}
+	return true
+}
+
+// eventKinds maps subscription kinds to outbox kinds
+var eventKinds = map[pb.EventKind]string{
+	pb.EventKind_NEW_TX:             outbox.KindNewTx,
+	pb.EventKind_ADD_SPOUT:          outbox.KindAddSpOut,
+	pb.EventKind_DELETE_SPOUT:       outbox.KindDeleteSpOut,
+	pb.EventKind_ADD_MEMPOOL:        outbox.KindAddMempool,
+	pb.EventKind_DELETE_MEMPOOL:     outbox.KindDeleteMempool,
+	pb.EventKind_NEW_BLOCK:          outbox.KindBlock,
+	pb.EventKind_BLOCK_DISCONNECTED: outbox.KindBlockDisconnected,
+	pb.EventKind_RESYNC:             outbox.KindResync,
+	pb.EventKind_TX_REPLACED:        outbox.KindTxReplaced,
+	pb.EventKind_TX_CONFLICTED:      outbox.KindTxConflicted,
+	pb.EventKind_TX_DROPPED:         outbox.KindTxDropped,
+	pb.EventKind_OP_RETURN_TX:       outbox.KindOpReturnTx,
+}
+
+// optInKinds are sent only if the subscription names them
+var optInKinds = map[pb.EventKind]bool{
+	pb.EventKind_OP_RETURN_TX: true,
+}
+
+// subscriptionKinds returns outbox kinds of the subscription.
+// Empty kinds or ALL mean every kind except opt-in ones.
+func subscriptionKinds(kinds []pb.EventKind) []string {
+	all := len(kinds) == 0
+	requested := map[pb.EventKind]bool{}
+	for _, kind := range kinds {
+		if kind == pb.EventKind_ALL {
+			all = true
+		}
+		requested[kind] = true
+	}
+	outboxKinds := []string{}
+	for kind, outboxKind := range eventKinds {
+		if requested[kind] || (all && !optInKinds[kind]) {
+			outboxKinds = append(outboxKinds, outboxKind)
+		}
+	}
+	// unknown kinds only are treated as all
+	if len(outboxKinds) == 0 {
+		return subscriptionKinds(nil)
+	}
+	sort.Strings(outboxKinds)
+	return outboxKinds
+}
+
+// envelope wraps outbox event into the Event message
+func envelope(event storage.Event) (*pb.Event, bool) {
+	envelope := &pb.Event{
+		Seq: event.Seq,
+	}
+	switch event.Kind {
+	case outbox.KindNewTx:
+		tx := &pb.BTCTransaction{}
+		if !unmarshalEvent(event, tx) {
+			return nil, false
+		}
+		tx.Seq = event.Seq
+		envelope.Payload = &pb.Event_Tx{Tx: tx}
+	case outbox.KindAddSpOut:
+		addSp := &pb.AddSpOut{}
+		if !unmarshalEvent(event, addSp) {
+			return nil, false
+		}
+		addSp.Seq = event.Seq
+		envelope.Payload = &pb.Event_AddSpOut{AddSpOut: addSp}
+	case outbox.KindDeleteSpOut:
+		delSp := &pb.ReqDeleteSpOut{}
+		if !unmarshalEvent(event, delSp) {
+			return nil, false
+		}
+		delSp.Seq = event.Seq
+		envelope.Payload = &pb.Event_DeleteSpOut{DeleteSpOut: delSp}
+	case outbox.KindAddMempool:
+		add := &pb.MempoolRecord{}
+		if !unmarshalEvent(event, add) {
+			return nil, false
+		}
+		add.Seq = event.Seq
+		envelope.Payload = &pb.Event_AddMempool{AddMempool: add}
+	case outbox.KindDeleteMempool:
+		del := &pb.MempoolToDelete{}
+		if !unmarshalEvent(event, del) {
+			return nil, false
+		}
+		del.Seq = event.Seq
+		envelope.Payload = &pb.Event_DeleteMempool{DeleteMempool: del}
+	case outbox.KindBlock:
+		h := &pb.BlockHeight{}
+		if !unmarshalEvent(event, h) {
+			return nil, false
+		}
+		h.Seq = event.Seq
+		envelope.Payload = &pb.Event_Block{Block: h}
+	case outbox.KindBlockDisconnected:
+		disconnected := &pb.BlockDisconnected{}
+		if !unmarshalEvent(event, disconnected) {
+			return nil, false
+		}
+		disconnected.Seq = event.Seq
+		envelope.Payload = &pb.Event_BlockDisconnected{BlockDisconnected: disconnected}
+	case outbox.KindResync:
+		res := &pb.Resync{}
+		if !unmarshalEvent(event, res) {
+			return nil, false
+		}
+		res.Seq = event.Seq
+		envelope.Payload = &pb.Event_Resync{Resync: res}
+	case outbox.KindTxReplaced:
+		replaced := &pb.TxConflict{}
+		if !unmarshalEvent(event, replaced) {
+			return nil, false
+		}
+		replaced.Seq = event.Seq
+		envelope.Payload = &pb.Event_TxReplaced{TxReplaced: replaced}
+	case outbox.KindTxConflicted:
+		conflicted := &pb.TxConflict{}
+		if !unmarshalEvent(event, conflicted) {
+			return nil, false
+		}
+		conflicted.Seq = event.Seq
+		envelope.Payload = &pb.Event_TxConflicted{TxConflicted: conflicted}
+	case outbox.KindOpReturnTx:
+		tx := &pb.BTCTransaction{}
+		if !unmarshalEvent(event, tx) {
+			return nil, false
+		}
+		tx.Seq = event.Seq
+		envelope.Payload = &pb.Event_OpReturnTx{OpReturnTx: tx}
+	case outbox.KindTxDropped:
+		dropped := &pb.TxConflict{}
+		if !unmarshalEvent(event, dropped) {
+			return nil, false
+		}
+		dropped.Seq = event.Seq
+		envelope.Payload = &pb.Event_TxDropped{TxDropped: dropped}
+	default:
+		log.Errorf("envelope: unknown event kind %s seq %d", event.Kind, event.Seq)
+		return nil, false
+	}
+	return envelope, true
+}
@@ -2,25 +2,24 @@ package streamer
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"net"
-	"net/http"
-	"strconv"
 	"sync"
-	"time"
 
 	"github.com/Multy-io/Multy-BTC-node-service/btc"
+	"github.com/Multy-io/Multy-BTC-node-service/history"
 	pb "github.com/Multy-io/Multy-BTC-node-service/node-streamer"
+	"github.com/Multy-io/Multy-BTC-node-service/outbox"
+	"github.com/Multy-io/Multy-BTC-node-service/storage"
 	"github.com/Multy-io/Multy-back/store"
 	"github.com/blockcypher/gobcy"
 	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/jekabolt/slf"
 	_ "github.com/jekabolt/slflog"
-	"github.com/parnurzeal/gorequest"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var log = slf.WithContext("streamer").WithCaller(slf.CallerShort)
@@ -28,13 +27,15 @@ var log = slf.WithContext("streamer").WithCaller(slf.CallerShort)
 // Server implements streamer interface and is a gRPC server
 type Server struct {
 	UsersData  *sync.Map
+	Storage    storage.Storage
+	Events     *outbox.Outbox
+	History    []history.HistoryProvider
 	BtcAPI     *gobcy.API
 	BtcCli     *btc.Client
 	M          *sync.Mutex
 	Info       *store.ServiceInfo
 	GRPCserver *grpc.Server
 	Listener   net.Listener
-	ReloadChan chan struct{}
 }
 
 func (s *Server) ServiceInfo(c context.Context, in *pb.Empty) (*pb.ServiceVersion, error) {
@@ -50,17 +51,35 @@ func (s *Server) ServiceInfo(c context.Context, in *pb.Empty) (*pb.ServiceVersio
 func (s *Server) EventInitialAdd(c context.Context, ud *pb.UsersData) (*pb.ReplyInfo, error) {
 	log.Debugf("EventInitialAdd len - %v", len(ud.Map))
 
-	udMap := sync.Map{}
-
+	addresses := map[string]store.AddressExtended{}
 	for addr, ex := range ud.GetMap() {
-		udMap.Store(addr, store.AddressExtended{
+		if err := btc.ValidateAddress(addr, s.BtcCli.Params()); err != nil {
+			log.Errorf("EventInitialAdd:btc.ValidateAddress: %s", err.Error())
+			continue
+		}
+		addresses[btc.NormalizeAddress(addr, s.BtcCli.Params())] = store.AddressExtended{
 			UserID:       ex.GetUserID(),
 			WalletIndex:  int(ex.GetWalletIndex()),
 			AddressIndex: int(ex.GetAddressIndex()),
-		})
+		}
 	}
 
-	*s.UsersData = udMap
+	s.UsersData.Range(func(addr, _ interface{}) bool {
+		if _, ok := addresses[addr.(string)]; !ok {
+			s.UsersData.Delete(addr)
+		}
+		return true
+	})
+	for addr, ex := range addresses {
+		s.UsersData.Store(addr, ex)
+	}
+
+	if err := s.Storage.SetAddresses(addresses); err != nil {
+		log.Errorf("EventInitialAdd:Storage.SetAddresses: %s", err.Error())
+		return &pb.ReplyInfo{
+			Message: "err: " + err.Error(),
+		}, nil
+	}
 
 	return &pb.ReplyInfo{
 		Message: "ok",
@@ -69,24 +88,32 @@ func (s *Server) EventInitialAdd(c context.Context, ud *pb.UsersData) (*pb.Reply
 
 // EventAddNewAddress us used to add new watch address to existing pairs
 func (s *Server) EventAddNewAddress(c context.Context, wa *pb.WatchAddress) (*pb.ReplyInfo, error) {
-	newMap := *s.UsersData
-	// if newMap == nil {
-	// 	newMap = map[string]store.AddressExtended{}
-	// }
+	if err := btc.ValidateAddress(wa.Address, s.BtcCli.Params()); err != nil {
+		log.Errorf("EventAddNewAddress:btc.ValidateAddress: %s", err.Error())
+		return &pb.ReplyInfo{
+			Message: "err: " + err.Error(),
+		}, nil
+	}
+
+	address := btc.NormalizeAddress(wa.Address, s.BtcCli.Params())
+
 	//TODO: binded address fix
-	_, ok := newMap.Load(wa.Address)
+	_, ok := s.UsersData.Load(address)
 	if ok {
 		return &pb.ReplyInfo{
 			Message: "err: Address already binded",
 		}, nil
 	}
-	newMap.Store(wa.Address, store.AddressExtended{
+	addressEx := store.AddressExtended{
 		UserID:       wa.UserID,
 		WalletIndex:  int(wa.WalletIndex),
 		AddressIndex: int(wa.AddressIndex),
-	})
+	}
+	s.UsersData.Store(address, addressEx)
 
-	*s.UsersData = newMap
+	if err := s.Storage.AddAddress(address, addressEx); err != nil {
+		log.Errorf("EventAddNewAddress:Storage.AddAddress: %s", err.Error())
+	}
 
 	return &pb.ReplyInfo{
 		Message: "ok",
@@ -111,11 +138,8 @@ func (s *Server) EventGetAllMempool(_ *pb.Empty, stream pb.NodeCommunications_Ev
 		return err
 	}
 
-	for _, rec := range mp {
-		stream.Send(&pb.MempoolRecord{
-			Category: int32(rec.Category),
-			HashTX:   rec.HashTX,
-		})
+	for i := range mp {
+		stream.Send(&mp[i])
 	}
 	return nil
 }
@@ -168,136 +192,57 @@ func (s *Server) SyncState(ctx context.Context, in *pb.BlockHeight) (*pb.ReplyIn
 	}, nil
 }
 
+// txCheckStatuses maps statuses of checked transactions
+var txCheckStatuses = map[string]pb.TxCheckStatus{
+	btc.TxUnknown:    pb.TxCheckStatus_CHECK_UNKNOWN,
+	btc.TxMempool:    pb.TxCheckStatus_CHECK_MEMPOOL,
+	btc.TxConfirmed:  pb.TxCheckStatus_CHECK_CONFIRMED,
+	btc.TxConflicted: pb.TxCheckStatus_CHECK_CONFLICTED,
+}
+
+// CheckRejectTxs reports the status of each transaction, it doesn't need the node's wallet
 func (s *Server) CheckRejectTxs(c context.Context, txs *pb.TxsToCheck) (*pb.RejectedTxs, error) {
+	states, err := s.BtcCli.TxStates(txs.Hash)
+	if err != nil {
+		log.Errorf("CheckRejectTxs:s.BtcCli.TxStates: %v", err.Error())
+		return nil, fmt.Errorf("CheckRejectTxs: %s", err.Error())
+	}
+
 	reTxs := &pb.RejectedTxs{}
-	for _, tx := range txs.Hash {
-		hash, err := chainhash.NewHashFromStr(tx)
-		if err != nil {
-			continue
-		}
-		_, err = s.BtcCli.RPCClient.GetTransaction(hash)
-		if err != nil {
-			reTxs.RejectedTxs = append(reTxs.RejectedTxs, tx)
+	for _, state := range states {
+		reTxs.Txs = append(reTxs.Txs, &pb.CheckedTx{
+			Hash:          state.Txid,
+			Status:        txCheckStatuses[state.Status],
+			Height:        state.Height,
+			BlockHash:     state.BlockHash,
+			Confirmations: state.Confirmations,
+			ReplacedBy:    state.ReplacedBy,
+		})
+		// unknown transactions may be confirmed beyond the node's and local index
+		if state.Status == btc.TxConflicted {
+			reTxs.RejectedTxs = append(reTxs.RejectedTxs, state.Txid)
 		}
-
 	}
 	return reTxs, nil
 }
 
 func (s *Server) EventResyncAddress(c context.Context, address *pb.AddressToResync) (*pb.ReplyInfo, error) {
 	log.Debugf("EventResyncAddress")
-	allResync := []store.ResyncTx{}
-	delFromResyncQ := ""
-	requestTimes := 0
-	if s.BtcAPI.Chain == "test3" {
-		addrInfo, err := s.BtcAPI.GetAddrFull(address.Address, map[string]string{"limit": "50"})
-		if err != nil {
-			return nil, fmt.Errorf("EventResyncAddress: s.BtcAPI.GetAddrFull : %s", err.Error())
-		}
-
-		log.Debugf("EventResyncAddress:s.BtcAPI.GetAddrFull")
-		if addrInfo.FinalNumTX > 50 {
-			requestTimes = int(float64(addrInfo.FinalNumTX) / 50.0)
-		}
-
-		if addrInfo.FinalNumTX == 0 {
-			delFromResyncQ = address.Address
-		}
-
-		for _, tx := range addrInfo.TXs {
-			allResync = append(allResync, store.ResyncTx{
-				Hash:        tx.Hash,
-				BlockHeight: tx.BlockHeight,
-			})
-		}
-		for i := 0; i < requestTimes; i++ {
-			addrInfo, err := s.BtcAPI.GetAddrFull(address.Address, map[string]string{"limit": "50", "before": strconv.Itoa(allResync[len(allResync)-1].BlockHeight)})
-			if err != nil {
-				return nil, fmt.Errorf("[ERR] EventResyncAddress: s.BtcAPI.GetAddrFull : %s", err.Error())
-			}
-			for _, tx := range addrInfo.TXs {
-				allResync = append(allResync, store.ResyncTx{
-					Hash:        tx.Hash,
-					BlockHeight: tx.BlockHeight,
-				})
-			}
-		}
 
+	if err := btc.ValidateAddress(address.Address, s.BtcCli.Params()); err != nil {
+		return nil, fmt.Errorf("EventResyncAddress: btc.ValidateAddress : %s", err.Error())
 	}
 
-	if s.BtcAPI.Chain == "main" {
-
-		url := "https://chain.api.btc.com/v3/address/" + address.Address + "/tx?page=1"
-		dbl := sync.Map{}
-
-		request := gorequest.New()
-		resp, _, errs := request.Get(url).Retry(10, 10*time.Second, http.StatusForbidden, http.StatusBadRequest, http.StatusInternalServerError).End()
-		if len(errs) > 0 {
-			log.Errorf("EventResyncAddress:request.Get: %v", errs)
-		}
-		respBody, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			log.Errorf("EventResyncAddress:ioutil.ReadAll: %v", err.Error())
-		}
-
-		reTx := store.BtcComResp{}
-		if err := json.Unmarshal(respBody, &reTx); err != nil {
-			log.Errorf("EventResyncAddress:json.Unmarshal: %v", err.Error())
-		}
-
-		if reTx.Data.TotalCount > 50 {
-			requestTimes = int(float64(reTx.Data.TotalCount)/50.0) + 2
-		}
-
-		if reTx.Data.TotalCount < 50 {
-			for _, tx := range reTx.Data.List {
-				_, ok := dbl.LoadOrStore(tx, true)
-				if !ok {
-					allResync = append(allResync, store.ResyncTx{
-						Hash:        tx.Hash,
-						BlockHeight: tx.BlockHeight,
-					})
-				}
-			}
-		}
-
-		if reTx.Data.TotalCount > 50 {
-			for index := 1; index < requestTimes; index++ {
-				url := "https://chain.api.btc.com/v3/address/" + address.Address + "/tx?page=" + strconv.Itoa(index)
-				resp, _, errs := request.Get(url).Retry(2, 2*time.Second, http.StatusForbidden, http.StatusBadRequest, http.StatusInternalServerError).End()
-				if len(errs) > 0 {
-					log.Errorf("EventResyncAddress:request.Get: %v", errs)
-				}
-
-				respBody, err := ioutil.ReadAll(resp.Body)
-				if err != nil {
-					log.Errorf("EventResyncAddress:ioutil.ReadAll: %v", err.Error())
-				}
-
-				reTx := store.BtcComResp{}
-				if err := json.Unmarshal(respBody, &reTx); err != nil {
-					log.Errorf("EventResyncAddress:json.Unmarshal: %v", err.Error())
-				}
-
-				for _, tx := range reTx.Data.List {
-					_, ok := dbl.LoadOrStore(tx, true)
-					if !ok {
-						allResync = append(allResync, store.ResyncTx{
-							Hash:        tx.Hash,
-							BlockHeight: tx.BlockHeight,
-						})
-					}
-				}
-			}
-		}
-
-		if reTx.Data.TotalCount == 0 {
-			delFromResyncQ = address.Address
-		}
+	allResync, err := history.AddressHistory(s.History, address.Address)
+	if err != nil {
+		return nil, fmt.Errorf("EventResyncAddress: history.AddressHistory : %s", err.Error())
+	}
 
+	delFromResyncQ := ""
+	if len(allResync) == 0 {
+		delFromResyncQ = address.Address
 	}
-	reverseResyncTx(allResync)
-	log.Debugf("EventResyncAddress:reverseResyncTx %d", len(allResync))
+	log.Debugf("EventResyncAddress:AddressHistory %d", len(allResync))
 
 	s.BtcCli.ResyncAddresses(allResync, address, delFromResyncQ)
 
@@ -306,100 +251,261 @@ func (s *Server) EventResyncAddress(c context.Context, address *pb.AddressToResy
 	}, nil
 }
 
-func (s *Server) EventSendRawTx(c context.Context, tx *pb.RawTx) (*pb.ReplyInfo, error) {
-	hash, err := s.BtcCli.RPCClient.SendCyberRawTransaction(tx.Transaction, true)
+// sendTxReasons maps reasons of rejected raw transactions
+var sendTxReasons = map[string]pb.SendTxReason{
+	btc.RejectParse:        pb.SendTxReason_PARSE_ERROR,
+	btc.RejectMissingInput: pb.SendTxReason_MISSING_INPUT,
+	btc.RejectSpentInput:   pb.SendTxReason_SPENT_INPUT,
+	btc.RejectFeeTooLow:    pb.SendTxReason_FEE_TOO_LOW,
+	btc.RejectAbsurdFee:    pb.SendTxReason_ABSURD_FEE,
+	btc.RejectDust:         pb.SendTxReason_DUST_OUTPUT,
+	btc.RejectNonFinal:     pb.SendTxReason_NON_FINAL,
+	btc.RejectNode:         pb.SendTxReason_NODE_REJECTED,
+}
+
+// EventSendRawTx validates the transaction and broadcasts it unless it's a dry run.
+// A rejected broadcast is an error like before validation was added,
+// its status carries the reply with problems in details.
+func (s *Server) EventSendRawTx(c context.Context, tx *pb.RawTx) (*pb.SendRawTxReply, error) {
+	check, err := s.BtcCli.SendRawTx(tx.Transaction, tx.DryRun)
 	if err != nil {
-		log.Errorf("EventSendRawTx:s.BtcCli.RPCClient.SendCyberRawTransaction: %v", err.Error())
-		return &pb.ReplyInfo{
+		log.Errorf("EventSendRawTx:s.BtcCli.SendRawTx: %v", err.Error())
+		return &pb.SendRawTxReply{
 			Message: "err: wrong raw tx",
 		}, fmt.Errorf("err: wrong raw tx %s", err.Error())
-
 	}
 
-	return &pb.ReplyInfo{
-		Message: hash.String(),
-	}, nil
-
+	reply := &pb.SendRawTxReply{
+		Message: check.Txid,
+		TxID:    check.Txid,
+		Fee:     int64(check.Fee),
+		Vsize:   check.Vsize,
+		FeeRate: check.FeeRate,
+		Sent:    check.Sent,
+	}
+	for _, problem := range check.Problems {
+		reply.Problems = append(reply.Problems, &pb.SendTxProblem{
+			Reason:  sendTxReasons[problem.Reason],
+			Index:   int32(problem.Index),
+			Message: problem.Message,
+		})
+	}
+	if len(check.Problems) > 0 {
+		reply.Message = "err: " + check.Problems[0].Message
+		log.Warnf("EventSendRawTx: %s is rejected: %v", check.Txid, check.Problems)
+		if !tx.DryRun {
+			return nil, rejectedTx(reply)
+		}
+	}
+	return reply, nil
 }
 
-func (s *Server) EventDeleteMempool(_ *pb.Empty, stream pb.NodeCommunications_EventDeleteMempoolServer) error {
-	for del := range s.BtcCli.DeleteMempool {
-		err := stream.Send(&del)
-		if err != nil && err.Error() == ErrGrpcTransport {
-			log.Warnf("EventDeleteMempool:stream.Send(&del) %v ", err.Error())
-			s.ReloadChan <- struct{}{}
-		}
+// rejectedTx is the status error of a rejected broadcast with the reason of the first problem,
+// the reply is in details for clients decoding them
+func rejectedTx(reply *pb.SendRawTxReply) error {
+	problem := reply.Problems[0]
+	code := codes.FailedPrecondition
+	if problem.Reason == pb.SendTxReason_PARSE_ERROR {
+		code = codes.InvalidArgument
 	}
-	return nil
+	st := status.Newf(code, "err: rejected raw tx %s: %s", problem.Reason, problem.Message)
+	detailed, err := st.WithDetails(reply)
+	if err != nil {
+		log.Errorf("rejectedTx:WithDetails: %s", err.Error())
+		return st.Err()
+	}
+	return detailed.Err()
 }
 
-func (s *Server) EventAddMempoolRecord(_ *pb.Empty, stream pb.NodeCommunications_EventAddMempoolRecordServer) error {
-	for add := range s.BtcCli.AddToMempool {
-		err := stream.Send(&add)
-		if err != nil && err.Error() == ErrGrpcTransport {
-			log.Warnf("EventAddMempoolRecord:stream.Send(&del) %v ", err.Error())
-			s.ReloadChan <- struct{}{}
+func (s *Server) EventDeleteMempool(cursor *pb.Cursor, stream pb.NodeCommunications_EventDeleteMempoolServer) error {
+	return s.replay(stream.Context(), cursor.GetSince(), cursor.GetFromStart(), []string{outbox.KindDeleteMempool}, func(event storage.Event) error {
+		del := pb.MempoolToDelete{}
+		if !unmarshalEvent(event, &del) {
+			return nil
 		}
-	}
-	return nil
+		del.Seq = event.Seq
+		return stream.Send(&del)
+	})
 }
 
-func (s *Server) EventDeleteSpendableOut(_ *pb.Empty, stream pb.NodeCommunications_EventDeleteSpendableOutServer) error {
-	for delSp := range s.BtcCli.DelSpOut {
-		log.Infof("Delete spendable out %v", delSp.String())
-		err := stream.Send(&delSp)
-		if err != nil && err.Error() == ErrGrpcTransport {
-			log.Warnf("EventDeleteSpendableOut:stream.Send(&del) %v ", err.Error())
-			s.ReloadChan <- struct{}{}
+func (s *Server) EventAddMempoolRecord(cursor *pb.Cursor, stream pb.NodeCommunications_EventAddMempoolRecordServer) error {
+	return s.replay(stream.Context(), cursor.GetSince(), cursor.GetFromStart(), []string{outbox.KindAddMempool}, func(event storage.Event) error {
+		add := pb.MempoolRecord{}
+		if !unmarshalEvent(event, &add) {
+			return nil
 		}
-	}
-	return nil
+		add.Seq = event.Seq
+		return stream.Send(&add)
+	})
 }
-func (s *Server) EventAddSpendableOut(_ *pb.Empty, stream pb.NodeCommunications_EventAddSpendableOutServer) error {
 
-	for addSp := range s.BtcCli.AddSpOut {
-		log.Infof("Add spendable out %v", addSp.String())
-		err := stream.Send(&addSp)
-		if err != nil && err.Error() == ErrGrpcTransport {
-			log.Warnf("EventAddSpendableOut:stream.Send(&del) %v ", err.Error())
-			s.ReloadChan <- struct{}{}
+func (s *Server) EventDeleteSpendableOut(cursor *pb.Cursor, stream pb.NodeCommunications_EventDeleteSpendableOutServer) error {
+	return s.replay(stream.Context(), cursor.GetSince(), cursor.GetFromStart(), []string{outbox.KindDeleteSpOut}, func(event storage.Event) error {
+		delSp := pb.ReqDeleteSpOut{}
+		if !unmarshalEvent(event, &delSp) {
+			return nil
 		}
-	}
+		delSp.Seq = event.Seq
+		log.Infof("Delete spendable out %v", delSp.String())
+		return stream.Send(&delSp)
+	})
+}
 
-	return nil
+func (s *Server) EventAddSpendableOut(cursor *pb.Cursor, stream pb.NodeCommunications_EventAddSpendableOutServer) error {
+	return s.replay(stream.Context(), cursor.GetSince(), cursor.GetFromStart(), []string{outbox.KindAddSpOut}, func(event storage.Event) error {
+		addSp := pb.AddSpOut{}
+		if !unmarshalEvent(event, &addSp) {
+			return nil
+		}
+		addSp.Seq = event.Seq
+		log.Infof("Add spendable out %v", addSp.String())
+		return stream.Send(&addSp)
+	})
 }
-func (s *Server) NewTx(_ *pb.Empty, stream pb.NodeCommunications_NewTxServer) error {
 
-	for tx := range s.BtcCli.TransactionsCh {
-		log.Infof("NewTx history - %v", tx.String())
-		err := stream.Send(&tx)
-		if err != nil && err.Error() == ErrGrpcTransport {
-			log.Warnf("NewTx:stream.Send(&del) %v ", err.Error())
-			s.ReloadChan <- struct{}{}
+func (s *Server) NewTx(cursor *pb.Cursor, stream pb.NodeCommunications_NewTxServer) error {
+	return s.replay(stream.Context(), cursor.GetSince(), cursor.GetFromStart(), []string{outbox.KindNewTx}, func(event storage.Event) error {
+		tx := pb.BTCTransaction{}
+		if !unmarshalEvent(event, &tx) {
+			return nil
 		}
-	}
-	return nil
+		tx.Seq = event.Seq
+		log.Infof("NewTx history - %v", tx.String())
+		return stream.Send(&tx)
+	})
 }
 
-func (s *Server) EventNewBlock(_ *pb.Empty, stream pb.NodeCommunications_EventNewBlockServer) error {
-	for h := range s.BtcCli.Block {
+func (s *Server) EventNewBlock(cursor *pb.Cursor, stream pb.NodeCommunications_EventNewBlockServer) error {
+	return s.replay(stream.Context(), cursor.GetSince(), cursor.GetFromStart(), []string{outbox.KindBlock}, func(event storage.Event) error {
+		h := pb.BlockHeight{}
+		if !unmarshalEvent(event, &h) {
+			return nil
+		}
+		h.Seq = event.Seq
 		log.Infof("New block height - %v", h.GetHeight())
-		err := stream.Send(&h)
-		if err != nil && err.Error() == ErrGrpcTransport {
-			log.Warnf("EventNewBlock:stream.Send(&del) %v ", err.Error())
-			s.ReloadChan <- struct{}{}
+		return stream.Send(&h)
+	})
+}
+
+func (s *Server) EventBlockDisconnected(cursor *pb.Cursor, stream pb.NodeCommunications_EventBlockDisconnectedServer) error {
+	return s.replay(stream.Context(), cursor.GetSince(), cursor.GetFromStart(), []string{outbox.KindBlockDisconnected}, func(event storage.Event) error {
+		disconnected := pb.BlockDisconnected{}
+		if !unmarshalEvent(event, &disconnected) {
+			return nil
 		}
-	}
-	return nil
+		disconnected.Seq = event.Seq
+		log.Infof("Block disconnected - %v", disconnected.String())
+		return stream.Send(&disconnected)
+	})
 }
 
-func (s *Server) ResyncAddress(_ *pb.Empty, stream pb.NodeCommunications_ResyncAddressServer) error {
-	for res := range s.BtcCli.ResyncCh {
+func (s *Server) ResyncAddress(cursor *pb.Cursor, stream pb.NodeCommunications_ResyncAddressServer) error {
+	return s.replay(stream.Context(), cursor.GetSince(), cursor.GetFromStart(), []string{outbox.KindResync}, func(event storage.Event) error {
+		res := pb.Resync{}
+		if !unmarshalEvent(event, &res) {
+			return nil
+		}
+		res.Seq = event.Seq
 		log.Infof("Resync address - %v", res.String())
-		err := stream.Send(&res)
-		if err != nil && err.Error() == ErrGrpcTransport {
-			s.ReloadChan <- struct{}{}
+		return stream.Send(&res)
+	})
+}
+
+func (s *Server) EventTxReplaced(cursor *pb.Cursor, stream pb.NodeCommunications_EventTxReplacedServer) error {
+	return s.replay(stream.Context(), cursor.GetSince(), cursor.GetFromStart(), []string{outbox.KindTxReplaced}, func(event storage.Event) error {
+		replaced := pb.TxConflict{}
+		if !unmarshalEvent(event, &replaced) {
+			return nil
+		}
+		replaced.Seq = event.Seq
+		log.Infof("Tx replaced - %v", replaced.String())
+		return stream.Send(&replaced)
+	})
+}
+
+func (s *Server) EventTxConflicted(cursor *pb.Cursor, stream pb.NodeCommunications_EventTxConflictedServer) error {
+	return s.replay(stream.Context(), cursor.GetSince(), cursor.GetFromStart(), []string{outbox.KindTxConflicted}, func(event storage.Event) error {
+		conflicted := pb.TxConflict{}
+		if !unmarshalEvent(event, &conflicted) {
+			return nil
+		}
+		conflicted.Seq = event.Seq
+		log.Infof("Tx conflicted - %v", conflicted.String())
+		return stream.Send(&conflicted)
+	})
+}
+
+func (s *Server) EventTxDropped(cursor *pb.Cursor, stream pb.NodeCommunications_EventTxDroppedServer) error {
+	return s.replay(stream.Context(), cursor.GetSince(), cursor.GetFromStart(), []string{outbox.KindTxDropped}, func(event storage.Event) error {
+		dropped := pb.TxConflict{}
+		if !unmarshalEvent(event, &dropped) {
+			return nil
+		}
+		dropped.Seq = event.Seq
+		log.Infof("Tx dropped - %v", dropped.String())
+		return stream.Send(&dropped)
+	})
+}
+
+// Subscribe sends all events of the subscription kinds in one ordered stream
+func (s *Server) Subscribe(sub *pb.Subscription, stream pb.NodeCommunications_SubscribeServer) error {
+	kinds := subscriptionKinds(sub.GetKinds())
+	log.Infof("Subscribe since %d from start %t kinds %v", sub.GetSince(), sub.GetFromStart(), kinds)
+	return s.replay(stream.Context(), sub.GetSince(), sub.GetFromStart(), kinds, func(event storage.Event) error {
+		envelope, ok := envelope(event)
+		if !ok {
+			return nil
 		}
+		return stream.Send(envelope)
+	})
+}
+
+// EstimateFee returns recommended fee rates for confirmation targets
+func (s *Server) EstimateFee(c context.Context, req *pb.FeeEstimateRequest) (*pb.FeeEstimates, error) {
+	targets := []int{}
+	for _, target := range req.GetTargets() {
+		targets = append(targets, int(target))
 	}
-	return nil
+
+	estimates, mempoolSize, mempoolBytes, err := s.BtcCli.EstimateFee(targets)
+	if err != nil {
+		log.Errorf("EstimateFee:s.BtcCli.EstimateFee: %v", err.Error())
+		return nil, fmt.Errorf("EstimateFee: %s", err.Error())
+	}
+
+	reply := &pb.FeeEstimates{
+		MempoolSize:  int64(mempoolSize),
+		MempoolBytes: mempoolBytes,
+	}
+	for _, estimate := range estimates {
+		reply.Estimates = append(reply.Estimates, &pb.FeeEstimate{
+			Target:         int32(estimate.Target),
+			FeeRate:        estimate.FeeRate,
+			MempoolFeeRate: estimate.MempoolFeeRate,
+			BlocksFeeRate:  estimate.BlocksFeeRate,
+			NodeFeeRate:    estimate.NodeFeeRate,
+		})
+	}
+	return reply, nil
+}
+
+// NodeHealth reports if node notifications are delivered or polled after a gap
+func (s *Server) NodeHealth(c context.Context, in *pb.Empty) (*pb.Health, error) {
+	health := s.BtcCli.Health()
+	reply := &pb.Health{
+		Degraded:        health.Degraded,
+		Mode:            health.Mode,
+		Reason:          health.Reason,
+		MissedBlocks:    int64(health.MissedBlocks),
+		MissedTxs:       int64(health.MissedTxs),
+		DeliveredHeight: health.DeliveredHeight,
+		BestHeight:      health.BestHeight,
+	}
+	if !health.Since.IsZero() {
+		reply.Since = health.Since.Unix()
+	}
+	if !health.CheckedAt.IsZero() {
+		reply.CheckedAt = health.CheckedAt.Unix()
+	}
+	return reply, nil
 }
@@ -0,0 +1,186 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package zmq
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"sync"
+	"time"
+)
+
+// writeTimeout disconnects subscribers which don't read
+const writeTimeout = 10 * time.Second
+
+// Publisher is a PUB socket accepting subscribers
+type Publisher struct {
+	listener net.Listener
+
+	m     sync.Mutex
+	peers map[*peer]struct{}
+}
+
+// peer is a connected subscriber with its topics
+type peer struct {
+	conn net.Conn
+	// w serializes messages of concurrent Publish calls
+	w sync.Mutex
+
+	m      sync.Mutex
+	topics map[string]struct{}
+}
+
+// Listen starts the publisher on tcp://host:port
+func Listen(address string) (*Publisher, error) {
+	listener, err := net.Listen("tcp", network(address))
+	if err != nil {
+		return nil, err
+	}
+	p := &Publisher{
+		listener: listener,
+		peers:    map[*peer]struct{}{},
+	}
+	go p.accept()
+	return p, nil
+}
+
+// Addr returns the listening address
+func (p *Publisher) Addr() net.Addr {
+	return p.listener.Addr()
+}
+
+func (p *Publisher) accept() {
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+		go p.serve(conn)
+	}
+}
+
+func (p *Publisher) serve(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	peerType, err := handshake(conn, r, true, socketPub)
+	if err != nil || (peerType != socketSub && peerType != socketXSub) {
+		conn.Close()
+		return
+	}
+
+	pr := &peer{
+		conn:   conn,
+		topics: map[string]struct{}{},
+	}
+	p.m.Lock()
+	p.peers[pr] = struct{}{}
+	p.m.Unlock()
+
+	defer func() {
+		p.m.Lock()
+		delete(p.peers, pr)
+		p.m.Unlock()
+		conn.Close()
+	}()
+
+	// subscriptions are messages starting with 1 and cancels starting with 0,
+	// ZMTP 3.1 peers send SUBSCRIBE and CANCEL commands instead
+	for {
+		parts, command, err := readMessage(r)
+		if err != nil {
+			return
+		}
+		if len(parts) != 1 {
+			continue
+		}
+		body := parts[0]
+		subscribe, topic := false, []byte(nil)
+		switch {
+		case command:
+			name, _, err := parseCommand(frame{command: true, body: body})
+			if err != nil {
+				continue
+			}
+			topic = body[1+len(name):]
+			subscribe = name == "SUBSCRIBE"
+			if !subscribe && name != "CANCEL" {
+				continue
+			}
+		case len(body) > 0 && (body[0] == 0 || body[0] == 1):
+			subscribe, topic = body[0] == 1, body[1:]
+		default:
+			continue
+		}
+
+		pr.m.Lock()
+		if subscribe {
+			pr.topics[string(topic)] = struct{}{}
+		} else {
+			delete(pr.topics, string(topic))
+		}
+		pr.m.Unlock()
+	}
+}
+
+func (pr *peer) subscribed(topic []byte) bool {
+	pr.m.Lock()
+	defer pr.m.Unlock()
+	for prefix := range pr.topics {
+		if bytes.HasPrefix(topic, []byte(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscribers returns a number of connected subscribers
+func (p *Publisher) Subscribers() int {
+	p.m.Lock()
+	defer p.m.Unlock()
+	return len(p.peers)
+}
+
+// Publish sends the message to subscribers of its first part, it's safe for concurrent use.
+// Slow or broken subscribers are disconnected.
+func (p *Publisher) Publish(parts ...[]byte) {
+	if len(parts) == 0 {
+		return
+	}
+	p.m.Lock()
+	peers := []*peer{}
+	for pr := range p.peers {
+		peers = append(peers, pr)
+	}
+	p.m.Unlock()
+
+	for _, pr := range peers {
+		if !pr.subscribed(parts[0]) {
+			continue
+		}
+		pr.write(parts)
+	}
+}
+
+// write sends the whole message before another one is started
+func (pr *peer) write(parts [][]byte) {
+	pr.w.Lock()
+	defer pr.w.Unlock()
+	pr.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if err := writeMessage(pr.conn, parts); err != nil {
+		pr.conn.Close()
+	}
+}
+
+// Close stops accepting subscribers and disconnects existing ones
+func (p *Publisher) Close() error {
+	err := p.listener.Close()
+	p.m.Lock()
+	for pr := range p.peers {
+		pr.conn.Close()
+	}
+	p.m.Unlock()
+	return err
+}
@@ -0,0 +1,237 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package zmq
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	topicTx    = "rawtx"
+	topicBlock = "rawblock"
+)
+
+func listen(t *testing.T) *Publisher {
+	p, err := Listen("tcp://127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// waitSubscribed waits until every connected peer subscribed to the topic
+func waitSubscribed(t *testing.T, p *Publisher, peers int, topic string) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		p.m.Lock()
+		subscribed := 0
+		for pr := range p.peers {
+			if pr.subscribed([]byte(topic)) {
+				subscribed++
+			}
+		}
+		p.m.Unlock()
+		if subscribed == peers {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("%d peers are not subscribed to %s", peers, topic)
+}
+
+func sequence(n uint32) []byte {
+	seq := make([]byte, 4)
+	binary.LittleEndian.PutUint32(seq, n)
+	return seq
+}
+
+func TestPublishTopics(t *testing.T) {
+	p := listen(t)
+	defer p.Close()
+
+	sub, err := Subscribe("tcp://"+p.Addr().String(), topicTx, topicBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	waitSubscribed(t, p, 1, topicTx)
+	waitSubscribed(t, p, 1, topicBlock)
+
+	block := bytes.Repeat([]byte{0xab}, 1000)
+	messages := [][][]byte{
+		{[]byte(topicTx), []byte{1, 2, 3}, sequence(0)},
+		{[]byte("hashtx"), []byte{4}, sequence(0)},
+		{[]byte(topicBlock), block, sequence(0)},
+		{[]byte(topicTx), []byte{5}, sequence(1)},
+	}
+	for _, message := range messages {
+		p.Publish(message...)
+	}
+
+	// hashtx is not subscribed
+	for _, want := range [][][]byte{messages[0], messages[2], messages[3]} {
+		sub.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		parts, err := sub.Receive()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(parts, want) {
+			t.Errorf("received %s message of %d parts, want %s", parts[0], len(parts), want[0])
+		}
+	}
+}
+
+// TestHandshakeFrames checks the bytes on the wire as bitcoind subscribers see them
+func TestHandshakeFrames(t *testing.T) {
+	p := listen(t)
+	defer p.Close()
+
+	conn, err := net.Dial("tcp", p.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	r := bufio.NewReader(conn)
+
+	if _, err := conn.Write(greeting(false)); err != nil {
+		t.Fatal(err)
+	}
+	server := make([]byte, greetingSize)
+	if _, err := io.ReadFull(r, server); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(server, greeting(true)) {
+		t.Fatalf("greeting %x", server)
+	}
+
+	if err := writeFrame(conn, frame{command: true, body: readyCommand(socketSub)}); err != nil {
+		t.Fatal(err)
+	}
+	ready, err := readFrame(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name, properties, err := parseCommand(ready)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != commandReady || properties["Socket-Type"] != socketPub {
+		t.Fatalf("command %s %v", name, properties)
+	}
+
+	if err := writeMessage(conn, [][]byte{append([]byte{1}, topicBlock...)}); err != nil {
+		t.Fatal(err)
+	}
+	waitSubscribed(t, p, 1, topicBlock)
+
+	tests := []struct {
+		name string
+		body []byte
+		want []byte
+	}{
+		{
+			name: "short",
+			body: []byte{0xaa, 0xbb},
+			want: []byte{
+				flagMore, 8, 'r', 'a', 'w', 'b', 'l', 'o', 'c', 'k',
+				flagMore, 2, 0xaa, 0xbb,
+				0, 4, 7, 0, 0, 0,
+			},
+		},
+		{
+			name: "long",
+			body: bytes.Repeat([]byte{0xcc}, 256),
+			want: append(append([]byte{
+				flagMore, 8, 'r', 'a', 'w', 'b', 'l', 'o', 'c', 'k',
+				flagMore | flagLong, 0, 0, 0, 0, 0, 0, 1, 0,
+			}, bytes.Repeat([]byte{0xcc}, 256)...),
+				0, 4, 7, 0, 0, 0,
+			),
+		},
+	}
+	for _, test := range tests {
+		p.Publish([]byte(topicBlock), test.body, sequence(7))
+		got := make([]byte, len(test.want))
+		if _, err := io.ReadFull(r, got); err != nil {
+			t.Fatalf("%s: %s", test.name, err.Error())
+		}
+		if !bytes.Equal(got, test.want) {
+			t.Errorf("%s: frames %x, want %x", test.name, got, test.want)
+		}
+	}
+}
+
+func TestPublishConcurrent(t *testing.T) {
+	p := listen(t)
+	defer p.Close()
+
+	const subscribers, publishers, messages = 3, 4, 50
+	subs := []*Subscriber{}
+	for i := 0; i < subscribers; i++ {
+		sub, err := Subscribe("tcp://"+p.Addr().String(), topicTx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer sub.Close()
+		subs = append(subs, sub)
+	}
+	waitSubscribed(t, p, subscribers, topicTx)
+
+	// subscribers read while messages are published
+	errs := make(chan error, subscribers)
+	for i, sub := range subs {
+		go func(i int, sub *Subscriber) {
+			for n := 0; n < publishers*messages; n++ {
+				sub.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+				parts, err := sub.Receive()
+				if err != nil {
+					errs <- fmt.Errorf("subscriber %d message %d: %s", i, n, err.Error())
+					return
+				}
+				if len(parts) != 3 || string(parts[0]) != topicTx || len(parts[1]) == 0 {
+					errs <- fmt.Errorf("subscriber %d message %d is broken", i, n)
+					return
+				}
+			}
+			errs <- nil
+		}(i, sub)
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < publishers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < messages; j++ {
+				body := []byte(fmt.Sprintf("%d-%d", i, j))
+				p.Publish([]byte(topicTx), bytes.Repeat(body, 100), sequence(uint32(j)))
+			}
+		}(i)
+	}
+	// a subscriber connecting during publishing doesn't break others
+	late, err := Subscribe("tcp://"+p.Addr().String(), topicTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	late.Close()
+	wg.Wait()
+
+	for range subs {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+}
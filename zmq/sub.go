@@ -0,0 +1,71 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+package zmq
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"time"
+)
+
+const dialTimeout = 10 * time.Second
+
+// Subscriber is a SUB socket connected to a single publisher
+type Subscriber struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// Subscribe connects to the publisher at tcp://host:port and subscribes to topics
+func Subscribe(address string, topics ...string) (*Subscriber, error) {
+	conn, err := net.DialTimeout("tcp", network(address), dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	s := &Subscriber{
+		conn: conn,
+		r:    bufio.NewReader(conn),
+	}
+
+	peerType, err := handshake(conn, s.r, false, socketSub)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("handshake: %s", err.Error())
+	}
+	if peerType != socketPub && peerType != socketXPub {
+		conn.Close()
+		return nil, fmt.Errorf("peer socket type %s is not PUB", peerType)
+	}
+
+	// ZMTP 3.0 subscription is a message starting with 1
+	for _, topic := range topics {
+		if err := writeMessage(conn, [][]byte{append([]byte{1}, topic...)}); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Receive blocks until the next message, its parts are returned
+func (s *Subscriber) Receive() ([][]byte, error) {
+	for {
+		parts, command, err := readMessage(s.r)
+		if err != nil {
+			return nil, err
+		}
+		if command {
+			continue
+		}
+		return parts, nil
+	}
+}
+
+// Close disconnects from the publisher, blocked Receive returns an error
+func (s *Subscriber) Close() error {
+	return s.conn.Close()
+}
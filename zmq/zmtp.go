@@ -0,0 +1,247 @@
+/*
+Copyright 2018 Idealnaya rabota LLC
+Licensed under Multy.io license.
+See LICENSE for details
+*/
+
+/*
+Package zmq is a minimal ZMTP 3.0 implementation of PUB and SUB sockets
+with the NULL security mechanism. It's enough to receive bitcoind
+zmqpubrawblock and zmqpubrawtx notifications and to publish them
+from a local stand-in.
+*/
+package zmq
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	flagMore    = 0x01
+	flagLong    = 0x02
+	flagCommand = 0x04
+
+	greetingSize     = 64
+	handshakeTimeout = 10 * time.Second
+	// maxFrameSize protects from broken peers, blocks are smaller
+	maxFrameSize = 64 << 20
+
+	commandReady = "READY"
+	socketPub    = "PUB"
+	socketSub    = "SUB"
+	socketXPub   = "XPUB"
+	socketXSub   = "XSUB"
+)
+
+var errUnexpectedCommand = errors.New("unexpected command")
+
+// frame is a single ZMTP frame
+type frame struct {
+	more    bool
+	command bool
+	body    []byte
+}
+
+// greeting returns ZMTP 3.0 greeting with the NULL mechanism
+func greeting(server bool) []byte {
+	g := make([]byte, greetingSize)
+	g[0] = 0xff
+	g[9] = 0x7f
+	g[10] = 3
+	g[11] = 0
+	copy(g[12:32], "NULL")
+	if server {
+		g[32] = 1
+	}
+	return g
+}
+
+// network returns the address without tcp:// scheme used by bitcoind options
+func network(address string) string {
+	return strings.TrimPrefix(address, "tcp://")
+}
+
+// handshake exchanges greetings and READY commands, the peer socket type is returned
+func handshake(conn net.Conn, r *bufio.Reader, server bool, socketType string) (string, error) {
+	conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	defer conn.SetDeadline(time.Time{})
+
+	if _, err := conn.Write(greeting(server)); err != nil {
+		return "", err
+	}
+	peer := make([]byte, greetingSize)
+	if _, err := io.ReadFull(r, peer); err != nil {
+		return "", err
+	}
+	if peer[0] != 0xff || peer[9] != 0x7f {
+		return "", errors.New("not a ZMTP peer")
+	}
+	if peer[10] < 3 {
+		return "", fmt.Errorf("unsupported ZMTP version %d.%d", peer[10], peer[11])
+	}
+	if mechanism := string(bytes.TrimRight(peer[12:32], "\x00")); mechanism != "NULL" {
+		return "", fmt.Errorf("unsupported security mechanism %s", mechanism)
+	}
+
+	if err := writeFrame(conn, frame{command: true, body: readyCommand(socketType)}); err != nil {
+		return "", err
+	}
+	f, err := readFrame(r)
+	if err != nil {
+		return "", err
+	}
+	name, properties, err := parseCommand(f)
+	if err != nil {
+		return "", err
+	}
+	if name != commandReady {
+		return "", fmt.Errorf("%s: %s", errUnexpectedCommand, name)
+	}
+	return properties["Socket-Type"], nil
+}
+
+func readyCommand(socketType string) []byte {
+	body := &bytes.Buffer{}
+	body.WriteByte(byte(len(commandReady)))
+	body.WriteString(commandReady)
+	writeProperty(body, "Socket-Type", socketType)
+	return body.Bytes()
+}
+
+func writeProperty(w *bytes.Buffer, name, value string) {
+	w.WriteByte(byte(len(name)))
+	w.WriteString(name)
+	size := make([]byte, 4)
+	binary.BigEndian.PutUint32(size, uint32(len(value)))
+	w.Write(size)
+	w.WriteString(value)
+}
+
+// parseCommand returns the command name and READY properties
+func parseCommand(f frame) (string, map[string]string, error) {
+	if !f.command || len(f.body) == 0 {
+		return "", nil, errors.New("command expected")
+	}
+	body := f.body
+	size := int(body[0])
+	if len(body) < 1+size {
+		return "", nil, errors.New("broken command")
+	}
+	name := string(body[1 : 1+size])
+	body = body[1+size:]
+
+	properties := map[string]string{}
+	if name != commandReady {
+		return name, properties, nil
+	}
+	for len(body) > 0 {
+		size := int(body[0])
+		if len(body) < 1+size+4 {
+			return "", nil, errors.New("broken property")
+		}
+		key := string(body[1 : 1+size])
+		body = body[1+size:]
+		valueSize := int(binary.BigEndian.Uint32(body))
+		body = body[4:]
+		if len(body) < valueSize {
+			return "", nil, errors.New("broken property")
+		}
+		properties[key] = string(body[:valueSize])
+		body = body[valueSize:]
+	}
+	return name, properties, nil
+}
+
+func writeFrame(w io.Writer, f frame) error {
+	var flags byte
+	if f.more {
+		flags |= flagMore
+	}
+	if f.command {
+		flags |= flagCommand
+	}
+	header := []byte{}
+	if len(f.body) > 255 {
+		size := make([]byte, 8)
+		binary.BigEndian.PutUint64(size, uint64(len(f.body)))
+		header = append(header, flags|flagLong)
+		header = append(header, size...)
+	} else {
+		header = append(header, flags, byte(len(f.body)))
+	}
+	if _, err := w.Write(append(header, f.body...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func readFrame(r *bufio.Reader) (frame, error) {
+	flags, err := r.ReadByte()
+	if err != nil {
+		return frame{}, err
+	}
+	var size uint64
+	if flags&flagLong != 0 {
+		buf := make([]byte, 8)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return frame{}, err
+		}
+		size = binary.BigEndian.Uint64(buf)
+	} else {
+		b, err := r.ReadByte()
+		if err != nil {
+			return frame{}, err
+		}
+		size = uint64(b)
+	}
+	if size > maxFrameSize {
+		return frame{}, fmt.Errorf("frame of %d bytes is too large", size)
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return frame{}, err
+	}
+	return frame{
+		more:    flags&flagMore != 0,
+		command: flags&flagCommand != 0,
+		body:    body,
+	}, nil
+}
+
+// writeMessage sends a multipart message
+func writeMessage(w io.Writer, parts [][]byte) error {
+	buf := &bytes.Buffer{}
+	for i, part := range parts {
+		if err := writeFrame(buf, frame{more: i < len(parts)-1, body: part}); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// readMessage reads a multipart message, commands are returned as single frame messages
+func readMessage(r *bufio.Reader) ([][]byte, bool, error) {
+	parts := [][]byte{}
+	for {
+		f, err := readFrame(r)
+		if err != nil {
+			return nil, false, err
+		}
+		if f.command {
+			return [][]byte{f.body}, true, nil
+		}
+		parts = append(parts, f.body)
+		if !f.more {
+			return parts, false, nil
+		}
+	}
+}